@@ -0,0 +1,248 @@
+package client
+
+import (
+	contextpkg "context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os/exec"
+	"sync"
+	"sync/atomic"
+
+	"github.com/gorilla/websocket"
+	"github.com/kjbreil/glsp"
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol316 "github.com/kjbreil/glsp/protocol_3_16"
+	protocol "github.com/kjbreil/glsp/protocol_3_17"
+	"github.com/sourcegraph/jsonrpc2"
+	wsjsonrpc2 "github.com/sourcegraph/jsonrpc2/websocket"
+)
+
+var (
+	ErrNotConnected     = errors.New("client not connected")
+	ErrAlreadyConnected = errors.New("client already connected")
+)
+
+//
+// Client
+//
+
+// Client drives a language server. It connects to the server, performs the initialize handshake, sends typed
+// requests and routes requests made by the server to Handler, which is a *Handler unless replaced.
+type Client struct {
+	Handler glsp.Handler
+	Log     *slog.Logger
+	Debug   bool
+
+	conn         *jsonrpc2.Conn
+	cmd          *exec.Cmd
+	capabilities *protocol.ServerCapabilities
+	serverInfo   *protocol316.InitializeResultServerInfo
+	documents    map[uri.DocumentURI]*Document
+	nextID       atomic.Uint64
+
+	mu sync.Mutex
+}
+
+func NewClient(handler glsp.Handler) *Client {
+	if handler == nil {
+		handler = &Handler{}
+	}
+	return &Client{
+		Handler:   handler,
+		Log:       slog.Default(),
+		documents: make(map[uri.DocumentURI]*Document),
+		mu:        sync.Mutex{},
+	}
+}
+
+// Launch starts the language server as a process and talks to it over its stdin and stdout.
+func (c *Client) Launch(ctx contextpkg.Context, name string, args ...string) error {
+	return c.LaunchCommand(ctx, exec.CommandContext(ctx, name, args...))
+}
+
+// LaunchCommand starts a prepared command and talks to it over its stdin and stdout, the command must not
+// have Stdin or Stdout set.
+func (c *Client) LaunchCommand(ctx contextpkg.Context, cmd *exec.Cmd) error {
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err = cmd.Start(); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.cmd = cmd
+	c.mu.Unlock()
+
+	return c.ConnectStream(ctx, &processStream{stdin: stdin, stdout: stdout})
+}
+
+// DialTCP connects to a language server listening on a TCP address.
+func (c *Client) DialTCP(ctx contextpkg.Context, address string) error {
+	var dialer net.Dialer
+	connection, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	return c.ConnectStream(ctx, connection)
+}
+
+// DialWebSocket connects to a language server listening for web socket connections.
+func (c *Client) DialWebSocket(ctx contextpkg.Context, url string) error {
+	socket, _, err := websocket.DefaultDialer.DialContext(ctx, url, nil)
+	if err != nil {
+		return err
+	}
+	return c.connect(ctx, wsjsonrpc2.NewObjectStream(socket))
+}
+
+// ConnectStream talks to a language server over an already established stream.
+func (c *Client) ConnectStream(ctx contextpkg.Context, stream io.ReadWriteCloser) error {
+	return c.connect(ctx, jsonrpc2.NewBufferedStream(stream, jsonrpc2.VSCodeObjectCodec{}))
+}
+
+func (c *Client) connect(ctx contextpkg.Context, stream jsonrpc2.ObjectStream) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn != nil {
+		return ErrAlreadyConnected
+	}
+
+	var options []jsonrpc2.ConnOpt
+	if c.Debug {
+		options = append(options, jsonrpc2.LogMessages(&jsonRPCLogger{c.Log.With("scope", "jsonrpc2")}))
+	}
+
+	c.conn = jsonrpc2.NewConn(contextpkg.WithoutCancel(ctx), stream, c.newHandler(), options...)
+	return nil
+}
+
+// DisconnectNotify returns a channel that is closed when the connection to the server is gone.
+func (c *Client) DisconnectNotify() <-chan struct{} {
+	conn, err := c.connection()
+	if err != nil {
+		closed := make(chan struct{})
+		close(closed)
+		return closed
+	}
+	return conn.DisconnectNotify()
+}
+
+// Close closes the connection and waits for a launched server process to exit. Call Shutdown first to stop
+// the server gracefully.
+func (c *Client) Close() error {
+	c.mu.Lock()
+	conn := c.conn
+	cmd := c.cmd
+	c.conn = nil
+	c.cmd = nil
+	c.mu.Unlock()
+
+	var errs []error
+	if conn != nil {
+		if err := conn.Close(); err != nil && !errors.Is(err, jsonrpc2.ErrClosed) {
+			errs = append(errs, err)
+		}
+	}
+	if cmd != nil {
+		if err := cmd.Wait(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ServerCapabilities returns the capabilities the server sent in its initialize result.
+func (c *Client) ServerCapabilities() *protocol.ServerCapabilities {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.capabilities
+}
+
+// ServerInfo returns the name and version the server sent in its initialize result.
+func (c *Client) ServerInfo() *protocol316.InitializeResultServerInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.serverInfo
+}
+
+func (c *Client) connection() (*jsonrpc2.Conn, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil, ErrNotConnected
+	}
+	return c.conn, nil
+}
+
+// Call sends a request to the server and decodes the response into result. When ctx is done before the
+// response arrives the request is cancelled with $/cancelRequest.
+func (c *Client) Call(ctx contextpkg.Context, method string, params any, result any) error {
+	conn, err := c.connection()
+	if err != nil {
+		return err
+	}
+
+	// ids are picked here so a cancelled request can be named in $/cancelRequest
+	id := jsonrpc2.ID{Num: c.nextID.Add(1)}
+	waiter, err := conn.DispatchCall(ctx, method, params, jsonrpc2.PickID(id))
+	if err != nil {
+		return err
+	}
+	err = waiter.Wait(ctx, result)
+	if ctx.Err() != nil {
+		cancelErr := conn.Notify(contextpkg.Background(), protocol316.MethodCancelRequest, &protocol316.CancelParams{
+			ID: protocol316.IntegerOrString{Value: protocol316.Integer(id.Num)},
+		})
+		if cancelErr != nil {
+			c.Log.Error("could not cancel request", "method", method, "err", cancelErr)
+		}
+	}
+	return err
+}
+
+// Notify sends a notification to the server.
+func (c *Client) Notify(ctx contextpkg.Context, method string, params any) error {
+	conn, err := c.connection()
+	if err != nil {
+		return err
+	}
+	return conn.Notify(ctx, method, params)
+}
+
+type processStream struct {
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+}
+
+// ([io.Reader] interface)
+func (p *processStream) Read(b []byte) (int, error) {
+	return p.stdout.Read(b)
+}
+
+// ([io.Writer] interface)
+func (p *processStream) Write(b []byte) (int, error) {
+	return p.stdin.Write(b)
+}
+
+// ([io.Closer] interface)
+func (p *processStream) Close() error {
+	return errors.Join(p.stdin.Close(), p.stdout.Close())
+}
+
+type jsonRPCLogger struct {
+	log *slog.Logger
+}
+
+// ([jsonrpc2.Logger] interface)
+func (j *jsonRPCLogger) Printf(format string, v ...any) {
+	j.log.Debug(fmt.Sprintf(format, v...))
+}
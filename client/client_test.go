@@ -0,0 +1,131 @@
+package client
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/kjbreil/glsp"
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol316 "github.com/kjbreil/glsp/protocol_3_16"
+	protocol "github.com/kjbreil/glsp/protocol_3_17"
+	"github.com/kjbreil/glsp/server"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+func newTestClient(t *testing.T, handler *protocol316.Handler) *Client {
+	t.Helper()
+
+	serverSide, clientSide := net.Pipe()
+	s := server.NewServer(handler, "test", false, func(conn *jsonrpc2.Conn) {})
+	go s.ServeStream(serverSide, nil)
+
+	c := NewClient(nil)
+	if err := c.ConnectStream(context.Background(), clientSide); err != nil {
+		t.Fatalf("ConnectStream() error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = c.Close()
+	})
+	return c
+}
+
+func TestClient_Initialize(t *testing.T) {
+	initialized := make(chan struct{})
+	handler := &protocol316.Handler{
+		Initialize: func(context *glsp.Context, params *protocol316.InitializeParams) (any, error) {
+			return protocol316.InitializeResult{
+				Capabilities: protocol316.ServerCapabilities{HoverProvider: true},
+				ServerInfo:   &protocol316.InitializeResultServerInfo{Name: "test"},
+			}, nil
+		},
+		Initialized: func(context *glsp.Context, params *protocol316.InitializedParams) error {
+			close(initialized)
+			return nil
+		},
+	}
+	c := newTestClient(t, handler)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := c.Initialize(ctx, &protocol.InitializeParams{})
+	if err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	if result.ServerInfo == nil || result.ServerInfo.Name != "test" {
+		t.Errorf("Initialize() server info = %v", result.ServerInfo)
+	}
+	if c.ServerCapabilities() == nil || c.ServerCapabilities().HoverProvider != true {
+		t.Errorf("ServerCapabilities() = %v", c.ServerCapabilities())
+	}
+
+	select {
+	case <-initialized:
+	case <-ctx.Done():
+		t.Fatal("initialized notification was not sent")
+	}
+}
+
+func TestClient_Completion_array(t *testing.T) {
+	handler := &protocol316.Handler{
+		Initialize: func(context *glsp.Context, params *protocol316.InitializeParams) (any, error) {
+			return protocol316.InitializeResult{}, nil
+		},
+		TextDocumentCompletion: func(context *glsp.Context, params *protocol316.CompletionParams) (any, error) {
+			return []protocol316.CompletionItem{{Label: "a"}, {Label: "b"}}, nil
+		},
+	}
+	c := newTestClient(t, handler)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := c.Initialize(ctx, &protocol.InitializeParams{}); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	list, err := c.Completion(ctx, &protocol316.CompletionParams{})
+	if err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+	if list == nil || list.IsIncomplete || len(list.Items) != 2 {
+		t.Errorf("Completion() = %v", list)
+	}
+}
+
+func TestApplyChanges(t *testing.T) {
+	text := applyChanges("hello world\nsecond line", []any{
+		protocol316.TextDocumentContentChangeEvent{
+			Range: &protocol316.Range{
+				Start: protocol316.Position{Line: 0, Character: 6},
+				End:   protocol316.Position{Line: 0, Character: 11},
+			},
+			Text: "there",
+		},
+		protocol316.TextDocumentContentChangeEvent{
+			Range: &protocol316.Range{
+				Start: protocol316.Position{Line: 1, Character: 0},
+				End:   protocol316.Position{Line: 1, Character: 6},
+			},
+			Text: "last",
+		},
+	})
+	if text != "hello there\nlast line" {
+		t.Errorf("applyChanges() = %q", text)
+	}
+
+	text = applyChanges(text, []any{protocol316.TextDocumentContentChangeEventWhole{Text: "new"}})
+	if text != "new" {
+		t.Errorf("applyChanges() whole = %q", text)
+	}
+}
+
+func TestClient_ChangeDocument_notOpen(t *testing.T) {
+	c := NewClient(nil)
+	err := c.ChangeDocument(context.Background(), uri.DocumentURI("file:///a.txt"), protocol316.TextDocumentContentChangeEventWhole{Text: "a"})
+	if err != ErrDocumentNotOpen {
+		t.Errorf("ChangeDocument() error = %v, want %v", err, ErrDocumentNotOpen)
+	}
+}
@@ -0,0 +1,175 @@
+package client
+
+import (
+	contextpkg "context"
+	"errors"
+	"sort"
+
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+var ErrDocumentNotOpen = errors.New("document not open")
+
+// Document is the client side copy of a document opened on the server.
+type Document struct {
+	URI        uri.DocumentURI
+	LanguageID string
+	Version    protocol.Integer
+	Text       string
+}
+
+// OpenDocument opens a document on the server at version 1.
+func (c *Client) OpenDocument(ctx contextpkg.Context, documentURI uri.DocumentURI, languageID string, text string) error {
+	return c.DidOpen(ctx, &protocol.DidOpenTextDocumentParams{
+		TextDocument: protocol.TextDocumentItem{
+			URI:        documentURI,
+			LanguageID: languageID,
+			Version:    1,
+			Text:       text,
+		},
+	})
+}
+
+// ChangeDocument applies the changes to the open document and bumps its version. Changes are either
+// protocol.TextDocumentContentChangeEvent or protocol.TextDocumentContentChangeEventWhole.
+func (c *Client) ChangeDocument(ctx contextpkg.Context, documentURI uri.DocumentURI, changes ...any) error {
+	document, ok := c.Document(documentURI)
+	if !ok {
+		return ErrDocumentNotOpen
+	}
+
+	return c.DidChange(ctx, &protocol.DidChangeTextDocumentParams{
+		TextDocument: protocol.VersionedTextDocumentIdentifier{
+			TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: documentURI},
+			Version:                document.Version + 1,
+		},
+		ContentChanges: changes,
+	})
+}
+
+// SetDocumentText replaces the whole text of the open document.
+func (c *Client) SetDocumentText(ctx contextpkg.Context, documentURI uri.DocumentURI, text string) error {
+	return c.ChangeDocument(ctx, documentURI, protocol.TextDocumentContentChangeEventWhole{Text: text})
+}
+
+// CloseDocument closes the open document on the server.
+func (c *Client) CloseDocument(ctx contextpkg.Context, documentURI uri.DocumentURI) error {
+	return c.DidClose(ctx, &protocol.DidCloseTextDocumentParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: documentURI},
+	})
+}
+
+// DidOpen sends textDocument/didOpen and keeps a copy of the document so later changes can be tracked.
+func (c *Client) DidOpen(ctx contextpkg.Context, params *protocol.DidOpenTextDocumentParams) error {
+	c.mu.Lock()
+	c.documents[params.TextDocument.URI] = &Document{
+		URI:        params.TextDocument.URI,
+		LanguageID: params.TextDocument.LanguageID,
+		Version:    params.TextDocument.Version,
+		Text:       params.TextDocument.Text,
+	}
+	c.mu.Unlock()
+
+	return c.Notify(ctx, protocol.MethodTextDocumentDidOpen, params)
+}
+
+// DidChange applies the changes to the copy of the document and sends textDocument/didChange. When the
+// server only supports full document sync the whole text is sent in place of the changes.
+func (c *Client) DidChange(ctx contextpkg.Context, params *protocol.DidChangeTextDocumentParams) error {
+	c.mu.Lock()
+	document, ok := c.documents[params.TextDocument.URI]
+	if !ok {
+		c.mu.Unlock()
+		return ErrDocumentNotOpen
+	}
+	document.Text = applyChanges(document.Text, params.ContentChanges)
+	document.Version = params.TextDocument.Version
+	text := document.Text
+	c.mu.Unlock()
+
+	switch c.syncKind() {
+	case protocol.TextDocumentSyncKindNone:
+		return nil
+	case protocol.TextDocumentSyncKindFull:
+		params = &protocol.DidChangeTextDocumentParams{
+			TextDocument:   params.TextDocument,
+			ContentChanges: []any{protocol.TextDocumentContentChangeEventWhole{Text: text}},
+		}
+	}
+	return c.Notify(ctx, protocol.MethodTextDocumentDidChange, params)
+}
+
+// DidClose forgets the copy of the document and sends textDocument/didClose.
+func (c *Client) DidClose(ctx contextpkg.Context, params *protocol.DidCloseTextDocumentParams) error {
+	c.mu.Lock()
+	_, ok := c.documents[params.TextDocument.URI]
+	delete(c.documents, params.TextDocument.URI)
+	c.mu.Unlock()
+	if !ok {
+		return ErrDocumentNotOpen
+	}
+
+	return c.Notify(ctx, protocol.MethodTextDocumentDidClose, params)
+}
+
+// syncKind returns how the server wants document changes, before initialization changes are sent as they
+// are.
+func (c *Client) syncKind() protocol.TextDocumentSyncKind {
+	capabilities := c.ServerCapabilities()
+	if capabilities == nil {
+		return protocol.TextDocumentSyncKindIncremental
+	}
+	switch sync := capabilities.TextDocumentSync.(type) {
+	case protocol.TextDocumentSyncKind:
+		return sync
+	case protocol.TextDocumentSyncOptions:
+		if sync.Change != nil {
+			return *sync.Change
+		}
+		return protocol.TextDocumentSyncKindNone
+	}
+	return protocol.TextDocumentSyncKindIncremental
+}
+
+// Document returns a copy of the open document.
+func (c *Client) Document(documentURI uri.DocumentURI) (Document, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	document, ok := c.documents[documentURI]
+	if !ok {
+		return Document{}, false
+	}
+	return *document, true
+}
+
+// Documents returns copies of all open documents ordered by uri.
+func (c *Client) Documents() []Document {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	documents := make([]Document, 0, len(c.documents))
+	for _, document := range c.documents {
+		documents = append(documents, *document)
+	}
+	sort.Slice(documents, func(i, j int) bool {
+		return documents[i].URI < documents[j].URI
+	})
+	return documents
+}
+
+func applyChanges(text string, changes []any) string {
+	for _, change := range changes {
+		switch change := change.(type) {
+		case protocol.TextDocumentContentChangeEvent:
+			if change.Range == nil {
+				text = change.Text
+				continue
+			}
+			start, end := change.Range.IndexesIn(text)
+			text = text[:start] + change.Text + text[end:]
+		case protocol.TextDocumentContentChangeEventWhole:
+			text = change.Text
+		}
+	}
+	return text
+}
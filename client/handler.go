@@ -0,0 +1,273 @@
+package client
+
+import (
+	contextpkg "context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kjbreil/glsp"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+//
+// Handler
+//
+
+// Handler handles the requests and notifications a language server sends to the client. Notifications without
+// a handler are dropped, requests without a handler are answered with a sensible default where the protocol
+// has one and with method not found otherwise.
+type Handler struct {
+	WindowShowMessage              func(context *glsp.Context, params *protocol.ShowMessageParams) error
+	WindowShowMessageRequest       func(context *glsp.Context, params *protocol.ShowMessageRequestParams) (*protocol.MessageActionItem, error)
+	WindowShowDocument             func(context *glsp.Context, params *protocol.ShowDocumentParams) (*protocol.ShowDocumentResult, error)
+	WindowLogMessage               func(context *glsp.Context, params *protocol.LogMessageParams) error
+	WindowWorkDoneProgressCreate   func(context *glsp.Context, params *protocol.WorkDoneProgressCreateParams) error
+	Progress                       func(context *glsp.Context, params *protocol.ProgressParams) error
+	LogTrace                       func(context *glsp.Context, params *protocol.LogTraceParams) error
+	TelemetryEvent                 func(context *glsp.Context, params any) error
+	ClientRegisterCapability       func(context *glsp.Context, params *protocol.RegistrationParams) error
+	ClientUnregisterCapability     func(context *glsp.Context, params *protocol.UnregistrationParams) error
+	WorkspaceWorkspaceFolders      func(context *glsp.Context) ([]protocol.WorkspaceFolder, error)
+	WorkspaceConfiguration         func(context *glsp.Context, params *protocol.ConfigurationParams) ([]any, error)
+	WorkspaceApplyEdit             func(context *glsp.Context, params *protocol.ApplyWorkspaceEditParams) (*protocol.ApplyWorkspaceEditResponse, error)
+	WorkspaceCodeLensRefresh       func(context *glsp.Context) error
+	WorkspaceSemanticTokensRefresh func(context *glsp.Context) error
+	TextDocumentPublishDiagnostics func(context *glsp.Context, params *protocol.PublishDiagnosticsParams) error
+}
+
+// ([glsp.Handler] interface)
+func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validParams bool, err error) {
+	switch context.Method {
+	case protocol.ServerWindowShowMessage:
+		validMethod = true
+		var params protocol.ShowMessageParams
+		if err = json.Unmarshal(context.Params, &params); err == nil {
+			validParams = true
+			if h.WindowShowMessage != nil {
+				err = h.WindowShowMessage(context, &params)
+			}
+		}
+
+	case protocol.ServerWindowShowMessageRequest:
+		validMethod = true
+		var params protocol.ShowMessageRequestParams
+		if err = json.Unmarshal(context.Params, &params); err == nil {
+			validParams = true
+			// without a handler no action is chosen, the protocol answers null for that
+			if h.WindowShowMessageRequest != nil {
+				r, err = h.WindowShowMessageRequest(context, &params)
+			}
+		}
+
+	case protocol.ServerWindowShowDocument:
+		validMethod = true
+		var params protocol.ShowDocumentParams
+		if err = json.Unmarshal(context.Params, &params); err == nil {
+			validParams = true
+			if h.WindowShowDocument != nil {
+				r, err = h.WindowShowDocument(context, &params)
+			} else {
+				r = &protocol.ShowDocumentResult{Success: false}
+			}
+		}
+
+	case protocol.ServerWindowLogMessage:
+		validMethod = true
+		var params protocol.LogMessageParams
+		if err = json.Unmarshal(context.Params, &params); err == nil {
+			validParams = true
+			if h.WindowLogMessage != nil {
+				err = h.WindowLogMessage(context, &params)
+			}
+		}
+
+	case protocol.ServerWindowWorkDoneProgressCreate:
+		validMethod = true
+		var params protocol.WorkDoneProgressCreateParams
+		if err = json.Unmarshal(context.Params, &params); err == nil {
+			validParams = true
+			if h.WindowWorkDoneProgressCreate != nil {
+				err = h.WindowWorkDoneProgressCreate(context, &params)
+			}
+		}
+
+	case protocol.MethodProgress:
+		validMethod = true
+		var params protocol.ProgressParams
+		if err = json.Unmarshal(context.Params, &params); err == nil {
+			validParams = true
+			if h.Progress != nil {
+				err = h.Progress(context, &params)
+			}
+		}
+
+	case protocol.MethodLogTrace:
+		validMethod = true
+		var params protocol.LogTraceParams
+		if err = json.Unmarshal(context.Params, &params); err == nil {
+			validParams = true
+			if h.LogTrace != nil {
+				err = h.LogTrace(context, &params)
+			}
+		}
+
+	case protocol.ServerTelemetryEvent:
+		validMethod = true
+		var params any
+		if err = json.Unmarshal(context.Params, &params); err == nil {
+			validParams = true
+			if h.TelemetryEvent != nil {
+				err = h.TelemetryEvent(context, params)
+			}
+		}
+
+	case protocol.ServerClientRegisterCapability:
+		validMethod = true
+		var params protocol.RegistrationParams
+		if err = json.Unmarshal(context.Params, &params); err == nil {
+			validParams = true
+			if h.ClientRegisterCapability != nil {
+				err = h.ClientRegisterCapability(context, &params)
+			}
+		}
+
+	case protocol.ServerClientUnregisterCapability:
+		validMethod = true
+		var params protocol.UnregistrationParams
+		if err = json.Unmarshal(context.Params, &params); err == nil {
+			validParams = true
+			if h.ClientUnregisterCapability != nil {
+				err = h.ClientUnregisterCapability(context, &params)
+			}
+		}
+
+	case protocol.ServerWorkspaceWorkspaceFolders:
+		if h.WorkspaceWorkspaceFolders != nil {
+			validMethod = true
+			validParams = true
+			r, err = h.WorkspaceWorkspaceFolders(context)
+		}
+
+	case protocol.ServerWorkspaceConfiguration:
+		validMethod = true
+		var params protocol.ConfigurationParams
+		if err = json.Unmarshal(context.Params, &params); err == nil {
+			validParams = true
+			if h.WorkspaceConfiguration != nil {
+				r, err = h.WorkspaceConfiguration(context, &params)
+			} else {
+				// one null per requested item tells the server there is no configuration
+				r = make([]any, len(params.Items))
+			}
+		}
+
+	case protocol.ServerWorkspaceApplyEdit:
+		if h.WorkspaceApplyEdit != nil {
+			validMethod = true
+			var params protocol.ApplyWorkspaceEditParams
+			if err = json.Unmarshal(context.Params, &params); err == nil {
+				validParams = true
+				r, err = h.WorkspaceApplyEdit(context, &params)
+			}
+		}
+
+	case protocol.ServerWorkspaceCodeLensRefresh:
+		validMethod = true
+		validParams = true
+		if h.WorkspaceCodeLensRefresh != nil {
+			err = h.WorkspaceCodeLensRefresh(context)
+		}
+
+	case protocol.MethodWorkspaceSemanticTokensRefresh:
+		validMethod = true
+		validParams = true
+		if h.WorkspaceSemanticTokensRefresh != nil {
+			err = h.WorkspaceSemanticTokensRefresh(context)
+		}
+
+	case protocol.ServerTextDocumentPublishDiagnostics:
+		validMethod = true
+		var params protocol.PublishDiagnosticsParams
+		if err = json.Unmarshal(context.Params, &params); err == nil {
+			validParams = true
+			if h.TextDocumentPublishDiagnostics != nil {
+				err = h.TextDocumentPublishDiagnostics(context, &params)
+			}
+		}
+	}
+
+	return
+}
+
+// newHandler adapts the Handler to jsonrpc2. Notifications are handled in order, requests from the server are
+// answered on their own goroutine so a handler may call back into the server without blocking the
+// connection.
+func (c *Client) newHandler() jsonrpc2.Handler {
+	handler := jsonrpc2.HandlerWithError(c.handle)
+	async := jsonrpc2.AsyncHandler(handler)
+	return handlerFunc(func(ctx contextpkg.Context, conn *jsonrpc2.Conn, request *jsonrpc2.Request) {
+		if request.Notif {
+			handler.Handle(ctx, conn, request)
+		} else {
+			async.Handle(ctx, conn, request)
+		}
+	})
+}
+
+type handlerFunc func(ctx contextpkg.Context, conn *jsonrpc2.Conn, request *jsonrpc2.Request)
+
+// ([jsonrpc2.Handler] interface)
+func (f handlerFunc) Handle(ctx contextpkg.Context, conn *jsonrpc2.Conn, request *jsonrpc2.Request) {
+	f(ctx, conn, request)
+}
+
+func (c *Client) handle(ctx contextpkg.Context, conn *jsonrpc2.Conn, request *jsonrpc2.Request) (any, error) {
+	glspContext := glsp.Context{
+		Method: request.Method,
+		Notify: func(method string, params any) {
+			if err := conn.Notify(ctx, method, params); err != nil {
+				c.Log.Error(err.Error())
+			}
+		},
+		Call: func(method string, params any, result any) {
+			if err := conn.Call(ctx, method, params, result); err != nil {
+				c.Log.Error(err.Error())
+			}
+		},
+		Context: ctx,
+	}
+	if request.Params != nil {
+		glspContext.Params = *request.Params
+	}
+	if !request.Notif {
+		glspContext.ID = request.ID
+	}
+
+	result, validMethod, validParams, err := c.Handler.Handle(&glspContext)
+	if request.Notif && (!validMethod || !validParams || err != nil) {
+		// notifications cannot be answered, unknown ones are allowed to be dropped
+		if err != nil {
+			c.Log.Error("could not handle notification", "method", request.Method, "err", err)
+		}
+		return nil, nil
+	}
+	if !validMethod {
+		return nil, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeMethodNotFound,
+			Message: fmt.Sprintf("method not supported: %s", request.Method),
+		}
+	} else if !validParams {
+		rpcErr := &jsonrpc2.Error{Code: jsonrpc2.CodeInvalidParams}
+		if err != nil {
+			rpcErr.Message = err.Error()
+		}
+		return nil, rpcErr
+	} else if err != nil {
+		return nil, &jsonrpc2.Error{
+			Code:    jsonrpc2.CodeInvalidRequest,
+			Message: err.Error(),
+		}
+	}
+	return result, nil
+}
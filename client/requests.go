@@ -0,0 +1,557 @@
+package client
+
+import (
+	"bytes"
+	contextpkg "context"
+	"encoding/json"
+
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol316 "github.com/kjbreil/glsp/protocol_3_16"
+	protocol "github.com/kjbreil/glsp/protocol_3_17"
+)
+
+//
+// General
+//
+
+// Initialize performs the initialize handshake, the initialized notification is sent once the server answered.
+func (c *Client) Initialize(ctx contextpkg.Context, params *protocol.InitializeParams) (*protocol.InitializeResult, error) {
+	var result protocol.InitializeResult
+	if err := c.Call(ctx, protocol.MethodInitialize, params, &result); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	c.capabilities = &result.Capabilities
+	c.serverInfo = result.ServerInfo
+	c.mu.Unlock()
+
+	if err := c.Notify(ctx, protocol316.MethodInitialized, &protocol316.InitializedParams{}); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) Shutdown(ctx contextpkg.Context) error {
+	return c.Call(ctx, protocol316.MethodShutdown, nil, nil)
+}
+
+func (c *Client) Exit(ctx contextpkg.Context) error {
+	return c.Notify(ctx, protocol316.MethodExit, nil)
+}
+
+func (c *Client) SetTrace(ctx contextpkg.Context, params *protocol316.SetTraceParams) error {
+	return c.Notify(ctx, protocol316.MethodSetTrace, params)
+}
+
+func (c *Client) WorkDoneProgressCancel(ctx contextpkg.Context, params *protocol316.WorkDoneProgressCancelParams) error {
+	return c.Notify(ctx, protocol316.MethodWindowWorkDoneProgressCancel, params)
+}
+
+//
+// Workspace
+//
+
+func (c *Client) DidChangeWorkspaceFolders(ctx contextpkg.Context, params *protocol316.DidChangeWorkspaceFoldersParams) error {
+	return c.Notify(ctx, protocol316.MethodWorkspaceDidChangeWorkspaceFolders, params)
+}
+
+func (c *Client) DidChangeConfiguration(ctx contextpkg.Context, params *protocol316.DidChangeConfigurationParams) error {
+	return c.Notify(ctx, protocol316.MethodWorkspaceDidChangeConfiguration, params)
+}
+
+func (c *Client) DidChangeWatchedFiles(ctx contextpkg.Context, params *protocol316.DidChangeWatchedFilesParams) error {
+	return c.Notify(ctx, protocol316.MethodWorkspaceDidChangeWatchedFiles, params)
+}
+
+func (c *Client) WorkspaceSymbol(ctx contextpkg.Context, params *protocol316.WorkspaceSymbolParams) ([]protocol316.SymbolInformation, error) {
+	var result []protocol316.SymbolInformation
+	err := c.Call(ctx, protocol316.MethodWorkspaceSymbol, params, &result)
+	return result, err
+}
+
+// ExecuteCommand runs a command on the server, the result of the command is decoded into result.
+func (c *Client) ExecuteCommand(ctx contextpkg.Context, params *protocol316.ExecuteCommandParams, result any) error {
+	return c.Call(ctx, protocol316.MethodWorkspaceExecuteCommand, params, result)
+}
+
+func (c *Client) WillCreateFiles(ctx contextpkg.Context, params *protocol316.CreateFilesParams) (*protocol316.WorkspaceEdit, error) {
+	var result *protocol316.WorkspaceEdit
+	err := c.Call(ctx, protocol316.MethodWorkspaceWillCreateFiles, params, &result)
+	return result, err
+}
+
+func (c *Client) DidCreateFiles(ctx contextpkg.Context, params *protocol316.CreateFilesParams) error {
+	return c.Notify(ctx, protocol316.MethodWorkspaceDidCreateFiles, params)
+}
+
+func (c *Client) WillRenameFiles(ctx contextpkg.Context, params *protocol316.RenameFilesParams) (*protocol316.WorkspaceEdit, error) {
+	var result *protocol316.WorkspaceEdit
+	err := c.Call(ctx, protocol316.MethodWorkspaceWillRenameFiles, params, &result)
+	return result, err
+}
+
+func (c *Client) DidRenameFiles(ctx contextpkg.Context, params *protocol316.RenameFilesParams) error {
+	return c.Notify(ctx, protocol316.MethodWorkspaceDidRenameFiles, params)
+}
+
+func (c *Client) WillDeleteFiles(ctx contextpkg.Context, params *protocol316.DeleteFilesParams) (*protocol316.WorkspaceEdit, error) {
+	var result *protocol316.WorkspaceEdit
+	err := c.Call(ctx, protocol316.MethodWorkspaceWillDeleteFiles, params, &result)
+	return result, err
+}
+
+func (c *Client) DidDeleteFiles(ctx contextpkg.Context, params *protocol316.DeleteFilesParams) error {
+	return c.Notify(ctx, protocol316.MethodWorkspaceDidDeleteFiles, params)
+}
+
+//
+// Text document synchronization
+//
+
+func (c *Client) WillSave(ctx contextpkg.Context, params *protocol316.WillSaveTextDocumentParams) error {
+	return c.Notify(ctx, protocol316.MethodTextDocumentWillSave, params)
+}
+
+func (c *Client) WillSaveWaitUntil(ctx contextpkg.Context, params *protocol316.WillSaveTextDocumentParams) ([]protocol316.TextEdit, error) {
+	var result []protocol316.TextEdit
+	err := c.Call(ctx, protocol316.MethodTextDocumentWillSaveWaitUntil, params, &result)
+	return result, err
+}
+
+func (c *Client) DidSave(ctx contextpkg.Context, params *protocol316.DidSaveTextDocumentParams) error {
+	return c.Notify(ctx, protocol316.MethodTextDocumentDidSave, params)
+}
+
+//
+// Language features
+//
+
+// Completion always returns a list, a plain array of items from the server is returned as a complete list.
+func (c *Client) Completion(ctx contextpkg.Context, params *protocol316.CompletionParams) (*protocol316.CompletionList, error) {
+	var raw json.RawMessage
+	if err := c.Call(ctx, protocol316.MethodTextDocumentCompletion, params, &raw); err != nil {
+		return nil, err
+	}
+	if isNull(raw) {
+		return nil, nil
+	}
+
+	var list protocol316.CompletionList
+	if isArray(raw) {
+		if err := json.Unmarshal(raw, &list.Items); err != nil {
+			return nil, err
+		}
+		return &list, nil
+	}
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+func (c *Client) CompletionResolve(ctx contextpkg.Context, item *protocol316.CompletionItem) (*protocol316.CompletionItem, error) {
+	var result protocol316.CompletionItem
+	if err := c.Call(ctx, protocol316.MethodCompletionItemResolve, item, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) Hover(ctx contextpkg.Context, params *protocol316.HoverParams) (*protocol316.Hover, error) {
+	var result *protocol316.Hover
+	err := c.Call(ctx, protocol316.MethodTextDocumentHover, params, &result)
+	return result, err
+}
+
+func (c *Client) SignatureHelp(ctx contextpkg.Context, params *protocol316.SignatureHelpParams) (*protocol316.SignatureHelp, error) {
+	var result *protocol316.SignatureHelp
+	err := c.Call(ctx, protocol316.MethodTextDocumentSignatureHelp, params, &result)
+	return result, err
+}
+
+// Declaration returns the declarations as locations, location links are reduced to their target selection.
+func (c *Client) Declaration(ctx contextpkg.Context, params *protocol316.DeclarationParams) ([]protocol316.Location, error) {
+	return c.locations(ctx, protocol316.MethodTextDocumentDeclaration, params)
+}
+
+// Definition returns the definitions as locations, location links are reduced to their target selection.
+func (c *Client) Definition(ctx contextpkg.Context, params *protocol316.DefinitionParams) ([]protocol316.Location, error) {
+	return c.locations(ctx, protocol316.MethodTextDocumentDefinition, params)
+}
+
+// TypeDefinition returns the type definitions as locations, location links are reduced to their target
+// selection.
+func (c *Client) TypeDefinition(ctx contextpkg.Context, params *protocol316.TypeDefinitionParams) ([]protocol316.Location, error) {
+	return c.locations(ctx, protocol316.MethodTextDocumentTypeDefinition, params)
+}
+
+// Implementation returns the implementations as locations, location links are reduced to their target
+// selection.
+func (c *Client) Implementation(ctx contextpkg.Context, params *protocol316.ImplementationParams) ([]protocol316.Location, error) {
+	return c.locations(ctx, protocol316.MethodTextDocumentImplementation, params)
+}
+
+func (c *Client) References(ctx contextpkg.Context, params *protocol316.ReferenceParams) ([]protocol316.Location, error) {
+	var result []protocol316.Location
+	err := c.Call(ctx, protocol316.MethodTextDocumentReferences, params, &result)
+	return result, err
+}
+
+func (c *Client) DocumentHighlight(ctx contextpkg.Context, params *protocol316.DocumentHighlightParams) ([]protocol316.DocumentHighlight, error) {
+	var result []protocol316.DocumentHighlight
+	err := c.Call(ctx, protocol316.MethodTextDocumentDocumentHighlight, params, &result)
+	return result, err
+}
+
+// DocumentSymbol returns either hierarchical document symbols or flat symbol information, depending on what
+// the server answered.
+func (c *Client) DocumentSymbol(ctx contextpkg.Context, params *protocol316.DocumentSymbolParams) ([]protocol316.DocumentSymbol, []protocol316.SymbolInformation, error) {
+	var raw []json.RawMessage
+	if err := c.Call(ctx, protocol316.MethodTextDocumentDocumentSymbol, params, &raw); err != nil {
+		return nil, nil, err
+	}
+	if len(raw) == 0 {
+		return nil, nil, nil
+	}
+
+	// only symbol information has a location
+	var probe struct {
+		Location *json.RawMessage `json:"location"`
+	}
+	if err := json.Unmarshal(raw[0], &probe); err != nil {
+		return nil, nil, err
+	}
+
+	if probe.Location != nil {
+		information := make([]protocol316.SymbolInformation, len(raw))
+		for i := range raw {
+			if err := json.Unmarshal(raw[i], &information[i]); err != nil {
+				return nil, nil, err
+			}
+		}
+		return nil, information, nil
+	}
+
+	symbols := make([]protocol316.DocumentSymbol, len(raw))
+	for i := range raw {
+		if err := json.Unmarshal(raw[i], &symbols[i]); err != nil {
+			return nil, nil, err
+		}
+	}
+	return symbols, nil, nil
+}
+
+// CodeAction returns the code actions, plain commands from the server are wrapped in a code action with the
+// title of the command.
+func (c *Client) CodeAction(ctx contextpkg.Context, params *protocol316.CodeActionParams) ([]protocol316.CodeAction, error) {
+	var raw []json.RawMessage
+	if err := c.Call(ctx, protocol316.MethodTextDocumentCodeAction, params, &raw); err != nil {
+		return nil, err
+	}
+
+	actions := make([]protocol316.CodeAction, 0, len(raw))
+	for _, r := range raw {
+		// a command has a string in its command field, a code action an object
+		var probe struct {
+			Command json.RawMessage `json:"command"`
+		}
+		if err := json.Unmarshal(r, &probe); err != nil {
+			return nil, err
+		}
+
+		if len(probe.Command) > 0 && probe.Command[0] == '"' {
+			var command protocol316.Command
+			if err := json.Unmarshal(r, &command); err != nil {
+				return nil, err
+			}
+			actions = append(actions, protocol316.CodeAction{Title: command.Title, Command: &command})
+			continue
+		}
+
+		var action protocol316.CodeAction
+		if err := json.Unmarshal(r, &action); err != nil {
+			return nil, err
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+func (c *Client) CodeActionResolve(ctx contextpkg.Context, action *protocol316.CodeAction) (*protocol316.CodeAction, error) {
+	var result protocol316.CodeAction
+	if err := c.Call(ctx, protocol316.MethodCodeActionResolve, action, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) CodeLens(ctx contextpkg.Context, params *protocol316.CodeLensParams) ([]protocol316.CodeLens, error) {
+	var result []protocol316.CodeLens
+	err := c.Call(ctx, protocol316.MethodTextDocumentCodeLens, params, &result)
+	return result, err
+}
+
+func (c *Client) CodeLensResolve(ctx contextpkg.Context, lens *protocol316.CodeLens) (*protocol316.CodeLens, error) {
+	var result protocol316.CodeLens
+	if err := c.Call(ctx, protocol316.MethodCodeLensResolve, lens, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) DocumentLink(ctx contextpkg.Context, params *protocol316.DocumentLinkParams) ([]protocol316.DocumentLink, error) {
+	var result []protocol316.DocumentLink
+	err := c.Call(ctx, protocol316.MethodTextDocumentDocumentLink, params, &result)
+	return result, err
+}
+
+func (c *Client) DocumentLinkResolve(ctx contextpkg.Context, link *protocol316.DocumentLink) (*protocol316.DocumentLink, error) {
+	var result protocol316.DocumentLink
+	if err := c.Call(ctx, protocol316.MethodDocumentLinkResolve, link, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *Client) DocumentColor(ctx contextpkg.Context, params *protocol316.DocumentColorParams) ([]protocol316.ColorInformation, error) {
+	var result []protocol316.ColorInformation
+	err := c.Call(ctx, protocol316.MethodTextDocumentColor, params, &result)
+	return result, err
+}
+
+func (c *Client) ColorPresentation(ctx contextpkg.Context, params *protocol316.ColorPresentationParams) ([]protocol316.ColorPresentation, error) {
+	var result []protocol316.ColorPresentation
+	err := c.Call(ctx, protocol316.MethodTextDocumentColorPresentation, params, &result)
+	return result, err
+}
+
+func (c *Client) Formatting(ctx contextpkg.Context, params *protocol316.DocumentFormattingParams) ([]protocol316.TextEdit, error) {
+	var result []protocol316.TextEdit
+	err := c.Call(ctx, protocol316.MethodTextDocumentFormatting, params, &result)
+	return result, err
+}
+
+func (c *Client) RangeFormatting(ctx contextpkg.Context, params *protocol316.DocumentRangeFormattingParams) ([]protocol316.TextEdit, error) {
+	var result []protocol316.TextEdit
+	err := c.Call(ctx, protocol316.MethodTextDocumentRangeFormatting, params, &result)
+	return result, err
+}
+
+func (c *Client) OnTypeFormatting(ctx contextpkg.Context, params *protocol316.DocumentOnTypeFormattingParams) ([]protocol316.TextEdit, error) {
+	var result []protocol316.TextEdit
+	err := c.Call(ctx, protocol316.MethodTextDocumentOnTypeFormatting, params, &result)
+	return result, err
+}
+
+func (c *Client) Rename(ctx contextpkg.Context, params *protocol316.RenameParams) (*protocol316.WorkspaceEdit, error) {
+	var result *protocol316.WorkspaceEdit
+	err := c.Call(ctx, protocol316.MethodTextDocumentRename, params, &result)
+	return result, err
+}
+
+// PrepareRenameResult is the answer to a prepare rename request. A nil result means the position cannot be
+// renamed, with DefaultBehavior set the client should pick the range itself.
+type PrepareRenameResult struct {
+	Range           protocol316.Range
+	Placeholder     string
+	DefaultBehavior bool
+}
+
+func (c *Client) PrepareRename(ctx contextpkg.Context, params *protocol316.PrepareRenameParams) (*PrepareRenameResult, error) {
+	var raw json.RawMessage
+	if err := c.Call(ctx, protocol316.MethodTextDocumentPrepareRename, params, &raw); err != nil {
+		return nil, err
+	}
+	if isNull(raw) {
+		return nil, nil
+	}
+
+	// the answer is a range, a range with a placeholder or the default behavior flag
+	var result struct {
+		Start           *protocol316.Position `json:"start"`
+		End             *protocol316.Position `json:"end"`
+		Range           *protocol316.Range    `json:"range"`
+		Placeholder     string                `json:"placeholder"`
+		DefaultBehavior bool                  `json:"defaultBehavior"`
+	}
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case result.Range != nil:
+		return &PrepareRenameResult{Range: *result.Range, Placeholder: result.Placeholder}, nil
+	case result.Start != nil && result.End != nil:
+		return &PrepareRenameResult{Range: protocol316.Range{Start: *result.Start, End: *result.End}}, nil
+	default:
+		return &PrepareRenameResult{DefaultBehavior: result.DefaultBehavior}, nil
+	}
+}
+
+func (c *Client) FoldingRange(ctx contextpkg.Context, params *protocol316.FoldingRangeParams) ([]protocol316.FoldingRange, error) {
+	var result []protocol316.FoldingRange
+	err := c.Call(ctx, protocol316.MethodTextDocumentFoldingRange, params, &result)
+	return result, err
+}
+
+func (c *Client) SelectionRange(ctx contextpkg.Context, params *protocol316.SelectionRangeParams) ([]protocol316.SelectionRange, error) {
+	var result []protocol316.SelectionRange
+	err := c.Call(ctx, protocol316.MethodTextDocumentSelectionRange, params, &result)
+	return result, err
+}
+
+func (c *Client) PrepareCallHierarchy(ctx contextpkg.Context, params *protocol316.CallHierarchyPrepareParams) ([]protocol316.CallHierarchyItem, error) {
+	var result []protocol316.CallHierarchyItem
+	err := c.Call(ctx, protocol316.MethodTextDocumentPrepareCallHierarchy, params, &result)
+	return result, err
+}
+
+func (c *Client) IncomingCalls(ctx contextpkg.Context, params *protocol316.CallHierarchyIncomingCallsParams) ([]protocol316.CallHierarchyIncomingCall, error) {
+	var result []protocol316.CallHierarchyIncomingCall
+	err := c.Call(ctx, protocol316.MethodCallHierarchyIncomingCalls, params, &result)
+	return result, err
+}
+
+func (c *Client) OutgoingCalls(ctx contextpkg.Context, params *protocol316.CallHierarchyOutgoingCallsParams) ([]protocol316.CallHierarchyOutgoingCall, error) {
+	var result []protocol316.CallHierarchyOutgoingCall
+	err := c.Call(ctx, protocol316.MethodCallHierarchyOutgoingCalls, params, &result)
+	return result, err
+}
+
+func (c *Client) SemanticTokensFull(ctx contextpkg.Context, params *protocol316.SemanticTokensParams) (*protocol316.SemanticTokens, error) {
+	var result *protocol316.SemanticTokens
+	err := c.Call(ctx, protocol316.MethodTextDocumentSemanticTokensFull, params, &result)
+	return result, err
+}
+
+// SemanticTokensFullDelta returns either the full tokens or the edits to the previous result, depending on
+// what the server answered.
+func (c *Client) SemanticTokensFullDelta(ctx contextpkg.Context, params *protocol316.SemanticTokensDeltaParams) (*protocol316.SemanticTokens, *protocol316.SemanticTokensDelta, error) {
+	var raw json.RawMessage
+	if err := c.Call(ctx, protocol316.MethodTextDocumentSemanticTokensFullDelta, params, &raw); err != nil {
+		return nil, nil, err
+	}
+	if isNull(raw) {
+		return nil, nil, nil
+	}
+
+	var probe struct {
+		Edits *json.RawMessage `json:"edits"`
+	}
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return nil, nil, err
+	}
+	if probe.Edits != nil {
+		var delta protocol316.SemanticTokensDelta
+		if err := json.Unmarshal(raw, &delta); err != nil {
+			return nil, nil, err
+		}
+		return nil, &delta, nil
+	}
+
+	var tokens protocol316.SemanticTokens
+	if err := json.Unmarshal(raw, &tokens); err != nil {
+		return nil, nil, err
+	}
+	return &tokens, nil, nil
+}
+
+func (c *Client) SemanticTokensRange(ctx contextpkg.Context, params *protocol316.SemanticTokensRangeParams) (*protocol316.SemanticTokens, error) {
+	var result *protocol316.SemanticTokens
+	err := c.Call(ctx, protocol316.MethodTextDocumentSemanticTokensRange, params, &result)
+	return result, err
+}
+
+func (c *Client) LinkedEditingRange(ctx contextpkg.Context, params *protocol316.LinkedEditingRangeParams) (*protocol316.LinkedEditingRanges, error) {
+	var result *protocol316.LinkedEditingRanges
+	err := c.Call(ctx, protocol316.MethodTextDocumentLinkedEditingRange, params, &result)
+	return result, err
+}
+
+func (c *Client) Moniker(ctx contextpkg.Context, params *protocol316.MonikerParams) ([]protocol316.Moniker, error) {
+	var result []protocol316.Moniker
+	err := c.Call(ctx, protocol316.MethodTextDocumentMoniker, params, &result)
+	return result, err
+}
+
+// DiagnosticReport is the answer to a document diagnostic request. For an unchanged report Items is empty and
+// the diagnostics of the previous result with the same id are still valid.
+type DiagnosticReport struct {
+	Kind             protocol.DocumentDiagnosticReportKind
+	ResultID         string
+	Items            []protocol316.Diagnostic
+	RelatedDocuments map[string]json.RawMessage
+}
+
+// Diagnostic pulls the diagnostics of a document.
+func (c *Client) Diagnostic(ctx contextpkg.Context, params *protocol.DocumentDiagnosticParams) (*DiagnosticReport, error) {
+	var result struct {
+		Kind             protocol.DocumentDiagnosticReportKind `json:"kind"`
+		ResultID         *string                               `json:"resultId"`
+		Items            []protocol316.Diagnostic              `json:"items"`
+		RelatedDocuments map[string]json.RawMessage            `json:"relatedDocuments"`
+	}
+	if err := c.Call(ctx, protocol.MethodTextDocumentDiagnostic, params, &result); err != nil {
+		return nil, err
+	}
+
+	report := DiagnosticReport{
+		Kind:             result.Kind,
+		Items:            result.Items,
+		RelatedDocuments: result.RelatedDocuments,
+	}
+	if result.ResultID != nil {
+		report.ResultID = *result.ResultID
+	}
+	return &report, nil
+}
+
+// locations decodes a Location | []Location | []LocationLink result into locations.
+func (c *Client) locations(ctx contextpkg.Context, method string, params any) ([]protocol316.Location, error) {
+	var raw json.RawMessage
+	if err := c.Call(ctx, method, params, &raw); err != nil {
+		return nil, err
+	}
+	if isNull(raw) {
+		return nil, nil
+	}
+
+	if !isArray(raw) {
+		var location protocol316.Location
+		if err := json.Unmarshal(raw, &location); err != nil {
+			return nil, err
+		}
+		return []protocol316.Location{location}, nil
+	}
+
+	var items []struct {
+		protocol316.Location
+		TargetURI            *uri.DocumentURI   `json:"targetUri"`
+		TargetSelectionRange *protocol316.Range `json:"targetSelectionRange"`
+	}
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, err
+	}
+
+	locations := make([]protocol316.Location, len(items))
+	for i, item := range items {
+		if item.TargetURI != nil && item.TargetSelectionRange != nil {
+			locations[i].URI = *item.TargetURI
+			locations[i].Range = *item.TargetSelectionRange
+			continue
+		}
+		locations[i] = item.Location
+	}
+	return locations, nil
+}
+
+func isNull(raw json.RawMessage) bool {
+	raw = bytes.TrimSpace(raw)
+	return len(raw) == 0 || bytes.Equal(raw, []byte("null"))
+}
+
+func isArray(raw json.RawMessage) bool {
+	raw = bytes.TrimSpace(raw)
+	return len(raw) > 0 && raw[0] == '['
+}
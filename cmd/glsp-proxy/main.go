@@ -0,0 +1,68 @@
+// Command glsp-proxy puts several language servers behind one editor connection.
+//
+// The backends are read from a JSON config file:
+//
+//	{
+//	  "backends": [
+//	    {"name": "dsl", "languages": ["dsl"], "command": "dsl-lsp"},
+//	    {"name": "sql", "patterns": ["*.sql"], "address": "localhost:4389"}
+//	  ]
+//	}
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/kjbreil/glsp/proxy"
+)
+
+type config struct {
+	Backends []*proxy.Backend `json:"backends"`
+}
+
+func main() {
+	configPath := flag.String("config", "glsp-proxy.json", "path of the backend config")
+	tcp := flag.String("tcp", "", "listen for the editor on a TCP address instead of stdio")
+	webSocket := flag.String("websocket", "", "listen for the editor on a web socket address instead of stdio")
+	debug := flag.Bool("debug", false, "log every message")
+	flag.Parse()
+
+	if err := run(*configPath, *tcp, *webSocket, *debug); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(configPath string, tcp string, webSocket string, debug bool) error {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return err
+	}
+	var c config
+	if err = json.Unmarshal(data, &c); err != nil {
+		return fmt.Errorf("%s: %w", configPath, err)
+	}
+
+	p := proxy.New(c.Backends...)
+	// stdout belongs to the editor when serving over stdio
+	p.Log = slog.New(slog.NewTextHandler(os.Stderr, nil))
+	if err = p.Start(context.Background()); err != nil {
+		return err
+	}
+	defer p.Close()
+
+	s := p.Server(debug)
+	switch {
+	case tcp != "":
+		return s.RunTCP(tcp)
+	case webSocket != "":
+		return s.RunWebSocket(webSocket)
+	default:
+		return s.RunStdio()
+	}
+}
@@ -0,0 +1,33 @@
+// Package codelens has the code lenses of a document, commands shown in the editor above the code they are
+// about.
+package codelens
+
+import (
+	"github.com/kjbreil/glsp/pkg/commands"
+	"github.com/kjbreil/glsp/pkg/editreader"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// Lens is a command shown above a range of a document. A lens without a command is resolved when it
+// becomes visible, the language fills in the command then.
+type Lens struct {
+	CharRange editreader.CharRange
+	Command   *commands.Reference
+}
+
+// Protocol returns the code lens, data is sent with it so the lens can be resolved. ok is false when the
+// lens has no range.
+func (l *Lens) Protocol(data any) (lens protocol.CodeLens, ok bool) {
+	r := l.CharRange.ProtocolRange()
+	if r == nil {
+		return lens, false
+	}
+	lens = protocol.CodeLens{
+		Range: *r,
+		Data:  data,
+	}
+	if l.Command != nil {
+		lens.Command = l.Command.Protocol()
+	}
+	return lens, true
+}
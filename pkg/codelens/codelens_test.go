@@ -0,0 +1,38 @@
+package codelens
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kjbreil/glsp/pkg/commands"
+	"github.com/kjbreil/glsp/pkg/editreader"
+	"github.com/kjbreil/glsp/pkg/location"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+func TestLensProtocol(t *testing.T) {
+	f, err := editreader.New(strings.NewReader("func main"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lens := Lens{
+		CharRange: editreader.CharRange{
+			Start: f.CharBefore(location.Point{Line: 0, Column: 1}),
+			End:   f.CharBefore(location.Point{Line: 0, Column: 4}),
+		},
+		Command: &commands.Reference{Title: "Run", Name: "run"},
+	}
+
+	got, ok := lens.Protocol(1)
+	want := protocol.Range{End: protocol.Position{Character: 4}}
+	if !ok || got.Range != want || got.Data != 1 {
+		t.Errorf("Protocol() = %v, %v, want range %v", got, ok, want)
+	}
+	if got.Command == nil || got.Command.Title != "Run" || got.Command.Command != "run" {
+		t.Errorf("Protocol() command = %v", got.Command)
+	}
+
+	if _, ok := (&Lens{}).Protocol(nil); ok {
+		t.Errorf("Protocol() of a lens without a range ok = true")
+	}
+}
@@ -0,0 +1,135 @@
+// Package color has the colors written in a document, which the editor shows with a swatch and a picker,
+// and finds hex and rgb color literals in text.
+package color
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kjbreil/glsp/pkg/location"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// Color is a color written at a range of a document, its components are between 0 and 1.
+type Color struct {
+	Range                   location.Range
+	Red, Green, Blue, Alpha float64
+}
+
+func (c *Color) Protocol() protocol.ColorInformation {
+	return protocol.ColorInformation{
+		Range: c.Range.ProtocolRange(),
+		Color: protocol.Color{
+			Red:   protocol.Decimal(c.Red),
+			Green: protocol.Decimal(c.Green),
+			Blue:  protocol.Decimal(c.Blue),
+			Alpha: protocol.Decimal(c.Alpha),
+		},
+	}
+}
+
+var (
+	hexPattern = regexp.MustCompile(`#(?:[0-9a-fA-F]{8}|[0-9a-fA-F]{6}|[0-9a-fA-F]{3,4})\b`)
+	rgbPattern = regexp.MustCompile(`\brgba?\(\s*(\d{1,3}%?)\s*,\s*(\d{1,3}%?)\s*,\s*(\d{1,3}%?)\s*(?:,\s*(\d*\.?\d+%?)\s*)?\)`)
+)
+
+// Detect returns the hex colors like #f80 and #ff8800cc, and the rgb colors like rgb(255, 136, 0) and
+// rgba(255, 136, 0, 0.5) of the text.
+func Detect(text string) []Color {
+	var colors []Color
+	for line, content := range strings.Split(text, "\n") {
+		for _, m := range hexPattern.FindAllStringIndex(content, -1) {
+			// #define is a word, not a color
+			if m[0] > 0 && isWord(content[m[0]-1]) {
+				continue
+			}
+			c := hex(content[m[0]+1 : m[1]])
+			c.Range = lineRange(content, line, m[0], m[1])
+			colors = append(colors, c)
+		}
+		for _, m := range rgbPattern.FindAllStringSubmatchIndex(content, -1) {
+			c := Color{
+				Red:   component(content[m[2]:m[3]], 255),
+				Green: component(content[m[4]:m[5]], 255),
+				Blue:  component(content[m[6]:m[7]], 255),
+				Alpha: 1,
+			}
+			if m[8] >= 0 {
+				c.Alpha = component(content[m[8]:m[9]], 1)
+			}
+			c.Range = lineRange(content, line, m[0], m[1])
+			colors = append(colors, c)
+		}
+	}
+	return colors
+}
+
+// hex reads the 3, 4, 6 or 8 hex digits of a color, the short forms repeat each digit.
+func hex(digits string) Color {
+	if len(digits) <= 4 {
+		var long strings.Builder
+		for _, d := range digits {
+			long.WriteRune(d)
+			long.WriteRune(d)
+		}
+		digits = long.String()
+	}
+	if len(digits) == 6 {
+		digits += "ff"
+	}
+	v, _ := strconv.ParseUint(digits, 16, 32)
+	return Color{
+		Red:   float64(v>>24&0xff) / 255,
+		Green: float64(v>>16&0xff) / 255,
+		Blue:  float64(v>>8&0xff) / 255,
+		Alpha: float64(v&0xff) / 255,
+	}
+}
+
+// component reads a number up to max or a percentage, clamped between 0 and 1.
+func component(s string, max float64) float64 {
+	if p, ok := strings.CutSuffix(s, "%"); ok {
+		v, _ := strconv.ParseFloat(p, 64)
+		return math.Min(v/100, 1)
+	}
+	v, _ := strconv.ParseFloat(s, 64)
+	return math.Min(v/max, 1)
+}
+
+// Presentations returns the ways to write the color, a hex and an rgb literal, with the notation of current,
+// the text the color replaces, first.
+func Presentations(c protocol.Color, current string) []string {
+	r, g, b := byteOf(c.Red), byteOf(c.Green), byteOf(c.Blue)
+	hex := fmt.Sprintf("#%02x%02x%02x", r, g, b)
+	rgb := fmt.Sprintf("rgb(%d, %d, %d)", r, g, b)
+	if c.Alpha < 1 {
+		hex += fmt.Sprintf("%02x", byteOf(c.Alpha))
+		rgb = fmt.Sprintf("rgba(%d, %d, %d, %s)", r, g, b, strconv.FormatFloat(math.Round(float64(c.Alpha)*100)/100, 'f', -1, 64))
+	}
+	if strings.HasPrefix(current, "#") && strings.ToUpper(current) == current {
+		hex = strings.ToUpper(hex)
+	}
+	if strings.HasPrefix(current, "rgb") {
+		return []string{rgb, hex}
+	}
+	return []string{hex, rgb}
+}
+
+func byteOf(v protocol.Decimal) int {
+	return int(math.Round(math.Max(0, math.Min(float64(v), 1)) * 255))
+}
+
+func lineRange(content string, line, start, end int) location.Range {
+	return location.Range{
+		Start: location.Point{Line: line, Column: utf8.RuneCountInString(content[:start])},
+		End:   location.Point{Line: line, Column: utf8.RuneCountInString(content[:end])},
+	}
+}
+
+func isWord(b byte) bool {
+	return b == '_' || '0' <= b && b <= '9' || 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z'
+}
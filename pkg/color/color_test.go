@@ -0,0 +1,36 @@
+package color
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kjbreil/glsp/pkg/location"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+func TestDetect(t *testing.T) {
+	text := "#define X\n" +
+		"é: #F80; b: #ff880080;\n" +
+		"c: rgba(255, 0, 50%, 0.5)"
+
+	got := Detect(text)
+	want := []Color{
+		{Range: location.Range{Start: location.Point{Line: 1, Column: 3}, End: location.Point{Line: 1, Column: 7}}, Red: 1, Green: 136.0 / 255, Blue: 0, Alpha: 1},
+		{Range: location.Range{Start: location.Point{Line: 1, Column: 12}, End: location.Point{Line: 1, Column: 21}}, Red: 1, Green: 136.0 / 255, Blue: 0, Alpha: 128.0 / 255},
+		{Range: location.Range{Start: location.Point{Line: 2, Column: 3}, End: location.Point{Line: 2, Column: 25}}, Red: 1, Green: 0, Blue: 0.5, Alpha: 0.5},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Detect() = %v, want %v", got, want)
+	}
+}
+
+func TestPresentations(t *testing.T) {
+	c := protocol.Color{Red: 1, Green: 0.5, Blue: 0, Alpha: 0.5}
+	if got, want := Presentations(c, "rgb(0, 0, 0)"), []string{"rgba(255, 128, 0, 0.5)", "#ff800080"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Presentations() = %v, want %v", got, want)
+	}
+	c.Alpha = 1
+	if got, want := Presentations(c, "#FFF"), []string{"#FF8000", "rgb(255, 128, 0)"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Presentations() = %v, want %v", got, want)
+	}
+}
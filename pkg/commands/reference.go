@@ -0,0 +1,27 @@
+package commands
+
+import (
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// Reference is a command shown to the user, like on a code lens, that runs a registered command with
+// arguments when it is clicked.
+type Reference struct {
+	Title     string
+	Name      string
+	Arguments []any
+}
+
+// Reference returns a reference to the command with the title shown to the user and the arguments the
+// command is executed with.
+func (c Command) Reference(title string, args ...any) Reference {
+	return Reference{Title: title, Name: c.Name, Arguments: args}
+}
+
+func (r *Reference) Protocol() *protocol.Command {
+	return &protocol.Command{
+		Title:     r.Title,
+		Command:   r.Name,
+		Arguments: r.Arguments,
+	}
+}
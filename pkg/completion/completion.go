@@ -4,40 +4,99 @@ import (
 	"github.com/kjbreil/glsp/internal/helpers"
 	"github.com/kjbreil/glsp/pkg/markdown"
 	protocol316 "github.com/kjbreil/glsp/protocol_3_16"
-	"strings"
 )
 
 type Completion struct {
-	Markdown   markdown.Markdown
-	Detail     string
+	Markdown markdown.Markdown
+	// Detail is shown next to the label, the description of Markdown is used when it is empty
+	Detail string
+	// Trigger is the text the completion is found by, it is the label unless Label is set
 	Trigger    string
+	Label      string
 	InsertText string
+	// Snippet replaces InsertText when it is set
+	Snippet *Snippet
+	// Kind is the icon of the completion, CompletionItemKindFunction when it is not set
+	Kind       protocol316.CompletionItemKind
+	Deprecated bool
+	// SortText and FilterText replace the label for sorting and filtering
+	SortText   string
+	FilterText string
+	// Preselect selects the completion when the list is shown
+	Preselect        bool
+	CommitCharacters []string
+	// AdditionalTextEdits are applied with the completion, for example to add an import
+	AdditionalTextEdits []protocol316.TextEdit
+	// Command is executed after the completion is inserted
+	Command *protocol316.Command
 }
 
+// Protocol returns the completion item with its documentation.
 func (c *Completion) Protocol() protocol316.CompletionItem {
-	if strings.Contains(c.InsertText, "$1") {
-		return protocol316.CompletionItem{
-			Detail: c.Markdown.Detail(),
-			Documentation: protocol316.MarkupContent{
-				Kind:  protocol316.MarkupKindMarkdown,
-				Value: c.Markdown.String(),
-			},
-			Kind:             ptr(protocol316.CompletionItemKindFunction),
-			Label:            c.Trigger,
-			InsertText:       &c.InsertText,
-			InsertTextFormat: helpers.Ptr(protocol316.InsertTextFormatSnippet),
+	item := c.ProtocolUnresolved()
+	item.Documentation = c.Documentation()
+	return item
+}
+
+// ProtocolUnresolved returns the completion item without documentation, the documentation is sent with
+// Documentation when the client resolves the item.
+func (c *Completion) ProtocolUnresolved() protocol316.CompletionItem {
+	item := protocol316.CompletionItem{
+		Label:               c.Trigger,
+		Kind:                ptr(protocol316.CompletionItemKindFunction),
+		Detail:              c.Markdown.Detail(),
+		CommitCharacters:    c.CommitCharacters,
+		AdditionalTextEdits: c.AdditionalTextEdits,
+		Command:             c.Command,
+	}
+	if c.Label != "" {
+		item.Label = c.Label
+	}
+	if c.Kind != 0 {
+		item.Kind = ptr(c.Kind)
+	}
+	if c.Detail != "" {
+		item.Detail = &c.Detail
+	}
+	if c.Deprecated {
+		item.Tags = []protocol316.CompletionItemTag{protocol316.CompletionItemTagDeprecated}
+	}
+	if c.SortText != "" {
+		item.SortText = &c.SortText
+	}
+	if c.FilterText != "" {
+		item.FilterText = &c.FilterText
+	} else if c.Label != "" && c.Label != c.Trigger {
+		item.FilterText = &c.Trigger
+	}
+	if c.Preselect {
+		item.Preselect = helpers.Ptr(true)
+	}
+
+	switch {
+	case c.Snippet != nil:
+		item.InsertText = helpers.Ptr(c.Snippet.String())
+		if c.Snippet.IsSnippet() {
+			item.InsertTextFormat = helpers.Ptr(protocol316.InsertTextFormatSnippet)
+		}
+	case c.InsertText != "":
+		item.InsertText = &c.InsertText
+		if HasTabStops(c.InsertText) {
+			item.InsertTextFormat = helpers.Ptr(protocol316.InsertTextFormatSnippet)
 		}
 	}
 
-	return protocol316.CompletionItem{
-		Detail: c.Markdown.Detail(),
-		Documentation: protocol316.MarkupContent{
-			Kind:  protocol316.MarkupKindMarkdown,
-			Value: c.Markdown.String(),
-		},
-		Kind:       ptr(protocol316.CompletionItemKindFunction),
-		Label:      c.Trigger,
-		InsertText: &c.InsertText,
+	return item
+}
+
+// Documentation renders the markdown of the completion, it is nil when the completion has no markdown.
+func (c *Completion) Documentation() any {
+	if c.Markdown == (markdown.Markdown{}) {
+		return nil
+	}
+	return protocol316.MarkupContent{
+		Kind:  protocol316.MarkupKindMarkdown,
+		Value: c.Markdown.String(),
 	}
 }
 
@@ -1,13 +1,22 @@
 package completion
 
-import protocol "github.com/kjbreil/glsp/protocol_3_16"
+import (
+	"sync/atomic"
+
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// generations numbers the lists of completions, a list gets a new generation when it is created or changed.
+var generations atomic.Uint64
 
 type Completions struct {
 	c []Completion
+	// generation identifies the completions, an Engine built from them is reused while it is the same
+	generation uint64
 }
 
 func NewCompletions(c ...Completion) Completions {
-	return Completions{c: c}
+	return Completions{c: c, generation: generations.Add(1)}
 }
 
 func (c *Completions) Len() int {
@@ -18,19 +27,23 @@ func (c *Completions) Slice() []Completion {
 	return c.c
 }
 
+// Index returns the completion at i so it can be changed, the completions get a new generation.
 func (c *Completions) Index(i int) *Completion {
 	if i < 0 || i >= len(c.c) {
 		return &Completion{}
 	}
+	c.generation = generations.Add(1)
 	return &c.c[i]
 }
 
 func (c *Completions) Combine(comps Completions) {
 	c.c = append(c.c, comps.c...)
+	c.generation = generations.Add(1)
 }
 
 func (c *Completions) Add(cmp Completion) {
 	c.c = append(c.c, cmp)
+	c.generation = generations.Add(1)
 }
 
 func (c *Completions) Protocol() []protocol.CompletionItem {
@@ -0,0 +1,249 @@
+package completion
+
+import (
+	"container/heap"
+	"fmt"
+	"slices"
+	"sort"
+	"unicode"
+
+	"github.com/kjbreil/glsp/pkg/editreader"
+	"github.com/kjbreil/glsp/pkg/location"
+)
+
+// DefaultLimit is the number of completions an Engine returns when its Limit is not set.
+const DefaultLimit = 100
+
+// Engine filters a large set of completions down to the ones that fuzzy match the word typed at the cursor.
+// Completions are matched by FilterText, Trigger or Label, the first that is set.
+type Engine struct {
+	// Limit caps the number of completions returned, DefaultLimit when it is 0
+	Limit int
+
+	completions []Completion
+	// generation is the generation of the completions the engine was built from
+	generation uint64
+	keys       [][]rune
+	// lowerKeys are the keys in lower case, matching is case insensitive
+	lowerKeys [][]rune
+}
+
+func NewEngine(completions Completions) *Engine {
+	e := &Engine{
+		// the completions are copied, changing the ones of the language in place does not change the engine
+		completions: slices.Clone(completions.Slice()),
+		generation:  completions.generation,
+		keys:        make([][]rune, completions.Len()),
+		lowerKeys:   make([][]rune, completions.Len()),
+	}
+	for i := range e.completions {
+		e.keys[i] = []rune(e.completions[i].matchText())
+		e.lowerKeys[i] = lower(e.keys[i])
+	}
+	return e
+}
+
+func (c *Completion) matchText() string {
+	switch {
+	case c.FilterText != "":
+		return c.FilterText
+	case c.Trigger != "":
+		return c.Trigger
+	default:
+		return c.Label
+	}
+}
+
+// Prefix returns the part of the word before the point in the document.
+func Prefix(f *editreader.File, p location.Point) string {
+	_, prefix := Word(f, p)
+	return prefix
+}
+
+// Word returns the range of the word around the point in the document and the part of it before the point.
+// The range is empty at the point when the point is not next to a word.
+func Word(f *editreader.File, p location.Point) (location.Range, string) {
+	word := location.Range{Start: p, End: p}
+	before := f.CharBefore(p)
+
+	var prefix []rune
+	for c := before; isWordChar(c, p.Line); c = c.Previous() {
+		prefix = append(prefix, c.Rune())
+		word.Start = c.Point()
+		if c.Previous() == c {
+			break
+		}
+	}
+	for i, j := 0, len(prefix)-1; i < j; i, j = i+1, j-1 {
+		prefix[i], prefix[j] = prefix[j], prefix[i]
+	}
+
+	for c := before; c.Next() != c && isWordChar(c.Next(), p.Line); c = c.Next() {
+		word.End = c.Next().Point().NewColumn()
+	}
+	return word, string(prefix)
+}
+
+// isWordChar reports whether the character is part of a word on the line.
+func isWordChar(c *editreader.Char, line int) bool {
+	return !c.IsEmpty() && c.Point().Line == line && (c.IsAlphaNumeric() || c.Rune() == '_')
+}
+
+// CompleteAt matches the completions against the word before the point in the document.
+func (e *Engine) CompleteAt(f *editreader.File, p location.Point) (Completions, bool) {
+	return e.Complete(Prefix(f, p))
+}
+
+// BuiltFrom reports whether the engine was built from the completions. A language that returns other
+// completions, or changed them with Add, Combine or Index, needs a new engine. Completions changed through
+// Slice are not noticed.
+func (e *Engine) BuiltFrom(completions Completions) bool {
+	return e.generation == completions.generation
+}
+
+type match struct {
+	index  int
+	score  int
+	length int
+}
+
+// better orders matches by score, shorter keys and then the order of the completions.
+func (m match) better(o match) bool {
+	if m.score != o.score {
+		return m.score > o.score
+	}
+	if m.length != o.length {
+		return m.length < o.length
+	}
+	return m.index < o.index
+}
+
+// matchHeap keeps the worst of the best matches on top so it can be replaced by a better one.
+type matchHeap []match
+
+func (h matchHeap) Len() int           { return len(h) }
+func (h matchHeap) Less(i, j int) bool { return h[j].better(h[i]) }
+func (h matchHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *matchHeap) Push(x any)        { *h = append(*h, x.(match)) }
+func (h *matchHeap) Pop() any {
+	old := *h
+	m := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return m
+}
+
+// Complete returns the completions that match prefix, best first. The list is incomplete when more completions
+// matched than the limit, the client should ask again as more is typed. The order is kept in SortText of
+// completions that do not set their own.
+func (e *Engine) Complete(prefix string) (Completions, bool) {
+	limit := e.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+
+	pattern := []rune(prefix)
+	lowerPattern := lower(pattern)
+
+	best := make(matchHeap, 0, min(len(e.keys), limit))
+	matched := 0
+	for i := range e.keys {
+		s, ok := fuzzyScore(pattern, lowerPattern, e.keys[i], e.lowerKeys[i])
+		if !ok {
+			continue
+		}
+		matched++
+		m := match{index: i, score: s, length: len(e.keys[i])}
+		if len(best) < limit {
+			heap.Push(&best, m)
+		} else if m.better(best[0]) {
+			best[0] = m
+			heap.Fix(&best, 0)
+		}
+	}
+	sort.Slice(best, func(i, j int) bool {
+		return best[i].better(best[j])
+	})
+
+	result := make([]Completion, len(best))
+	for i, m := range best {
+		result[i] = e.completions[m.index]
+		if result[i].SortText == "" {
+			result[i].SortText = fmt.Sprintf("%05d", i)
+		}
+	}
+	return NewCompletions(result...), matched > limit
+}
+
+// Match rates how well pattern fuzzy matches candidate the way an Engine does, ok is false when it does not
+// match at all.
+func Match(pattern string, candidate string) (score int, ok bool) {
+	p, c := []rune(pattern), []rune(candidate)
+	return fuzzyScore(p, lower(p), c, lower(c))
+}
+
+// fuzzyScore rates how well pattern matches key, the runes of pattern have to appear in key in order. Matches at
+// the start of key, at the start of words in key, of consecutive runes and in the same case score higher.
+func fuzzyScore(pattern, lowerPattern, key, lowerKey []rune) (int, bool) {
+	if len(pattern) == 0 {
+		return 0, true
+	}
+	if len(pattern) > len(key) {
+		return 0, false
+	}
+
+	total := 0
+	prefix := true
+	previous := -2
+	p := 0
+	for k := 0; k < len(key) && p < len(pattern); k++ {
+		if lowerKey[k] != lowerPattern[p] {
+			prefix = false
+			continue
+		}
+
+		total++
+		if key[k] == pattern[p] {
+			total++
+		}
+		switch {
+		case k == 0:
+			total += 8
+		case wordStart(key, k):
+			total += 4
+		}
+		if previous == k-1 {
+			total += 4
+		} else if previous >= 0 {
+			// a gap inside the match
+			total -= 2
+		}
+		previous = k
+		p++
+	}
+	if p < len(pattern) {
+		return 0, false
+	}
+	if prefix {
+		total += 16
+	}
+	return total, true
+}
+
+// lower maps every rune to lower case, unlike strings.ToLower the runes stay at the same index.
+func lower(runes []rune) []rune {
+	lowered := make([]rune, len(runes))
+	for i, r := range runes {
+		lowered[i] = unicode.ToLower(r)
+	}
+	return lowered
+}
+
+// wordStart reports whether the rune at k starts a word, after a separator or as an upper case rune after a
+// lower case one.
+func wordStart(key []rune, k int) bool {
+	before := key[k-1]
+	if !unicode.IsLetter(before) && !unicode.IsDigit(before) {
+		return true
+	}
+	return unicode.IsUpper(key[k]) && unicode.IsLower(before)
+}
@@ -0,0 +1,159 @@
+package completion
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/kjbreil/glsp/pkg/editreader"
+	"github.com/kjbreil/glsp/pkg/location"
+)
+
+func triggers(completions Completions) []string {
+	var labels []string
+	for _, c := range completions.Slice() {
+		labels = append(labels, c.Trigger)
+	}
+	return labels
+}
+
+func TestEngine_Complete(t *testing.T) {
+	e := NewEngine(NewCompletions(
+		Completion{Trigger: "getValue"},
+		Completion{Trigger: "gv"},
+		Completion{Trigger: "forget_value"},
+		Completion{Trigger: "GetVisible"},
+		Completion{Trigger: "set"},
+	))
+
+	tests := []struct {
+		prefix string
+		want   []string
+	}{
+		{"gv", []string{"gv", "getValue", "GetVisible", "forget_value"}},
+		{"getv", []string{"getValue", "GetVisible", "forget_value"}},
+		{"set", []string{"set"}},
+		{"xyz", nil},
+	}
+	for _, tt := range tests {
+		got, incomplete := e.Complete(tt.prefix)
+		if strings.Join(triggers(got), ",") != strings.Join(tt.want, ",") {
+			t.Errorf("Complete(%q) = %v, want %v", tt.prefix, triggers(got), tt.want)
+		}
+		if incomplete {
+			t.Errorf("Complete(%q) incomplete = true, want false", tt.prefix)
+		}
+	}
+}
+
+func TestEngine_Complete_limit(t *testing.T) {
+	e := NewEngine(largeCompletions(50))
+	e.Limit = 10
+
+	got, incomplete := e.Complete("")
+	if got.Len() != 10 || !incomplete {
+		t.Errorf("Complete() = %d completions incomplete %v, want 10 and true", got.Len(), incomplete)
+	}
+	if got.Index(0).SortText != "00000" || got.Index(9).SortText != "00009" {
+		t.Errorf("Complete() sort texts = %q to %q", got.Index(0).SortText, got.Index(9).SortText)
+	}
+
+	got, incomplete = e.Complete("item_49")
+	if got.Len() != 1 || incomplete {
+		t.Errorf("Complete(item_49) = %d completions incomplete %v, want 1 and false", got.Len(), incomplete)
+	}
+}
+
+func TestPrefix(t *testing.T) {
+	f, err := editreader.New(strings.NewReader("select foo_ba.x\nnext"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		point location.Point
+		want  string
+	}{
+		{location.Point{Line: 0, Column: 0}, ""},
+		{location.Point{Line: 0, Column: 3}, "sel"},
+		{location.Point{Line: 0, Column: 13}, "foo_ba"},
+		{location.Point{Line: 0, Column: 14}, ""},
+		{location.Point{Line: 1, Column: 2}, "ne"},
+	}
+	for _, tt := range tests {
+		if got := Prefix(f, tt.point); got != tt.want {
+			t.Errorf("Prefix(%v) = %q, want %q", tt.point, got, tt.want)
+		}
+	}
+}
+
+func TestWord(t *testing.T) {
+	f, err := editreader.New(strings.NewReader("select foo_ba.x\nnext"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		point  location.Point
+		word   location.Range
+		prefix string
+	}{
+		{location.Point{Line: 0, Column: 0}, location.Range{End: location.Point{Column: 6}}, ""},
+		{location.Point{Line: 0, Column: 3}, location.Range{End: location.Point{Column: 6}}, "sel"},
+		{location.Point{Line: 0, Column: 9}, location.Range{Start: location.Point{Column: 7}, End: location.Point{Column: 13}}, "fo"},
+		{location.Point{Line: 0, Column: 15}, location.Range{Start: location.Point{Column: 14}, End: location.Point{Column: 15}}, "x"},
+		{location.Point{Line: 1, Column: 4}, location.Range{Start: location.Point{Line: 1}, End: location.Point{Line: 1, Column: 4}}, "next"},
+	}
+	for _, tt := range tests {
+		word, prefix := Word(f, tt.point)
+		if word != tt.word || prefix != tt.prefix {
+			t.Errorf("Word(%v) = %v, %q, want %v, %q", tt.point, word, prefix, tt.word, tt.prefix)
+		}
+	}
+}
+
+func TestEngine_BuiltFrom(t *testing.T) {
+	completions := largeCompletions(3)
+	e := NewEngine(completions)
+	if !e.BuiltFrom(completions) {
+		t.Errorf("BuiltFrom(same completions) = false")
+	}
+	if e.BuiltFrom(largeCompletions(3)) {
+		t.Errorf("BuiltFrom(new completions) = true")
+	}
+	completions.Index(1).Detail = "changed"
+	if e.BuiltFrom(completions) {
+		t.Errorf("BuiltFrom(completions changed in place) = true")
+	}
+	completions = largeCompletions(3)
+	completions.Add(Completion{Trigger: "more"})
+	if e.BuiltFrom(completions) {
+		t.Errorf("BuiltFrom(longer completions) = true")
+	}
+}
+
+func largeCompletions(n int) Completions {
+	completions := make([]Completion, n)
+	for i := range completions {
+		completions[i] = Completion{Trigger: fmt.Sprintf("item_%d", i)}
+	}
+	return NewCompletions(completions...)
+}
+
+func BenchmarkEngine_Complete(b *testing.B) {
+	words := []string{"select", "insert", "update", "delete", "create", "Table", "Index", "Value", "Column", "Where"}
+	completions := make([]Completion, 20000)
+	for i := range completions {
+		completions[i] = Completion{Trigger: fmt.Sprintf("%s%s_%d", words[i%len(words)], words[(i/len(words))%len(words)], i)}
+	}
+	e := NewEngine(NewCompletions(completions...))
+
+	for _, prefix := range []string{"", "s", "selIn", "upd_19"} {
+		b.Run(fmt.Sprintf("prefix=%q", prefix), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				e.Complete(prefix)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package completion
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Snippet builds the insert text of a completion in the snippet syntax of the protocol. Text added to it is
+// escaped, so only the tab stops, placeholders, choices and variables are interpreted by the client.
+type Snippet struct {
+	sb strings.Builder
+	// snippet is set once something other than text is added
+	snippet bool
+}
+
+func NewSnippet() *Snippet {
+	return &Snippet{}
+}
+
+// Text adds literal text.
+func (s *Snippet) Text(text string) *Snippet {
+	s.sb.WriteString(escape(text, "$}\\"))
+	return s
+}
+
+// TabStop adds tab stop n, the cursor stops at the tab stops in order, 0 is the final position.
+func (s *Snippet) TabStop(n int) *Snippet {
+	s.snippet = true
+	s.sb.WriteByte('$')
+	s.sb.WriteString(strconv.Itoa(n))
+	return s
+}
+
+// Placeholder adds tab stop n with text selected at it.
+func (s *Snippet) Placeholder(n int, text string) *Snippet {
+	s.snippet = true
+	s.sb.WriteString("${")
+	s.sb.WriteString(strconv.Itoa(n))
+	s.sb.WriteByte(':')
+	s.sb.WriteString(escape(text, "$}\\"))
+	s.sb.WriteByte('}')
+	return s
+}
+
+// Choice adds tab stop n that offers the choices, the first is inserted.
+func (s *Snippet) Choice(n int, choices ...string) *Snippet {
+	if len(choices) == 0 {
+		return s.TabStop(n)
+	}
+	s.snippet = true
+	s.sb.WriteString("${")
+	s.sb.WriteString(strconv.Itoa(n))
+	s.sb.WriteByte('|')
+	for i, choice := range choices {
+		if i > 0 {
+			s.sb.WriteByte(',')
+		}
+		s.sb.WriteString(escape(choice, "$}\\,|"))
+	}
+	s.sb.WriteString("|}")
+	return s
+}
+
+// Variable adds a variable the client fills in, like TM_SELECTED_TEXT or CURRENT_YEAR. The default is inserted
+// when the client does not know the variable.
+func (s *Snippet) Variable(name string, defaultText string) *Snippet {
+	s.snippet = true
+	s.sb.WriteString("${")
+	s.sb.WriteString(name)
+	if defaultText != "" {
+		s.sb.WriteByte(':')
+		s.sb.WriteString(escape(defaultText, "$}\\"))
+	}
+	s.sb.WriteByte('}')
+	return s
+}
+
+// IsSnippet reports whether the result needs the snippet format, a snippet of only text is plain text.
+func (s *Snippet) IsSnippet() bool {
+	return s.snippet
+}
+
+// String returns the snippet, or the text unescaped when it is not a snippet.
+func (s *Snippet) String() string {
+	if !s.snippet {
+		return PlainText(s.sb.String())
+	}
+	return s.sb.String()
+}
+
+// PlainText returns the text the snippet inserts for a client without snippet support.
+func (s *Snippet) PlainText() string {
+	return PlainText(s.sb.String())
+}
+
+func escape(text string, special string) string {
+	if !strings.ContainsAny(text, special) {
+		return text
+	}
+	var sb strings.Builder
+	for _, r := range text {
+		if strings.ContainsRune(special, r) {
+			sb.WriteByte('\\')
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// PlainText strips the syntax of a snippet, tab stops and variables are removed, placeholders and variables
+// with a default are replaced by their text and choices by the first choice.
+func PlainText(snippet string) string {
+	var sb strings.Builder
+	p := snippetParser{s: snippet}
+	p.text(&sb, false)
+	return sb.String()
+}
+
+// HasTabStops reports whether the text has a tab stop, placeholder or choice. Insert text with one is sent
+// as a snippet, a $ that does not start one is inserted as it is.
+func HasTabStops(text string) bool {
+	var sb strings.Builder
+	p := snippetParser{s: text}
+	p.text(&sb, false)
+	return p.tabStops
+}
+
+type snippetParser struct {
+	s string
+	i int
+	// tabStops is set once a tab stop, placeholder or choice is parsed
+	tabStops bool
+}
+
+func (p *snippetParser) peek() byte {
+	if p.i < len(p.s) {
+		return p.s[p.i]
+	}
+	return 0
+}
+
+// text writes the plain text up to the end of the snippet, or to the closing brace when nested.
+func (p *snippetParser) text(sb *strings.Builder, nested bool) {
+	for p.i < len(p.s) {
+		c := p.s[p.i]
+		switch {
+		case c == '\\' && p.i+1 < len(p.s) && strings.IndexByte("$}\\", p.s[p.i+1]) >= 0:
+			sb.WriteByte(p.s[p.i+1])
+			p.i += 2
+		case c == '}' && nested:
+			p.i++
+			return
+		case c == '$':
+			if !p.element(sb) {
+				sb.WriteByte('$')
+				p.i++
+			}
+		default:
+			sb.WriteByte(c)
+			p.i++
+		}
+	}
+}
+
+// element parses the tab stop, placeholder, choice or variable at the $, it reports false and consumes
+// nothing when the $ does not start one.
+func (p *snippetParser) element(sb *strings.Builder) bool {
+	start := p.i
+	p.i++
+
+	if name := p.name(); name != "" {
+		// $1 or $VARIABLE, the value of a variable is not known
+		p.tabStops = p.tabStops || isDigit(name[0])
+		return true
+	}
+	if p.peek() != '{' {
+		p.i = start
+		return false
+	}
+	p.i++
+	name := p.name()
+	if name == "" {
+		p.i = start
+		return false
+	}
+	p.tabStops = p.tabStops || isDigit(name[0])
+
+	switch p.peek() {
+	case '}':
+		p.i++
+	case ':':
+		p.i++
+		p.text(sb, true)
+	case '|':
+		p.i++
+		p.choice(sb)
+	default:
+		// a transform, it is skipped up to its closing brace
+		p.skip()
+	}
+	return true
+}
+
+// name reads the number of a tab stop or the name of a variable.
+func (p *snippetParser) name() string {
+	start := p.i
+	digits := isDigit(p.peek())
+	for p.i < len(p.s) {
+		c := p.s[p.i]
+		switch {
+		case c >= '0' && c <= '9':
+		case !digits && (c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'):
+		default:
+			return p.s[start:p.i]
+		}
+		p.i++
+	}
+	return p.s[start:p.i]
+}
+
+// choice writes the first choice and skips the others.
+func (p *snippetParser) choice(sb *strings.Builder) {
+	first := true
+	for p.i < len(p.s) {
+		c := p.s[p.i]
+		switch {
+		case c == '\\' && p.i+1 < len(p.s) && strings.IndexByte("$}\\,|", p.s[p.i+1]) >= 0:
+			if first {
+				sb.WriteByte(p.s[p.i+1])
+			}
+			p.i += 2
+		case c == ',':
+			first = false
+			p.i++
+		case c == '|' && p.i+1 < len(p.s) && p.s[p.i+1] == '}':
+			p.i += 2
+			return
+		default:
+			if first {
+				sb.WriteByte(c)
+			}
+			p.i++
+		}
+	}
+}
+
+// skip skips to the closing brace, braces of nested elements are matched.
+func (p *snippetParser) skip() {
+	depth := 0
+	for p.i < len(p.s) {
+		switch p.s[p.i] {
+		case '\\':
+			p.i += 2
+			continue
+		case '$':
+			if p.i+1 < len(p.s) && p.s[p.i+1] == '{' {
+				depth++
+				p.i++
+			}
+		case '}':
+			if depth == 0 {
+				p.i++
+				return
+			}
+			depth--
+		}
+		p.i++
+	}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,99 @@
+package completion
+
+import "testing"
+
+func TestSnippet(t *testing.T) {
+	tests := []struct {
+		name        string
+		snippet     *Snippet
+		want        string
+		wantPlain   string
+		wantSnippet bool
+	}{
+		{
+			name:      "text only",
+			snippet:   NewSnippet().Text("cost $5 {x}"),
+			want:      "cost $5 {x}",
+			wantPlain: "cost $5 {x}",
+		},
+		{
+			name:        "tab stops and placeholders",
+			snippet:     NewSnippet().Text("func ").Placeholder(1, "name").Text("(").TabStop(2).Text(") {\n\t").TabStop(0).Text("\n}"),
+			want:        "func ${1:name}($2) {\n\t$0\n\\}",
+			wantPlain:   "func name() {\n\t\n}",
+			wantSnippet: true,
+		},
+		{
+			name:        "escaping",
+			snippet:     NewSnippet().Text(`a\b$c}`).Placeholder(1, `$x}`),
+			want:        `a\\b\$c\}${1:\$x\}}`,
+			wantPlain:   `a\b$c}$x}`,
+			wantSnippet: true,
+		},
+		{
+			name:        "choices",
+			snippet:     NewSnippet().Text("order ").Choice(1, "asc", "a,b|c"),
+			want:        `order ${1|asc,a\,b\|c|}`,
+			wantPlain:   "order asc",
+			wantSnippet: true,
+		},
+		{
+			name:        "variables",
+			snippet:     NewSnippet().Variable("TM_SELECTED_TEXT", "").Text(" ").Variable("CURRENT_YEAR", "2024"),
+			want:        "${TM_SELECTED_TEXT} ${CURRENT_YEAR:2024}",
+			wantPlain:   " 2024",
+			wantSnippet: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.snippet.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+			if got := tt.snippet.PlainText(); got != tt.wantPlain {
+				t.Errorf("PlainText() = %q, want %q", got, tt.wantPlain)
+			}
+			if got := tt.snippet.IsSnippet(); got != tt.wantSnippet {
+				t.Errorf("IsSnippet() = %v, want %v", got, tt.wantSnippet)
+			}
+		})
+	}
+}
+
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		snippet string
+		want    string
+	}{
+		{"SELECT $1 FROM ${2:table}", "SELECT  FROM table"},
+		{"${1:outer ${2:inner}}", "outer inner"},
+		{"price: $ 5", "price: $ 5"},
+		{"${TM_FILENAME/(.*)/${1:/upcase}/}x", "x"},
+		{"$1name", "name"},
+	}
+	for _, tt := range tests {
+		if got := PlainText(tt.snippet); got != tt.want {
+			t.Errorf("PlainText(%q) = %q, want %q", tt.snippet, got, tt.want)
+		}
+	}
+}
+
+func TestHasTabStops(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"SELECT $1 FROM ${2:table}", true},
+		{"${1|a,b|}", true},
+		{"fn($0)", true},
+		{"$name = 1", false},
+		{"${TM_FILENAME}", false},
+		{"price: $ 5", false},
+		{`\$1`, false},
+	}
+	for _, tt := range tests {
+		if got := HasTabStops(tt.text); got != tt.want {
+			t.Errorf("HasTabStops(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
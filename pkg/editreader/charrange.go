@@ -46,8 +46,12 @@ func (r CharRange) LowerString() string {
 	return sb.String()
 }
 
+// ProtocolRange returns the range as a protocol range, it is nil when the range is unset or ends before it
+// starts.
 func (r CharRange) ProtocolRange() *protocol.Range {
-
+	if !r.Valid() {
+		return nil
+	}
 	return &protocol.Range{
 		Start: protocol.Position{
 			Line:      protocol.UInteger(r.Start.point.Line),
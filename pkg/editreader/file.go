@@ -264,3 +264,19 @@ func (f *File) Tail() *Char {
 func (f *File) GoTo(c *Char) {
 	f.read.GoTo(c)
 }
+
+// CharBefore returns the character before the cursor at the point, it is the empty head when the point is at
+// the start of the file.
+func (f *File) CharBefore(p location.Point) *Char {
+	f.m.Lock()
+	defer f.m.Unlock()
+
+	before := f.head
+	for c := f.head.n; !c.IsEmpty(); c = c.n {
+		if !c.point.Before(p) {
+			break
+		}
+		before = c
+	}
+	return before
+}
@@ -0,0 +1,166 @@
+// Package folding has the folding ranges of a document and derives them from its text for languages that do
+// not know their structure.
+package folding
+
+import (
+	"sort"
+	"strings"
+
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// tabWidth is the columns a tab indents, only the order of indentations matters for folding.
+const tabWidth = 4
+
+// Range is a region of lines that can be folded, the start line stays visible.
+type Range struct {
+	StartLine int
+	EndLine   int
+	// Kind is protocol.FoldingRangeKindComment, Imports or Region, or empty for code.
+	Kind protocol.FoldingRangeKind
+}
+
+func (r Range) Protocol() protocol.FoldingRange {
+	fr := protocol.FoldingRange{
+		StartLine: protocol.UInteger(r.StartLine),
+		EndLine:   protocol.UInteger(r.EndLine),
+	}
+	if r.Kind != "" {
+		kind := string(r.Kind)
+		fr.Kind = &kind
+	}
+	return fr
+}
+
+// brackets maps the closing brackets to their opening bracket.
+var brackets = map[rune]rune{')': '(', ']': '[', '}': '{'}
+
+// Brackets returns a range for every bracket pair that spans lines. The line of the closing bracket stays
+// visible when the bracket starts it. Brackets in strings and comments are not told apart.
+func Brackets(text string) []Range {
+	type open struct {
+		bracket rune
+		line    int
+	}
+	var (
+		ranges []Range
+		stack  []open
+		line   int
+		// blank is set while only whitespace is before the cursor on the line
+		blank = true
+	)
+	for _, r := range text {
+		switch r {
+		case '\n':
+			line++
+			blank = true
+			continue
+		case '(', '[', '{':
+			stack = append(stack, open{bracket: r, line: line})
+		case ')', ']', '}':
+			// an unmatched closing bracket closes nothing, opening brackets without one are dropped
+			for i := len(stack) - 1; i >= 0; i-- {
+				if stack[i].bracket != brackets[r] {
+					continue
+				}
+				end := line
+				if blank {
+					end--
+				}
+				if end > stack[i].line {
+					ranges = append(ranges, Range{StartLine: stack[i].line, EndLine: end})
+				}
+				stack = stack[:i]
+				break
+			}
+		}
+		if r != ' ' && r != '\t' && r != '\r' {
+			blank = false
+		}
+	}
+	return ranges
+}
+
+// Indentation returns a range for every line that is followed by lines indented deeper, blank lines at the end
+// of the range are left out.
+func Indentation(text string) []Range {
+	type indented struct {
+		line   int
+		indent int
+	}
+	var (
+		ranges []Range
+		stack  []indented
+		// last is the last line that is not blank
+		last = -1
+	)
+	closeTo := func(indent int) {
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if last > top.line {
+				ranges = append(ranges, Range{StartLine: top.line, EndLine: last})
+			}
+		}
+	}
+
+	for i, l := range strings.Split(text, "\n") {
+		indent, blank := indentation(l)
+		if blank {
+			continue
+		}
+		closeTo(indent)
+		stack = append(stack, indented{line: i, indent: indent})
+		last = i
+	}
+	closeTo(0)
+	return ranges
+}
+
+func indentation(line string) (int, bool) {
+	width := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			width++
+		case '\t':
+			width += tabWidth - width%tabWidth
+		case '\r':
+		default:
+			return width, false
+		}
+	}
+	return width, true
+}
+
+// Fallback returns the folding ranges of the bracket pairs of the text, and of its indentation for the lines
+// no bracket pair starts on. There is a range per start line, sorted by it.
+func Fallback(text string) []Range {
+	return Merge(Brackets(text), Indentation(text))
+}
+
+// Merge keeps the largest range of each start line, ranges of the first slices are taken over the ranges of
+// later slices that start on the same line.
+func Merge(sets ...[]Range) []Range {
+	byLine := make(map[int]Range)
+	for _, set := range sets {
+		largest := make(map[int]Range)
+		for _, r := range set {
+			if existing, ok := largest[r.StartLine]; !ok || r.EndLine > existing.EndLine {
+				largest[r.StartLine] = r
+			}
+		}
+		for line, r := range largest {
+			if _, ok := byLine[line]; !ok {
+				byLine[line] = r
+			}
+		}
+	}
+
+	ranges := make([]Range, 0, len(byLine))
+	for _, r := range byLine {
+		ranges = append(ranges, r)
+	}
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].StartLine < ranges[j].StartLine })
+	return ranges
+}
@@ -0,0 +1,30 @@
+package folding
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBrackets(t *testing.T) {
+	text := "func f() {\n\tcall(a,\n\t\tb)\n\tx := []int{1, 2}\n}\n)"
+	want := []Range{{StartLine: 1, EndLine: 2}, {StartLine: 0, EndLine: 3}}
+	if got := Brackets(text); !reflect.DeepEqual(got, want) {
+		t.Errorf("Brackets() = %v, want %v", got, want)
+	}
+}
+
+func TestIndentation(t *testing.T) {
+	text := "a:\n  b:\n    c\n\n  d\n\ne\n  f\n"
+	want := []Range{{StartLine: 1, EndLine: 2}, {StartLine: 0, EndLine: 4}, {StartLine: 6, EndLine: 7}}
+	if got := Indentation(text); !reflect.DeepEqual(got, want) {
+		t.Errorf("Indentation() = %v, want %v", got, want)
+	}
+}
+
+func TestFallback(t *testing.T) {
+	text := "if x {\n  y\n  z\n}\nlist:\n  - a\n  - b\n"
+	want := []Range{{StartLine: 0, EndLine: 2}, {StartLine: 4, EndLine: 6}}
+	if got := Fallback(text); !reflect.DeepEqual(got, want) {
+		t.Errorf("Fallback() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,122 @@
+package format
+
+// Doc is a document for the pretty printer. A language builds a Doc from its parse and Render lays it out: the
+// groups that fit on the rest of the line are printed flat, the others break their lines.
+type Doc interface {
+	// hard reports whether the document contains a hard line, the groups around it always break
+	hard() bool
+}
+
+type text string
+
+func (text) hard() bool { return false }
+
+type line struct {
+	// soft lines print nothing when flat, other lines a space
+	soft bool
+	// hard lines always break
+	isHard bool
+}
+
+func (l line) hard() bool { return l.isHard }
+
+type concat struct {
+	docs   []Doc
+	isHard bool
+}
+
+func (c concat) hard() bool { return c.isHard }
+
+type group struct {
+	doc Doc
+}
+
+func (g group) hard() bool { return g.doc.hard() }
+
+type indent struct {
+	doc Doc
+}
+
+func (i indent) hard() bool { return i.doc.hard() }
+
+type fill struct {
+	docs   []Doc
+	isHard bool
+}
+
+func (f fill) hard() bool { return f.isHard }
+
+type ifBreak struct {
+	broken Doc
+	flat   Doc
+}
+
+func (i ifBreak) hard() bool { return i.broken.hard() || i.flat.hard() }
+
+var (
+	// Line is a space, or a newline when its group breaks.
+	Line Doc = line{}
+	// SoftLine is nothing, or a newline when its group breaks.
+	SoftLine Doc = line{soft: true}
+	// HardLine is always a newline, the groups around it break.
+	HardLine Doc = line{isHard: true}
+)
+
+// Text is text printed as it is, it must not contain newlines.
+func Text(s string) Doc {
+	return text(s)
+}
+
+// Concat prints the documents one after the other.
+func Concat(docs ...Doc) Doc {
+	if len(docs) == 1 {
+		return docs[0]
+	}
+	return concat{docs: docs, isHard: anyHard(docs)}
+}
+
+// Group prints the documents flat when they fit on the rest of the line, otherwise the lines of the group
+// break. Groups inside a broken group are measured on their own.
+func Group(docs ...Doc) Doc {
+	return group{doc: Concat(docs...)}
+}
+
+// Indent indents the lines that break inside the documents by one level.
+func Indent(docs ...Doc) Doc {
+	return indent{doc: Concat(docs...)}
+}
+
+// Fill prints as many documents on a line as fit. The documents alternate between content and separators,
+// a separator breaks when the content after it does not fit on the line.
+func Fill(docs ...Doc) Doc {
+	return fill{docs: docs, isHard: anyHard(docs)}
+}
+
+// IfBreak prints broken when the group it is in breaks and flat when it does not, flat may be nil.
+func IfBreak(broken Doc, flat Doc) Doc {
+	if flat == nil {
+		flat = Concat()
+	}
+	return ifBreak{broken: broken, flat: flat}
+}
+
+// Join puts sep between the documents.
+func Join(sep Doc, docs ...Doc) Doc {
+	joined := make([]Doc, 0, 2*len(docs))
+	for i, d := range docs {
+		if i > 0 {
+			joined = append(joined, sep)
+		}
+		joined = append(joined, d)
+	}
+	return Concat(joined...)
+}
+
+func anyHard(docs []Doc) bool {
+	for _, d := range docs {
+		if d.hard() {
+			return true
+		}
+	}
+	return false
+}
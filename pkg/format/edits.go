@@ -0,0 +1,185 @@
+package format
+
+import (
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// Edits returns the text edits that turn before into after. The lines are diffed so only the changed lines
+// are edited, and each edit is trimmed to the characters that differ. before starts at start in the document,
+// the edits are in document positions.
+func Edits(start protocol.Position, before, after string) []protocol.TextEdit {
+	if before == after {
+		return []protocol.TextEdit{}
+	}
+	a, b := splitLines(before), splitLines(after)
+
+	edits := []protocol.TextEdit{}
+	// pos is the position of the start of line line of before
+	pos, line := start, 0
+	for _, h := range diffLines(a, b) {
+		for ; line < h.a0; line++ {
+			pos = advance(pos, a[line])
+		}
+		removed := strings.Join(a[h.a0:h.a1], "")
+		inserted := strings.Join(b[h.b0:h.b1], "")
+		edits = append(edits, trimmedEdit(pos, removed, inserted))
+	}
+	return edits
+}
+
+// trimmedEdit returns the edit replacing removed at pos with inserted, without the text both start or end with.
+func trimmedEdit(pos protocol.Position, removed string, inserted string) protocol.TextEdit {
+	prefix := 0
+	for prefix < len(removed) && prefix < len(inserted) && removed[prefix] == inserted[prefix] {
+		prefix++
+	}
+	for prefix > 0 && prefix < len(removed) && !utf8.RuneStart(removed[prefix]) {
+		prefix--
+	}
+	suffix := 0
+	for suffix < len(removed)-prefix && suffix < len(inserted)-prefix && removed[len(removed)-1-suffix] == inserted[len(inserted)-1-suffix] {
+		suffix++
+	}
+	for suffix > 0 && !utf8.RuneStart(removed[len(removed)-suffix]) {
+		suffix--
+	}
+
+	editStart := advance(pos, removed[:prefix])
+	return protocol.TextEdit{
+		Range: protocol.Range{
+			Start: editStart,
+			End:   advance(editStart, removed[prefix:len(removed)-suffix]),
+		},
+		NewText: inserted[prefix : len(inserted)-suffix],
+	}
+}
+
+// advance returns the position after text when it starts at pos, characters are counted in UTF-16.
+func advance(pos protocol.Position, text string) protocol.Position {
+	for _, r := range text {
+		if r == '\n' {
+			pos.Line++
+			pos.Character = 0
+			continue
+		}
+		pos.Character += protocol.UInteger(utf16.RuneLen(r))
+	}
+	return pos
+}
+
+// splitLines splits the text after each newline, the last line has no newline when the text does not end
+// with one.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// hunk replaces the lines a0 to a1 of the old text with the lines b0 to b1 of the new text.
+type hunk struct {
+	a0, a1 int
+	b0, b1 int
+}
+
+// diffLines returns the hunks of the shortest edit script from a to b, found with the Myers algorithm.
+func diffLines(a, b []string) []hunk {
+	// lines that are the same at the start and the end are left out of the search
+	pre := 0
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	suf := 0
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+
+	var hunks []hunk
+	x, y := pre, pre
+	for _, m := range matches(a[pre:len(a)-suf], b[pre:len(b)-suf]) {
+		if m.x+pre > x || m.y+pre > y {
+			hunks = append(hunks, hunk{a0: x, a1: m.x + pre, b0: y, b1: m.y + pre})
+		}
+		x, y = m.x+pre+1, m.y+pre+1
+	}
+	if x < len(a)-suf || y < len(b)-suf {
+		hunks = append(hunks, hunk{a0: x, a1: len(a) - suf, b0: y, b1: len(b) - suf})
+	}
+	return hunks
+}
+
+type match struct {
+	x, y int
+}
+
+// matches returns the lines a and b have in common in the shortest edit script, in order.
+func matches(a, b []string) []match {
+	n, m := len(a), len(b)
+	maxD := n + m
+	if maxD == 0 {
+		return nil
+	}
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	// trace holds the diagonals -d-1 to d+1 of v before round d, the path is read back from it
+	var trace [][]int
+
+	for d := 0; d <= maxD; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d-1:offset+d+2]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || k != d && v[offset+k-1] < v[offset+k+1] {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, n, m)
+			}
+		}
+	}
+	return nil
+}
+
+func backtrack(trace [][]int, x int, y int) []match {
+	var reversed []match
+	for d := len(trace) - 1; d >= 0; d-- {
+		// diagonal k of round d is at k+d+1
+		v := trace[d]
+		k := x - y
+
+		var prevX, prevY int
+		if d > 0 {
+			prevK := k - 1
+			if k == -d || k != d && v[k-1+d+1] < v[k+1+d+1] {
+				prevK = k + 1
+			}
+			prevX = v[prevK+d+1]
+			prevY = prevX - prevK
+		}
+		// the diagonal to x, y is made of lines that match
+		for x > prevX && y > prevY {
+			x--
+			y--
+			reversed = append(reversed, match{x: x, y: y})
+		}
+		x, y = prevX, prevY
+	}
+
+	result := make([]match, len(reversed))
+	for i, m := range reversed {
+		result[len(reversed)-1-i] = m
+	}
+	return result
+}
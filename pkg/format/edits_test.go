@@ -0,0 +1,86 @@
+package format
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// apply applies the edits to the text, the edits must not overlap.
+func apply(t *testing.T, text string, edits []protocol.TextEdit) string {
+	t.Helper()
+	for i := len(edits) - 1; i >= 0; i-- {
+		start, end := edits[i].Range.IndexesIn(text)
+		text = text[:start] + edits[i].NewText + text[end:]
+	}
+	return text
+}
+
+func TestEdits(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		edits  int
+	}{
+		{name: "same", before: "a\nb\n", after: "a\nb\n", edits: 0},
+		{name: "indent", before: "if {\nx\n}\n", after: "if {\n\tx\n}\n", edits: 1},
+		{name: "two lines", before: "a\n b\nc\n d\ne\n", after: "a\nb\nc\nd\ne\n", edits: 2},
+		{name: "insert line", before: "a\nc\n", after: "a\nb\nc\n", edits: 1},
+		{name: "delete line", before: "a\nb\nc\n", after: "a\nc\n", edits: 1},
+		{name: "final newline", before: "a\nb", after: "a\nb\n", edits: 1},
+		{name: "empty", before: "", after: "a\n", edits: 1},
+		{name: "utf16", before: "x = \"😀\"  \ny\n", after: "x = \"😀\"\ny\n", edits: 1},
+		{name: "crlf", before: "a  \r\nb\r\n", after: "a\r\nb\r\n", edits: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			edits := Edits(protocol.Position{}, tt.before, tt.after)
+			if len(edits) != tt.edits {
+				t.Errorf("Edits() = %d edits %v, want %d", len(edits), edits, tt.edits)
+			}
+			if got := apply(t, tt.before, edits); got != tt.after {
+				t.Errorf("applied edits = %q, want %q", got, tt.after)
+			}
+		})
+	}
+}
+
+func TestEditsMinimal(t *testing.T) {
+	edits := Edits(protocol.Position{}, "func f() {\n  return\n}\n", "func f() {\n\treturn\n}\n")
+	want := protocol.TextEdit{
+		Range:   protocol.Range{Start: protocol.Position{Line: 1}, End: protocol.Position{Line: 1, Character: 2}},
+		NewText: "\t",
+	}
+	if len(edits) != 1 || edits[0] != want {
+		t.Errorf("Edits() = %v, want %v", edits, want)
+	}
+}
+
+func TestEditsStart(t *testing.T) {
+	edits := Edits(protocol.Position{Line: 3, Character: 4}, "a  b\nc", "a b\nc")
+	want := protocol.Range{Start: protocol.Position{Line: 3, Character: 6}, End: protocol.Position{Line: 3, Character: 7}}
+	if len(edits) != 1 || edits[0].Range != want {
+		t.Errorf("Edits() = %v, want range %v", edits, want)
+	}
+}
+
+func TestEditsRandom(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	words := []string{"a\n", "b\n", "c\n", "  d\n", "\n"}
+	text := func() string {
+		var sb strings.Builder
+		for i := r.Intn(30); i > 0; i-- {
+			sb.WriteString(words[r.Intn(len(words))])
+		}
+		return sb.String()
+	}
+	for i := 0; i < 500; i++ {
+		before, after := text(), text()
+		if got := apply(t, before, Edits(protocol.Position{}, before, after)); got != after {
+			t.Fatalf("applied edits to %q = %q, want %q", before, got, after)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Package format turns the output of a language formatter into the edits sent to the client.
+package format
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kjbreil/glsp/pkg/location"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// DefaultTabSize is the tab size used when the client does not send one.
+const DefaultTabSize = 4
+
+// Options are the formatting options sent by the client.
+type Options struct {
+	TabSize      int
+	InsertSpaces bool
+	// TrimTrailingWhitespace removes the spaces and tabs at the end of lines.
+	TrimTrailingWhitespace bool
+	// InsertFinalNewline ends the document with a newline, TrimFinalNewlines removes the empty lines after it.
+	InsertFinalNewline bool
+	TrimFinalNewlines  bool
+}
+
+// ParseOptions reads the options the client sent, options it did not send are off.
+func ParseOptions(options protocol.FormattingOptions) Options {
+	o := Options{
+		TabSize:                DefaultTabSize,
+		InsertSpaces:           boolOption(options, protocol.FormattingOptionInsertSpaces),
+		TrimTrailingWhitespace: boolOption(options, protocol.FormattingOptionTrimTrailingWhitespace),
+		InsertFinalNewline:     boolOption(options, protocol.FormattingOptionInsertFinalNewline),
+		TrimFinalNewlines:      boolOption(options, protocol.FormattingOptionTrimFinalNewlines),
+	}
+	// numbers decoded from JSON are float64, options built in Go may use any integer type
+	switch v := options[protocol.FormattingOptionTabSize].(type) {
+	case float64:
+		o.TabSize = int(v)
+	case int:
+		o.TabSize = v
+	case protocol.UInteger:
+		o.TabSize = int(v)
+	case protocol.Integer:
+		o.TabSize = int(v)
+	}
+	if o.TabSize <= 0 {
+		o.TabSize = DefaultTabSize
+	}
+	return o
+}
+
+func boolOption(options protocol.FormattingOptions, key string) bool {
+	v, ok := options[key].(bool)
+	return ok && v
+}
+
+// Indent returns the indentation for depth levels, a tab per level or TabSize spaces when InsertSpaces is set.
+func (o Options) Indent(depth int) string {
+	if o.InsertSpaces {
+		return strings.Repeat(" ", depth*o.TabSize)
+	}
+	return strings.Repeat("\t", depth)
+}
+
+// Apply applies the options to the formatted text of a whole document. The indentation of the lines is
+// converted to tabs or spaces, trailing whitespace and the newlines at the end are handled as the options ask.
+// The lines in the protected ranges of the text, like multi-line strings and raw blocks, keep their
+// indentation and trailing whitespace.
+func Apply(text string, options protocol.FormattingOptions, protected []location.Range) string {
+	o := ParseOptions(options)
+	text = applyLines(text, o, protected)
+
+	newline := "\n"
+	if strings.Contains(text, "\r\n") {
+		newline = "\r\n"
+	}
+	if o.TrimFinalNewlines {
+		trimmed := strings.TrimRight(text, "\r\n")
+		if len(trimmed) < len(text) {
+			text = trimmed + newline
+		}
+	}
+	if o.InsertFinalNewline && text != "" && !strings.HasSuffix(text, "\n") {
+		text += newline
+	}
+	return text
+}
+
+// ApplyLines applies the options that work line by line, it is used for the text of a range. The protected
+// ranges are positions in text, not in the document.
+func ApplyLines(text string, options protocol.FormattingOptions, protected []location.Range) string {
+	return applyLines(text, ParseOptions(options), protected)
+}
+
+func applyLines(text string, o Options, protected []location.Range) string {
+	lines := strings.SplitAfter(text, "\n")
+	for i, line := range lines {
+		content := strings.TrimSuffix(line, "\n")
+		ending := line[len(content):]
+		if strings.HasSuffix(content, "\r") {
+			content = content[:len(content)-1]
+			ending = "\r" + ending
+		}
+
+		// a line that starts inside a protected range has its indentation as content
+		if !inside(location.Point{Line: i}, protected, false) {
+			content = reindent(content, o)
+		}
+		if o.TrimTrailingWhitespace {
+			trimmed := strings.TrimRight(content, " \t")
+			end := location.Point{Line: i, Column: utf8.RuneCountInString(trimmed)}
+			if !inside(end, protected, true) {
+				content = trimmed
+			}
+		}
+		lines[i] = content + ending
+	}
+	return strings.Join(lines, "")
+}
+
+// inside reports whether the point is in one of the ranges, after their start or at it when atStart is set,
+// and before their end.
+func inside(p location.Point, ranges []location.Range, atStart bool) bool {
+	for _, r := range ranges {
+		if (r.Start.Before(p) || atStart && r.Start == p) && p.Before(r.End) {
+			return true
+		}
+	}
+	return false
+}
+
+// reindent rewrites the leading whitespace of the line with the tabs or spaces of the options, the width of the
+// indentation stays the same.
+func reindent(line string, o Options) string {
+	content := strings.TrimLeft(line, " \t")
+	leading := line[:len(line)-len(content)]
+	if leading == "" || content == "" {
+		return line
+	}
+
+	width := 0
+	for _, r := range leading {
+		if r == '\t' {
+			width += o.TabSize - width%o.TabSize
+		} else {
+			width++
+		}
+	}
+
+	var want string
+	if o.InsertSpaces {
+		want = strings.Repeat(" ", width)
+	} else {
+		want = strings.Repeat("\t", width/o.TabSize) + strings.Repeat(" ", width%o.TabSize)
+	}
+	if want == leading {
+		return line
+	}
+	return want + content
+}
@@ -0,0 +1,66 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/kjbreil/glsp/pkg/location"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		options   protocol.FormattingOptions
+		protected []location.Range
+		want      string
+	}{
+		{
+			name:    "spaces",
+			text:    "a\n\tb\n\t\tc\n",
+			options: protocol.FormattingOptions{"tabSize": float64(2), "insertSpaces": true},
+			want:    "a\n  b\n    c\n",
+		},
+		{
+			name:    "tabs",
+			text:    "a\n    b\n      c\n",
+			options: protocol.FormattingOptions{"tabSize": float64(4), "insertSpaces": false},
+			want:    "a\n\tb\n\t  c\n",
+		},
+		{
+			name:    "trailing whitespace",
+			text:    "a  \n\t\nb\t\r\n",
+			options: protocol.FormattingOptions{"insertSpaces": false, "trimTrailingWhitespace": true},
+			want:    "a\n\nb\r\n",
+		},
+		{
+			name:    "final newline",
+			text:    "a\nb",
+			options: protocol.FormattingOptions{"insertFinalNewline": true},
+			want:    "a\nb\n",
+		},
+		{
+			name:    "final newlines",
+			text:    "a\n\n\n",
+			options: protocol.FormattingOptions{"trimFinalNewlines": true},
+			want:    "a\n",
+		},
+		{
+			// the string from the end of line 0 to line 2 keeps its indentation and trailing spaces
+			name:    "protected",
+			text:    "x = `  \n    y  \n  `  \n    z  \n",
+			options: protocol.FormattingOptions{"insertSpaces": false, "trimTrailingWhitespace": true},
+			protected: []location.Range{
+				{Start: location.Point{Line: 0, Column: 4}, End: location.Point{Line: 2, Column: 3}},
+			},
+			want: "x = `  \n    y  \n  `\n\tz\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Apply(tt.text, tt.options, tt.protected); got != tt.want {
+				t.Errorf("Apply() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
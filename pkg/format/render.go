@@ -0,0 +1,192 @@
+package format
+
+import (
+	"unicode/utf8"
+
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// DefaultWidth is the width documents are laid out in when none is given.
+const DefaultWidth = 80
+
+type mode int
+
+const (
+	modeBreak mode = iota
+	modeFlat
+)
+
+// command is a document waiting to be printed at an indentation level in a mode.
+type command struct {
+	level int
+	mode  mode
+	doc   Doc
+}
+
+// Render lays the document out in width columns, DefaultWidth when width is 0. The indentation uses tabs or
+// spaces as the options ask, a tab counts as TabSize columns.
+func Render(d Doc, width int, options protocol.FormattingOptions) string {
+	if width <= 0 {
+		width = DefaultWidth
+	}
+	r := renderer{options: ParseOptions(options), width: width}
+	r.render(d)
+	return string(r.out)
+}
+
+type renderer struct {
+	options Options
+	width   int
+	out     []byte
+	// column is the column the next text is printed at
+	column int
+}
+
+func (r *renderer) render(d Doc) {
+	commands := []command{{level: 0, mode: modeBreak, doc: d}}
+	for len(commands) > 0 {
+		c := commands[len(commands)-1]
+		commands = commands[:len(commands)-1]
+
+		switch d := c.doc.(type) {
+		case text:
+			r.out = append(r.out, d...)
+			r.column += utf8.RuneCountInString(string(d))
+		case concat:
+			for i := len(d.docs) - 1; i >= 0; i-- {
+				commands = append(commands, command{level: c.level, mode: c.mode, doc: d.docs[i]})
+			}
+		case indent:
+			commands = append(commands, command{level: c.level + 1, mode: c.mode, doc: d.doc})
+		case group:
+			flat := command{level: c.level, mode: modeFlat, doc: d.doc}
+			if !d.hard() && (c.mode == modeFlat || r.fits(flat, commands, false)) {
+				commands = append(commands, flat)
+			} else {
+				commands = append(commands, command{level: c.level, mode: modeBreak, doc: d.doc})
+			}
+		case ifBreak:
+			if c.mode == modeBreak {
+				commands = append(commands, command{level: c.level, mode: c.mode, doc: d.broken})
+			} else {
+				commands = append(commands, command{level: c.level, mode: c.mode, doc: d.flat})
+			}
+		case line:
+			if c.mode == modeFlat && !d.isHard {
+				if !d.soft {
+					r.out = append(r.out, ' ')
+					r.column++
+				}
+				continue
+			}
+			r.newline(c.level)
+		case fill:
+			commands = r.fill(c, d, commands)
+		}
+	}
+}
+
+// fill pushes the commands for the next content and separator of the fill, and the fill of the rest.
+func (r *renderer) fill(c command, f fill, commands []command) []command {
+	if len(f.docs) == 0 {
+		return commands
+	}
+	content := f.docs[0]
+	contentFlat := command{level: c.level, mode: modeFlat, doc: content}
+	contentBreak := command{level: c.level, mode: modeBreak, doc: content}
+	contentFits := !content.hard() && r.fits(contentFlat, nil, true)
+	if len(f.docs) == 1 {
+		if contentFits {
+			return append(commands, contentFlat)
+		}
+		return append(commands, contentBreak)
+	}
+
+	separator := f.docs[1]
+	separatorFlat := command{level: c.level, mode: modeFlat, doc: separator}
+	separatorBreak := command{level: c.level, mode: modeBreak, doc: separator}
+	if len(f.docs) == 2 {
+		if contentFits {
+			return append(commands, separatorFlat, contentFlat)
+		}
+		return append(commands, separatorBreak, contentBreak)
+	}
+
+	rest := command{level: c.level, mode: c.mode, doc: fill{docs: f.docs[2:], isHard: anyHard(f.docs[2:])}}
+	// the separator stays flat when the next content fits after it on the line
+	pair := Concat(content, separator, f.docs[2])
+	pairFits := !pair.hard() && r.fits(command{level: c.level, mode: modeFlat, doc: pair}, nil, true)
+	switch {
+	case pairFits:
+		return append(commands, rest, separatorFlat, contentFlat)
+	case contentFits:
+		return append(commands, rest, separatorBreak, contentFlat)
+	default:
+		return append(commands, rest, separatorBreak, contentBreak)
+	}
+}
+
+// fits reports whether next fits on the rest of the line. The commands after it are measured up to the first
+// line that breaks unless mustBeFlat is set.
+func (r *renderer) fits(next command, rest []command, mustBeFlat bool) bool {
+	remaining := r.width - r.column
+	stack := []command{next}
+	for remaining >= 0 {
+		if len(stack) == 0 {
+			if mustBeFlat || len(rest) == 0 {
+				return true
+			}
+			stack = append(stack, rest[len(rest)-1])
+			rest = rest[:len(rest)-1]
+			continue
+		}
+		c := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		switch d := c.doc.(type) {
+		case text:
+			remaining -= utf8.RuneCountInString(string(d))
+		case concat:
+			for i := len(d.docs) - 1; i >= 0; i-- {
+				stack = append(stack, command{level: c.level, mode: c.mode, doc: d.docs[i]})
+			}
+		case fill:
+			for i := len(d.docs) - 1; i >= 0; i-- {
+				stack = append(stack, command{level: c.level, mode: c.mode, doc: d.docs[i]})
+			}
+		case indent:
+			stack = append(stack, command{level: c.level + 1, mode: c.mode, doc: d.doc})
+		case group:
+			m := c.mode
+			if d.hard() {
+				m = modeBreak
+			}
+			stack = append(stack, command{level: c.level, mode: m, doc: d.doc})
+		case ifBreak:
+			if c.mode == modeBreak {
+				stack = append(stack, command{level: c.level, mode: c.mode, doc: d.broken})
+			} else {
+				stack = append(stack, command{level: c.level, mode: c.mode, doc: d.flat})
+			}
+		case line:
+			if c.mode == modeBreak || d.isHard {
+				return true
+			}
+			if !d.soft {
+				remaining--
+			}
+		}
+	}
+	return false
+}
+
+// newline ends the line without its trailing whitespace and indents the next one to level.
+func (r *renderer) newline(level int) {
+	end := len(r.out)
+	for end > 0 && (r.out[end-1] == ' ' || r.out[end-1] == '\t') {
+		end--
+	}
+	r.out = append(r.out[:end], '\n')
+	r.out = append(r.out, r.options.Indent(level)...)
+	r.column = level * r.options.TabSize
+}
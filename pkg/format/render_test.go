@@ -0,0 +1,121 @@
+package format
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func call(name string, args ...Doc) Doc {
+	return Group(
+		Text(name+"("),
+		Indent(SoftLine, Join(Concat(Text(","), Line), args...), IfBreak(Text(","), nil)),
+		SoftLine,
+		Text(")"),
+	)
+}
+
+func block(header string, statements ...Doc) Doc {
+	return Concat(Text(header+" {"), Indent(HardLine, Join(HardLine, statements...)), HardLine, Text("}"))
+}
+
+func words(s string) Doc {
+	var docs []Doc
+	for i, w := range strings.Fields(s) {
+		if i > 0 {
+			docs = append(docs, Line)
+		}
+		docs = append(docs, Text(w))
+	}
+	return Fill(docs...)
+}
+
+var spaces = protocol.FormattingOptions{"tabSize": float64(2), "insertSpaces": true}
+
+// TestRenderGolden renders each document at every width and compares the layouts with testdata/<name>.golden,
+// go test -update rewrites the files.
+func TestRenderGolden(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     Doc
+		widths  []int
+		options protocol.FormattingOptions
+	}{
+		{
+			name:   "call",
+			doc:    call("print", Text("first"), Text("second"), Text("third")),
+			widths: []int{80, 20, 10},
+		},
+		{
+			name: "nested",
+			doc: call("outer",
+				call("inner", Text("alpha"), Text("beta")),
+				call("other", Text("gamma"), Text("delta"), Text("epsilon")),
+			),
+			widths:  []int{80, 40, 24, 12},
+			options: spaces,
+		},
+		{
+			name: "block",
+			doc: block("func main()",
+				call("print", Text("\"hello\""), Text("name")),
+				block("if ok", call("exit", Text("0"))),
+			),
+			widths: []int{80, 16},
+		},
+		{
+			name:    "fill",
+			doc:     Concat(Text("// "), words("the quick brown fox jumps over the lazy dog and keeps running until the end of the line")),
+			widths:  []int{80, 40, 20},
+			options: spaces,
+		},
+		{
+			name: "list",
+			doc: Group(Text("["), Indent(SoftLine, Fill(
+				Text("1,"), Line, Text("2,"), Line, Text("3,"), Line, Text("4,"), Line, Text("5,"), Line,
+				Text("6,"), Line, Text("7,"), Line, Text("8,"), Line, Text("9,"), Line, Text("10"),
+			)), SoftLine, Text("]")),
+			widths:  []int{80, 16, 8},
+			options: spaces,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sb strings.Builder
+			for _, width := range tt.widths {
+				fmt.Fprintf(&sb, "-- width %d --\n%s\n", width, Render(tt.doc, width, tt.options))
+			}
+			got := sb.String()
+
+			golden := filepath.Join("testdata", tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Errorf("Render() layouts differ from %s\ngot:\n%s\nwant:\n%s", golden, got, want)
+			}
+		})
+	}
+}
+
+func TestRenderTabs(t *testing.T) {
+	doc := block("a", call("b", Text("cccccccc"), Text("dddddddd")))
+	got := Render(doc, 16, protocol.FormattingOptions{"tabSize": float64(4), "insertSpaces": false})
+	want := "a {\n\tb(\n\t\tcccccccc,\n\t\tdddddddd,\n\t)\n}"
+	if got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
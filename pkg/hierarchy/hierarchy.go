@@ -0,0 +1,116 @@
+// Package hierarchy has the callables and types of a file with the calls between them and the types they
+// extend, the server links them by name across files into call and type hierarchies.
+package hierarchy
+
+import (
+	"github.com/kjbreil/glsp/pkg/editreader"
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+	protocol317 "github.com/kjbreil/glsp/protocol_3_17"
+)
+
+// Item is a callable or a type of a hierarchy.
+type Item struct {
+	Name   string
+	Kind   protocol.SymbolKind
+	Detail string
+	// Range covers the whole item, Selection only its name.
+	Range      editreader.CharRange
+	Selection  editreader.CharRange
+	Deprecated bool
+}
+
+// Callable is a function or method of a file with the calls made in its body.
+type Callable struct {
+	Item
+	Calls []Call
+}
+
+// Call is the call of a callable by name, Ranges are where it is called in the body of the caller.
+type Call struct {
+	Name   string
+	Ranges []editreader.CharRange
+}
+
+// Type is a type of a file with the names of the types it extends or implements.
+type Type struct {
+	Item
+	Supertypes []string
+}
+
+// Ranges returns the range of the item and of its name, the name falls back to the whole item when it is not
+// set. ok is false when the item has no range.
+func (i *Item) Ranges() (r protocol.Range, selection protocol.Range, ok bool) {
+	full := i.Range.ProtocolRange()
+	if full == nil {
+		return protocol.Range{}, protocol.Range{}, false
+	}
+	if name := i.Selection.ProtocolRange(); name != nil {
+		return *full, *name, true
+	}
+	return *full, *full, true
+}
+
+// CallItem returns the item as a call hierarchy item of the document, data is sent with it so the calls of
+// the item can be found. ok is false when the item has no range.
+func (i *Item) CallItem(u uri.DocumentURI, data any) (item protocol.CallHierarchyItem, ok bool) {
+	r, selection, ok := i.Ranges()
+	if !ok {
+		return item, false
+	}
+	return protocol.CallHierarchyItem{
+		Name:           i.Name,
+		Kind:           i.Kind,
+		Tags:           i.tags(),
+		Detail:         i.detail(),
+		URI:            u,
+		Range:          r,
+		SelectionRange: selection,
+		Data:           data,
+	}, true
+}
+
+// TypeItem returns the item as a type hierarchy item of the document, data is sent with it so the
+// supertypes and subtypes of the item can be found. ok is false when the item has no range.
+func (i *Item) TypeItem(u uri.DocumentURI, data any) (item protocol317.TypeHierarchyItem, ok bool) {
+	r, selection, ok := i.Ranges()
+	if !ok {
+		return item, false
+	}
+	return protocol317.TypeHierarchyItem{
+		Name:           i.Name,
+		Kind:           i.Kind,
+		Tags:           i.tags(),
+		Detail:         i.detail(),
+		URI:            u,
+		Range:          r,
+		SelectionRange: selection,
+		Data:           data,
+	}, true
+}
+
+func (i *Item) tags() []protocol.SymbolTag {
+	if i.Deprecated {
+		return []protocol.SymbolTag{protocol.SymbolTagDeprecated}
+	}
+	return nil
+}
+
+func (i *Item) detail() *string {
+	if i.Detail == "" {
+		return nil
+	}
+	detail := i.Detail
+	return &detail
+}
+
+// ProtocolRanges returns the ranges of the call sites, call sites without a range are left out.
+func (c *Call) ProtocolRanges() []protocol.Range {
+	ranges := make([]protocol.Range, 0, len(c.Ranges))
+	for _, r := range c.Ranges {
+		if pr := r.ProtocolRange(); pr != nil {
+			ranges = append(ranges, *pr)
+		}
+	}
+	return ranges
+}
@@ -0,0 +1,61 @@
+package hierarchy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kjbreil/glsp/pkg/editreader"
+	"github.com/kjbreil/glsp/pkg/location"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+func TestItemRanges(t *testing.T) {
+	f, err := editreader.New(strings.NewReader("func main() {}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	full := editreader.CharRange{
+		Start: f.CharBefore(location.Point{Line: 0, Column: 1}),
+		End:   f.CharBefore(location.Point{Line: 0, Column: 14}),
+	}
+	name := editreader.CharRange{
+		Start: f.CharBefore(location.Point{Line: 0, Column: 6}),
+		End:   f.CharBefore(location.Point{Line: 0, Column: 9}),
+	}
+
+	item := Item{Name: "main", Range: full, Selection: name}
+	got, ok := item.CallItem("file:///a.go", nil)
+	if !ok || got.Range != *full.ProtocolRange() || got.SelectionRange != *name.ProtocolRange() {
+		t.Errorf("CallItem() = %v, %v", got, ok)
+	}
+
+	// the selection falls back to the whole item
+	item.Selection = editreader.CharRange{}
+	if got, ok := item.TypeItem("file:///a.go", nil); !ok || got.SelectionRange != got.Range {
+		t.Errorf("TypeItem() without a selection = %v, %v", got, ok)
+	}
+
+	item.Range = editreader.CharRange{}
+	if _, ok := item.CallItem("file:///a.go", nil); ok {
+		t.Errorf("CallItem() without a range ok = true")
+	}
+	if _, ok := item.TypeItem("file:///a.go", nil); ok {
+		t.Errorf("TypeItem() without a range ok = true")
+	}
+}
+
+func TestCallProtocolRanges(t *testing.T) {
+	f, err := editreader.New(strings.NewReader("a()"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	call := Call{Name: "a", Ranges: []editreader.CharRange{
+		{},
+		{Start: f.CharBefore(location.Point{Line: 0, Column: 1}), End: f.CharBefore(location.Point{Line: 0, Column: 1})},
+	}}
+	got := call.ProtocolRanges()
+	want := protocol.Range{End: protocol.Position{Character: 1}}
+	if len(got) != 1 || got[0] != want {
+		t.Errorf("ProtocolRanges() = %v, want [%v]", got, want)
+	}
+}
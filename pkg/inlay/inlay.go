@@ -0,0 +1,96 @@
+// Package inlay has the inlay hints of a document, text the editor shows inline with the code like the
+// inferred type of a variable or the name of a parameter.
+package inlay
+
+import (
+	"github.com/kjbreil/glsp/pkg/commands"
+	"github.com/kjbreil/glsp/pkg/editreader"
+	"github.com/kjbreil/glsp/pkg/location"
+	"github.com/kjbreil/glsp/pkg/markdown"
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+	protocol317 "github.com/kjbreil/glsp/protocol_3_17"
+)
+
+// Hint is shown at a point of a document, its label is Label or, when it has parts, the parts one after the
+// other.
+type Hint struct {
+	Point location.Point
+	Label string
+	Parts []LabelPart
+	// Kind is protocol317.InlayHintKindType or Parameter, or 0 when the hint is neither.
+	Kind    protocol317.InlayHintKind
+	Tooltip markdown.Markdown
+	// PaddingLeft and PaddingRight put space between the hint and the text before or after it.
+	PaddingLeft  bool
+	PaddingRight bool
+}
+
+// LabelPart is a part of the label of a hint that has its own tooltip, and can be clicked to go to the code
+// it stands for or to run a command.
+type LabelPart struct {
+	Value   string
+	Tooltip markdown.Markdown
+	// URI and CharRange are the location the part stands for, like the definition of a type, they are
+	// left empty when it has none.
+	URI       uri.DocumentURI
+	CharRange editreader.CharRange
+	Command   *commands.Reference
+}
+
+func (h *Hint) Protocol() protocol317.InlayHint {
+	hint := protocol317.InlayHint{
+		Position: protocol.Position{
+			Line:      protocol.UInteger(h.Point.Line),
+			Character: protocol.UInteger(h.Point.Column),
+		},
+		Label:   h.Label,
+		Tooltip: tooltip(h.Tooltip),
+	}
+	if len(h.Parts) > 0 {
+		parts := make([]protocol317.InlayHintLabelPart, len(h.Parts))
+		for i := range h.Parts {
+			parts[i] = h.Parts[i].Protocol()
+		}
+		hint.Label = parts
+	}
+	if h.Kind != 0 {
+		hint.Kind = ptr(h.Kind)
+	}
+	if h.PaddingLeft {
+		hint.PaddingLeft = ptr(true)
+	}
+	if h.PaddingRight {
+		hint.PaddingRight = ptr(true)
+	}
+	return hint
+}
+
+func (p *LabelPart) Protocol() protocol317.InlayHintLabelPart {
+	part := protocol317.InlayHintLabelPart{
+		Value:   p.Value,
+		Tooltip: tooltip(p.Tooltip),
+	}
+	if r := p.CharRange.ProtocolRange(); p.URI != "" && r != nil {
+		part.Location = &protocol.Location{URI: p.URI, Range: *r}
+	}
+	if p.Command != nil {
+		part.Command = p.Command.Protocol()
+	}
+	return part
+}
+
+// tooltip renders the markdown, it is nil when the markdown is empty.
+func tooltip(md markdown.Markdown) any {
+	if md == (markdown.Markdown{}) {
+		return nil
+	}
+	return protocol.MarkupContent{
+		Kind:  protocol.MarkupKindMarkdown,
+		Value: md.String(),
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
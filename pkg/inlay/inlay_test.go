@@ -0,0 +1,60 @@
+package inlay
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/kjbreil/glsp/pkg/commands"
+	"github.com/kjbreil/glsp/pkg/location"
+	"github.com/kjbreil/glsp/pkg/markdown"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+	protocol317 "github.com/kjbreil/glsp/protocol_3_17"
+)
+
+func TestHintProtocol(t *testing.T) {
+	show := commands.Command{Name: "show"}.Reference("Show", 1)
+	h := Hint{
+		Point: location.Point{Line: 2, Column: 7},
+		Parts: []LabelPart{
+			{Value: ": "},
+			{Value: "int", Tooltip: markdown.Markdown{Main: "integer"}, Command: &show},
+		},
+		Kind:         protocol317.InlayHintKindType,
+		PaddingRight: true,
+	}
+
+	data, err := json.Marshal(h.Protocol())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got protocol317.InlayHint
+	if err = json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Position != (protocol.Position{Line: 2, Character: 7}) {
+		t.Errorf("position = %v", got.Position)
+	}
+	if got.PaddingLeft != nil || got.PaddingRight == nil || !*got.PaddingRight {
+		t.Errorf("padding = %v, %v, want only right", got.PaddingLeft, got.PaddingRight)
+	}
+	parts, ok := got.Label.([]protocol317.InlayHintLabelPart)
+	if !ok || len(parts) != 2 {
+		t.Fatalf("label = %#v, want 2 parts", got.Label)
+	}
+	if parts[0].Tooltip != nil {
+		t.Errorf("tooltip of the first part = %v, want none", parts[0].Tooltip)
+	}
+	if _, ok := parts[1].Tooltip.(protocol.MarkupContent); !ok {
+		t.Errorf("tooltip of the second part = %#v, want markup", parts[1].Tooltip)
+	}
+	if want := (&protocol.Command{Title: "Show", Command: "show", Arguments: []any{1.0}}); !reflect.DeepEqual(parts[1].Command, want) {
+		t.Errorf("command = %v, want %v", parts[1].Command, want)
+	}
+
+	h = Hint{Label: "x:"}
+	if label := h.Protocol().Label; label != "x:" {
+		t.Errorf("label = %v, want x:", label)
+	}
+}
@@ -0,0 +1,34 @@
+package language
+
+import (
+	"github.com/kjbreil/glsp/pkg/codelens"
+	"github.com/kjbreil/glsp/pkg/inlay"
+	"github.com/kjbreil/glsp/pkg/location"
+)
+
+// CodeLensProvider is implemented by a File that shows code lenses. The commands of the lenses are the
+// commands the language registers with Commands, lenses whose command is expensive to work out can leave it
+// nil for a CodeLensResolver of the language to fill in.
+type CodeLensProvider interface {
+	CodeLenses() []codelens.Lens
+}
+
+// CodeLensResolver is implemented by a LanguageDef that fills in the command of a code lens of one of its
+// files when the lens becomes visible.
+type CodeLensResolver interface {
+	ResolveCodeLens(file File, lens codelens.Lens) codelens.Lens
+}
+
+// InlayHintProvider is implemented by a File that shows inlay hints, InlayHints returns the hints in the
+// range that is visible in the editor.
+type InlayHintProvider interface {
+	InlayHints(r location.Range) []inlay.Hint
+}
+
+// Refresher is implemented by a LanguageDef whose code lenses or inlay hints depend on more than the text
+// of a file, like other files or the configuration. After a document or the configuration changes the
+// server asks the client to request the lenses or hints of all documents again.
+type Refresher interface {
+	RefreshCodeLenses() bool
+	RefreshInlayHints() bool
+}
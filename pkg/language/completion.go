@@ -0,0 +1,73 @@
+package language
+
+import (
+	"github.com/kjbreil/glsp/pkg/completion"
+	"github.com/kjbreil/glsp/pkg/editreader"
+	"github.com/kjbreil/glsp/pkg/location"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// CompletionContext describes the spot completion was requested at.
+type CompletionContext struct {
+	// Point is the position of the cursor.
+	Point       location.Point
+	TriggerKind protocol.CompletionTriggerKind
+	// TriggerCharacter is the character that triggered completion, it is empty unless TriggerKind is
+	// protocol.CompletionTriggerKindTriggerCharacter.
+	TriggerCharacter string
+	// Prefix is the part of the word at the cursor that is before it.
+	Prefix string
+}
+
+// FileCompleter is implemented by a File that completes the text at a position. The static Completions of
+// the language are only used for files that implement neither FileCompleter nor LanguageCompleter.
+type FileCompleter interface {
+	Complete(c CompletionContext) completion.Completions
+}
+
+// LanguageCompleter is implemented by a LanguageDef that completes the text at a position of one of its files.
+// A file that implements FileCompleter is asked first.
+type LanguageCompleter interface {
+	Complete(file File, c CompletionContext) completion.Completions
+}
+
+// ReaderFile is implemented by a File that keeps its text in an editreader.File, the word at the cursor is
+// read from it instead of from the text of the file.
+type ReaderFile interface {
+	Reader() *editreader.File
+}
+
+// CompletionResolver is implemented by a LanguageDef that declares Resolve in its CompletionOptions and fills
+// in a completion when the client selects it, for example with documentation that is expensive to look up.
+type CompletionResolver interface {
+	ResolveCompletion(c completion.Completion) completion.Completion
+}
+
+// CompletionOptions are the characters and features a language declares for completion and signature help.
+type CompletionOptions struct {
+	// TriggerCharacters start completion when typed.
+	TriggerCharacters []string
+	// CommitCharacters accept the selected completion item when typed.
+	CommitCharacters []string
+	// SignatureTriggerCharacters start signature help when typed, SignatureRetriggerCharacters only while it
+	// is showing.
+	SignatureTriggerCharacters   []string
+	SignatureRetriggerCharacters []string
+	// Resolve is set when the language fills in completion items lazily on completionItem/resolve, the
+	// documentation of its items is then only rendered for the item the client selects.
+	Resolve bool
+}
+
+// CompletionOptionsProvider is implemented by a LanguageDef that declares CompletionOptions, languages that
+// do not implement it complete without trigger characters.
+type CompletionOptionsProvider interface {
+	CompletionOptions() CompletionOptions
+}
+
+// CompletionOptionsOf returns the completion options the language declares.
+func CompletionOptionsOf(def LanguageDef) CompletionOptions {
+	if provider, ok := def.(CompletionOptionsProvider); ok {
+		return provider.CompletionOptions()
+	}
+	return CompletionOptions{}
+}
@@ -0,0 +1,19 @@
+package language
+
+import (
+	"github.com/kjbreil/glsp/pkg/editreader"
+	"github.com/kjbreil/glsp/pkg/folding"
+	"github.com/kjbreil/glsp/pkg/location"
+)
+
+// FoldingRangeProvider is implemented by a File that knows the regions of its structure, like blocks,
+// comments and imports. The files of other languages fold on their brackets and indentation.
+type FoldingRangeProvider interface {
+	FoldingRanges() []folding.Range
+}
+
+// SelectionRangeProvider is implemented by a File that expands a selection along its structure.
+// SelectionRanges returns the ranges around the point from the innermost out, each contains the one before.
+type SelectionRangeProvider interface {
+	SelectionRanges(p location.Point) []editreader.CharRange
+}
@@ -0,0 +1,42 @@
+package language
+
+import (
+	"github.com/kjbreil/glsp/pkg/format"
+	"github.com/kjbreil/glsp/pkg/location"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// Formatter is implemented by a LanguageDef that formats its files, Format returns the formatted text of the
+// whole file. The server sends the client only the edits between the file and the result, after applying the
+// options of the client to it.
+type Formatter interface {
+	Format(file File, options protocol.FormattingOptions) (string, error)
+}
+
+// DocFormatter is implemented by a LanguageDef that builds a format.Doc of a file instead of formatting its
+// text, the server renders the document within width columns, format.DefaultWidth when width is 0. A
+// Formatter of the language is used before it.
+type DocFormatter interface {
+	FormatDoc(file File) (doc format.Doc, width int, err error)
+}
+
+// RangeFormatter is implemented by a LanguageDef that formats part of a file, FormatRange returns the text
+// that replaces the range.
+type RangeFormatter interface {
+	FormatRange(file File, r location.Range, options protocol.FormattingOptions) (string, error)
+}
+
+// OnTypeFormatter is implemented by a LanguageDef that formats while the user types. FormatOnType is called
+// when one of the trigger characters is typed at the point and returns the formatted text of the whole file.
+type OnTypeFormatter interface {
+	FormatTriggerCharacters() []string
+	FormatOnType(file File, p location.Point, ch string, options protocol.FormattingOptions) (string, error)
+}
+
+// FormatProtector is implemented by a LanguageDef whose formatted text has ranges the options of the client
+// must not change, like multi-line strings and raw blocks whose indentation and trailing whitespace are part
+// of their content. Protected returns those ranges of the formatted text, for range formatting the positions
+// are in the text that replaces the range.
+type FormatProtector interface {
+	Protected(formatted string) []location.Range
+}
@@ -0,0 +1,23 @@
+package language
+
+import (
+	"github.com/kjbreil/glsp/pkg/hierarchy"
+	"github.com/kjbreil/glsp/pkg/location"
+)
+
+// CallHierarchyProvider is implemented by a File that has functions or methods. CallableAt returns the name
+// of the callable defined or called at the point, empty when there is none, and Callables returns the
+// callables of the file with the calls they make. Calls are linked to callables by name across the open and
+// the workspace files of the language, callables of the same file are taken over the ones of other files.
+type CallHierarchyProvider interface {
+	CallableAt(p location.Point) string
+	Callables() []hierarchy.Callable
+}
+
+// TypeHierarchyProvider is implemented by a File that has types that extend or implement other types. TypeAt
+// returns the name of the type defined or used at the point, empty when there is none, and Types returns the
+// types of the file with the names of their supertypes, which are linked like the calls of callables.
+type TypeHierarchyProvider interface {
+	TypeAt(p location.Point) string
+	Types() []hierarchy.Type
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/kjbreil/glsp/pkg/location"
 	"github.com/kjbreil/glsp/pkg/problems"
 	"github.com/kjbreil/glsp/pkg/semantic"
+	"github.com/kjbreil/glsp/pkg/symbols"
 	"github.com/kjbreil/glsp/pkg/uri"
 	protocol "github.com/kjbreil/glsp/protocol_3_16"
 	"io"
@@ -47,17 +48,71 @@ func (l *Language) DeleteUri(u uri.DocumentURI) {
 	delete(l.files, u)
 }
 
+// Files calls yield for every open file of the language.
+func (l *Language) Files(yield func(File) bool) {
+	l.mu.Lock()
+	files := make([]File, 0, len(l.files))
+	for _, f := range l.files {
+		files = append(files, f)
+	}
+	l.mu.Unlock()
+
+	for _, f := range files {
+		if !yield(f) {
+			return
+		}
+	}
+}
+
+// Def returns the definition the language was added with.
+func (l *Language) Def() LanguageDef {
+	return l.def
+}
+
 func (l *Language) On() *LanguageOn {
 	return l.def.On()
 }
 
+// File is a document of a language. The features of the server beyond diagnostics come from the optional
+// interfaces a File or its LanguageDef implements, the server only offers the features some language has.
 type File interface {
-	Hover(point location.Point) *hover.Hover
 	Replace(text string, r *location.Range)
 	Problems() *problems.Problems
 	Uri() uri.DocumentURI
 	Path() string
 	Reset(s string)
+}
+
+// TextFile is implemented by a File that returns its content with every edit applied. The server keeps a copy
+// of the text of a file that does not.
+type TextFile interface {
+	Text() string
+}
+
+// HoverProvider is implemented by a File that shows information about the code at a point.
+type HoverProvider interface {
+	Hover(point location.Point) *hover.Hover
+}
+
+// SemanticsProvider is implemented by a File that highlights its tokens. Links are detected in its comment
+// and string tokens.
+type SemanticsProvider interface {
 	Semantics() *semantic.Semantics
+}
+
+// CodeActionProvider is implemented by a File that offers fixes and refactorings for a range.
+type CodeActionProvider interface {
 	CodeActions(r *location.Range) ([]protocol.CodeAction, error)
 }
+
+// SymbolProvider is implemented by a File that records the definitions and references of symbols while it
+// parses, it enables definition, declaration, references and document highlight.
+type SymbolProvider interface {
+	Symbols() *symbols.Symbols
+}
+
+// DocumentSymbolProvider is implemented by a File that has an outline, it enables document symbols and
+// workspace symbols.
+type DocumentSymbolProvider interface {
+	DocumentSymbols() []symbols.Symbol
+}
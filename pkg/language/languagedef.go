@@ -24,6 +24,14 @@ type LanguageDef interface {
 	On() *LanguageOn
 }
 
+// FileTyper is implemented by a LanguageDef that declares the type of the files it parses. FileType returns a
+// zero value of that type, like (*MyFile)(nil), and the server checks which optional File interfaces it
+// implements to tell which features the language has. A language without it only has the features its
+// LanguageDef provides, like completion, and none of the features of its files.
+type FileTyper interface {
+	FileType() File
+}
+
 type LanguageOn struct {
 	SaveFn func(f File) error
 }
@@ -135,9 +135,18 @@ func (l *Languages) CommandsExecute(context *glsp.Context, params *protocol.Exec
 	return l.commands.Execute(context, params)
 }
 
+// Languages calls yield with each language until it returns false. Languages added while it runs are not
+// visited.
 func (l *Languages) Languages(yield func(LanguageDef) bool) {
+	l.mu.Lock()
+	defs := make([]LanguageDef, 0, len(l.languages))
 	for _, lang := range l.languages {
-		if !yield(lang.def) {
+		defs = append(defs, lang.def)
+	}
+	l.mu.Unlock()
+
+	for _, def := range defs {
+		if !yield(def) {
 			return
 		}
 	}
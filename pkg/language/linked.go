@@ -0,0 +1,14 @@
+package language
+
+import (
+	"github.com/kjbreil/glsp/pkg/editreader"
+	"github.com/kjbreil/glsp/pkg/location"
+)
+
+// LinkedEditingRangeProvider is implemented by a File with names that are edited together, like the open
+// and close tags of an element. LinkedEditingRanges returns the ranges that change with the one at the
+// point, including it, and the regular expression of the text that is valid in them, or an empty pattern for
+// the word pattern of the client. It returns no ranges when nothing at the point is linked.
+type LinkedEditingRangeProvider interface {
+	LinkedEditingRanges(p location.Point) (ranges []editreader.CharRange, wordPattern string)
+}
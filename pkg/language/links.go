@@ -0,0 +1,31 @@
+package language
+
+import (
+	"github.com/kjbreil/glsp/pkg/color"
+	"github.com/kjbreil/glsp/pkg/links"
+)
+
+// DocumentLinkProvider is implemented by a File that knows its links, like imports that open another file.
+// Files that do not implement it link the URLs and file paths in their comments and strings, as told apart
+// by their semantic tokens when they are a SemanticsProvider.
+type DocumentLinkProvider interface {
+	DocumentLinks() []links.Link
+}
+
+// DocumentLinkResolver is implemented by a LanguageDef that fills in the target of a link of one of its
+// files when the user follows it, links without a target are dropped for languages that do not implement it.
+type DocumentLinkResolver interface {
+	ResolveDocumentLink(file File, link links.Link) links.Link
+}
+
+// ColorProvider is implemented by a File that knows the colors written in it.
+type ColorProvider interface {
+	Colors() []color.Color
+}
+
+// ColorDetector is implemented by a LanguageDef whose files write colors as hex and rgb literals, like
+// #ff8800 and rgb(255, 136, 0). DetectColors reports whether the literals are looked for in files that do
+// not implement ColorProvider.
+type ColorDetector interface {
+	DetectColors() bool
+}
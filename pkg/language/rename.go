@@ -0,0 +1,25 @@
+package language
+
+import (
+	"github.com/kjbreil/glsp/pkg/editreader"
+	"github.com/kjbreil/glsp/pkg/location"
+)
+
+// RenameProvider is implemented by a File that decides what can be renamed. PrepareRename returns the range
+// of the name at the point and the text the client shows for it, ok is false when nothing at the point can be
+// renamed. Files that do not implement it rename the symbols of their SymbolProvider.
+type RenameProvider interface {
+	PrepareRename(p location.Point) (r editreader.CharRange, placeholder string, ok bool)
+}
+
+// RenameValidator is implemented by a LanguageDef that checks a new name before a rename, the error is shown
+// to the user when the name is not valid.
+type RenameValidator interface {
+	ValidName(name string) error
+}
+
+// FileExtensions is implemented by a LanguageDef whose files are looked up in the workspace, a rename then
+// also edits the files of the language that are not open. The extensions include the dot, like ".go".
+type FileExtensions interface {
+	Extensions() []string
+}
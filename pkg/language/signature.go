@@ -0,0 +1,29 @@
+package language
+
+import (
+	"github.com/kjbreil/glsp/pkg/location"
+	"github.com/kjbreil/glsp/pkg/signature"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// SignatureContext describes the spot signature help was requested at.
+type SignatureContext struct {
+	// Point is the position of the cursor.
+	Point       location.Point
+	TriggerKind protocol.SignatureHelpTriggerKind
+	// TriggerCharacter is the character that triggered signature help, it is empty unless TriggerKind is
+	// protocol.SignatureHelpTriggerKindTriggerCharacter.
+	TriggerCharacter string
+	// IsRetrigger is set when signature help was already showing, ActiveSignature is then the label of the
+	// signature that was active.
+	IsRetrigger     bool
+	ActiveSignature string
+}
+
+// SignatureHelpProvider is implemented by a File that shows the signatures of the call at a position, it
+// returns nil when the position is not in a call. On a retrigger the signature that was active stays active
+// when it is still in the help. The trigger characters are the SignatureTriggerCharacters and
+// SignatureRetriggerCharacters of the CompletionOptions of the language.
+type SignatureHelpProvider interface {
+	SignatureHelp(c SignatureContext) *signature.Help
+}
@@ -0,0 +1,134 @@
+// Package links has the links of a document, ranges that open a URL or a file when they are followed, and
+// finds the URLs and file paths written in plain text.
+package links
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/kjbreil/glsp/pkg/location"
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// Link is a range of a document that opens Target when it is followed. A link without a target is resolved
+// when the user follows it.
+type Link struct {
+	Range   location.Range
+	Target  uri.DocumentURI
+	Tooltip string
+}
+
+// Protocol returns the document link, data is sent with it so the link can be resolved.
+func (l *Link) Protocol(data any) protocol.DocumentLink {
+	link := protocol.DocumentLink{
+		Range: l.Range.ProtocolRange(),
+		Data:  data,
+	}
+	if l.Target != "" {
+		target := l.Target
+		link.Target = &target
+	}
+	if l.Tooltip != "" {
+		tooltip := l.Tooltip
+		link.Tooltip = &tooltip
+	}
+	return link
+}
+
+var (
+	urlPattern = regexp.MustCompile("\\b(?:https?|ftp|file)://[^\\s\"'<>`]+")
+	// pathPattern matches paths that start with ./, ../ or /, and relative paths with a directory that end in
+	// a file extension, other words with a slash like and/or are not paths
+	pathPattern = regexp.MustCompile(`(?:\.\.?/|/)[\w.-]+(?:/[\w.-]+)*|[\w-]+(?:/[\w.-]+)*/[\w-]+\.\w+`)
+)
+
+// Detect returns the links to the URLs and file paths in the regions of the text, or in all of it when
+// regions is nil. Relative paths are resolved against the document at base.
+func Detect(text string, base uri.DocumentURI, regions []location.Range) []Link {
+	baseURL, err := url.Parse(string(base))
+	if err != nil {
+		baseURL = nil
+	}
+
+	var links []Link
+	for line, content := range strings.Split(text, "\n") {
+		urls := urlPattern.FindAllStringIndex(content, -1)
+		for _, m := range urls {
+			raw := trimTrailing(content[m[0]:m[1]])
+			if link, ok := newLink(content, line, m[0], raw, uri.DocumentURI(raw), regions); ok {
+				links = append(links, link)
+			}
+		}
+
+		for _, m := range pathPattern.FindAllStringIndex(content, -1) {
+			// a match that continues a word or another path is not a path of its own
+			if baseURL == nil || overlaps(urls, m) || m[0] > 0 && inPath(content[m[0]-1]) {
+				continue
+			}
+			raw := trimTrailing(content[m[0]:m[1]])
+			target := baseURL.ResolveReference(&url.URL{Path: raw})
+			if link, ok := newLink(content, line, m[0], raw, uri.DocumentURI(target.String()), regions); ok {
+				links = append(links, link)
+			}
+		}
+	}
+	return links
+}
+
+func newLink(content string, line, start int, raw string, target uri.DocumentURI, regions []location.Range) (Link, bool) {
+	if raw == "" {
+		return Link{}, false
+	}
+	r := location.Range{
+		Start: location.Point{Line: line, Column: utf8.RuneCountInString(content[:start])},
+		End:   location.Point{Line: line, Column: utf8.RuneCountInString(content[:start+len(raw)])},
+	}
+	if regions != nil && !within(r, regions) {
+		return Link{}, false
+	}
+	return Link{Range: r, Target: target}, true
+}
+
+// trimTrailing removes the punctuation that ends a sentence after a link and closing brackets that are not
+// part of it.
+func trimTrailing(s string) string {
+	for s != "" {
+		last := s[len(s)-1]
+		switch {
+		case strings.IndexByte(".,;:!?'\"", last) >= 0:
+		case last == ')' && strings.Count(s, "(") < strings.Count(s, ")"):
+		case last == ']' && strings.Count(s, "[") < strings.Count(s, "]"):
+		case last == '}' && strings.Count(s, "{") < strings.Count(s, "}"):
+		default:
+			return s
+		}
+		s = s[:len(s)-1]
+	}
+	return s
+}
+
+func overlaps(matches [][]int, m []int) bool {
+	for _, o := range matches {
+		if m[0] < o[1] && o[0] < m[1] {
+			return true
+		}
+	}
+	return false
+}
+
+func within(r location.Range, regions []location.Range) bool {
+	for _, region := range regions {
+		if !r.Start.Before(region.Start) && !region.End.Before(r.End) {
+			return true
+		}
+	}
+	return false
+}
+
+func inPath(b byte) bool {
+	return b == '_' || '0' <= b && b <= '9' || 'a' <= b && b <= 'z' || 'A' <= b && b <= 'Z' ||
+		strings.IndexByte(`\:/.-~`, b) >= 0
+}
@@ -0,0 +1,50 @@
+package links
+
+import (
+	"testing"
+
+	"github.com/kjbreil/glsp/pkg/location"
+	"github.com/kjbreil/glsp/pkg/uri"
+)
+
+func TestDetect(t *testing.T) {
+	text := "// see https://example.com/a_(b).\n" +
+		"// and/or ../docs/readme.md, lib/util.go\n" +
+		`x = "/etc/hosts" // ./ignored`
+
+	regions := []location.Range{
+		{Start: location.Point{Line: 0, Column: 0}, End: location.Point{Line: 1, Column: 40}},
+		{Start: location.Point{Line: 2, Column: 4}, End: location.Point{Line: 2, Column: 16}},
+	}
+	got := Detect(text, uri.DocumentURI("file:///src/app/main.go"), regions)
+
+	want := []struct {
+		line, start, end int
+		target           uri.DocumentURI
+	}{
+		{0, 7, 32, "https://example.com/a_(b)"},
+		{1, 10, 27, "file:///src/docs/readme.md"},
+		{1, 29, 40, "file:///src/app/lib/util.go"},
+		{2, 5, 15, "file:///etc/hosts"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Detect() = %v, want %d links", got, len(want))
+	}
+	for i, w := range want {
+		r := location.Range{Start: location.Point{Line: w.line, Column: w.start}, End: location.Point{Line: w.line, Column: w.end}}
+		if got[i].Range != r || got[i].Target != w.target {
+			t.Errorf("link %d = %v %s, want %v %s", i, got[i].Range, got[i].Target, r, w.target)
+		}
+	}
+}
+
+func TestDetectNonASCII(t *testing.T) {
+	// columns count runes like the regions of the semantic tokens, the emoji is one column
+	text := "// 😀 https://a.io"
+	regions := []location.Range{{Start: location.Point{Line: 0, Column: 5}, End: location.Point{Line: 0, Column: 17}}}
+
+	got := Detect(text, "", regions)
+	if len(got) != 1 || got[0].Range != regions[0] {
+		t.Errorf("Detect() = %v, want a link at %v", got, regions[0])
+	}
+}
@@ -0,0 +1,98 @@
+package registration
+
+import protocol "github.com/kjbreil/glsp/protocol_3_16"
+
+// MethodSemanticTokens is the method used to register semantic tokens, the individual
+// textDocument/semanticTokens/* methods cannot be registered on their own.
+const MethodSemanticTokens = protocol.Method("textDocument/semanticTokens")
+
+// dynamicRegistration looks up the dynamicRegistration flag of the client capability belonging to method.
+func dynamicRegistration(c *protocol.ClientCapabilities, method string) bool {
+	if c == nil {
+		return false
+	}
+
+	if c.Workspace != nil {
+		w := c.Workspace
+		switch method {
+		case protocol.MethodWorkspaceDidChangeConfiguration:
+			return w.DidChangeConfiguration != nil && isTrue(w.DidChangeConfiguration.DynamicRegistration)
+		case protocol.MethodWorkspaceDidChangeWatchedFiles:
+			return w.DidChangeWatchedFiles != nil && isTrue(w.DidChangeWatchedFiles.DynamicRegistration)
+		case protocol.MethodWorkspaceSymbol:
+			return w.Symbol != nil && isTrue(w.Symbol.DynamicRegistration)
+		case protocol.MethodWorkspaceExecuteCommand:
+			return w.ExecuteCommand != nil && isTrue(w.ExecuteCommand.DynamicRegistration)
+		case protocol.MethodWorkspaceDidCreateFiles, protocol.MethodWorkspaceWillCreateFiles,
+			protocol.MethodWorkspaceDidRenameFiles, protocol.MethodWorkspaceWillRenameFiles,
+			protocol.MethodWorkspaceDidDeleteFiles, protocol.MethodWorkspaceWillDeleteFiles:
+			return w.FileOperations != nil && isTrue(w.FileOperations.DynamicRegistration)
+		}
+	}
+
+	t := c.TextDocument
+	if t == nil {
+		return false
+	}
+
+	switch method {
+	case protocol.MethodTextDocumentDidOpen, protocol.MethodTextDocumentDidChange, protocol.MethodTextDocumentDidClose,
+		protocol.MethodTextDocumentDidSave, protocol.MethodTextDocumentWillSave, protocol.MethodTextDocumentWillSaveWaitUntil:
+		return t.Synchronization != nil && isTrue(t.Synchronization.DynamicRegistration)
+	case protocol.MethodTextDocumentCompletion:
+		return t.Completion != nil && isTrue(t.Completion.DynamicRegistration)
+	case protocol.MethodTextDocumentHover:
+		return t.Hover != nil && isTrue(t.Hover.DynamicRegistration)
+	case protocol.MethodTextDocumentSignatureHelp:
+		return t.SignatureHelp != nil && isTrue(t.SignatureHelp.DynamicRegistration)
+	case protocol.MethodTextDocumentDeclaration:
+		return t.Declaration != nil && isTrue(t.Declaration.DynamicRegistration)
+	case protocol.MethodTextDocumentDefinition:
+		return t.Definition != nil && isTrue(t.Definition.DynamicRegistration)
+	case protocol.MethodTextDocumentTypeDefinition:
+		return t.TypeDefinition != nil && isTrue(t.TypeDefinition.DynamicRegistration)
+	case protocol.MethodTextDocumentImplementation:
+		return t.Implementation != nil && isTrue(t.Implementation.DynamicRegistration)
+	case protocol.MethodTextDocumentReferences:
+		return t.References != nil && isTrue(t.References.DynamicRegistration)
+	case protocol.MethodTextDocumentDocumentHighlight:
+		return t.DocumentHighlight != nil && isTrue(t.DocumentHighlight.DynamicRegistration)
+	case protocol.MethodTextDocumentDocumentSymbol:
+		return t.DocumentSymbol != nil && isTrue(t.DocumentSymbol.DynamicRegistration)
+	case protocol.MethodTextDocumentCodeAction:
+		return t.CodeAction != nil && isTrue(t.CodeAction.DynamicRegistration)
+	case protocol.MethodTextDocumentCodeLens:
+		return t.CodeLens != nil && isTrue(t.CodeLens.DynamicRegistration)
+	case protocol.MethodTextDocumentDocumentLink:
+		return t.DocumentLink != nil && isTrue(t.DocumentLink.DynamicRegistration)
+	case protocol.MethodTextDocumentColor:
+		return t.ColorProvider != nil && isTrue(t.ColorProvider.DynamicRegistration)
+	case protocol.MethodTextDocumentFormatting:
+		return t.Formatting != nil && isTrue(t.Formatting.DynamicRegistration)
+	case protocol.MethodTextDocumentRangeFormatting:
+		return t.RangeFormatting != nil && isTrue(t.RangeFormatting.DynamicRegistration)
+	case protocol.MethodTextDocumentOnTypeFormatting:
+		return t.OnTypeFormatting != nil && isTrue(t.OnTypeFormatting.DynamicRegistration)
+	case protocol.MethodTextDocumentRename:
+		return t.Rename != nil && isTrue(t.Rename.DynamicRegistration)
+	case protocol.MethodTextDocumentFoldingRange:
+		return t.FoldingRange != nil && isTrue(t.FoldingRange.DynamicRegistration)
+	case protocol.MethodTextDocumentSelectionRange:
+		return t.SelectionRange != nil && isTrue(t.SelectionRange.DynamicRegistration)
+	case protocol.MethodTextDocumentLinkedEditingRange:
+		return t.LinkedEditingRange != nil && isTrue(t.LinkedEditingRange.DynamicRegistration)
+	case protocol.MethodTextDocumentPrepareCallHierarchy:
+		return t.CallHierarchy != nil && isTrue(t.CallHierarchy.DynamicRegistration)
+	case protocol.MethodTextDocumentSemanticTokensFull, protocol.MethodTextDocumentSemanticTokensFullDelta,
+		protocol.MethodTextDocumentSemanticTokensRange, MethodSemanticTokens:
+		return t.SemanticTokens != nil && isTrue(t.SemanticTokens.DynamicRegistration)
+	case protocol.MethodTextDocumentMoniker:
+		return t.Moniker != nil && isTrue(t.Moniker.DynamicRegistration)
+	}
+
+	return false
+}
+
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
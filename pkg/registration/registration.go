@@ -0,0 +1,216 @@
+package registration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+	"github.com/sourcegraph/jsonrpc2"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrNotConnected        = errors.New("registration: no client connection")
+	ErrDynamicNotSupported = errors.New("registration: client does not support dynamic registration")
+	ErrUnknownRegistration = errors.New("registration: unknown registration id")
+)
+
+// Caller sends a request to the client and decodes the response into result.
+type Caller func(ctx context.Context, method string, params any, result any) error
+
+// Manager issues client/registerCapability and client/unregisterCapability requests and keeps track of
+// the registrations that are currently live on the client.
+type Manager struct {
+	caller       Caller
+	capabilities *protocol.ClientCapabilities
+	// dynamic holds the methods the 3.16 capabilities do not cover that the client registers dynamically
+	dynamic map[string]bool
+	live    map[string]protocol.Registration
+	next    uint64
+
+	mu sync.Mutex
+}
+
+func New() *Manager {
+	return &Manager{
+		live: make(map[string]protocol.Registration),
+		mu:   sync.Mutex{},
+	}
+}
+
+func (m *Manager) SetConn(conn *jsonrpc2.Conn) {
+	m.SetCaller(func(ctx context.Context, method string, params any, result any) error {
+		return conn.Call(ctx, method, params, result)
+	})
+}
+
+func (m *Manager) SetCaller(caller Caller) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.caller = caller
+}
+
+// SetClientCapabilities stores the capabilities the client sent in initialize, they decide which methods
+// can be registered dynamically.
+func (m *Manager) SetClientCapabilities(capabilities *protocol.ClientCapabilities) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.capabilities = capabilities
+}
+
+// SetDynamic records whether the client supports dynamic registration for a method its 3.16 capabilities do
+// not cover, like the methods added in 3.17.
+func (m *Manager) SetDynamic(method string, dynamic bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.dynamic == nil {
+		m.dynamic = make(map[string]bool)
+	}
+	m.dynamic[method] = dynamic
+}
+
+// Dynamic reports whether the client supports dynamic registration for the method. When it does not the
+// server has to advertise the capability statically in its initialize result.
+func (m *Manager) Dynamic(method string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.dynamicLocked(method)
+}
+
+func (m *Manager) dynamicLocked(method string) bool {
+	return m.dynamic[method] || dynamicRegistration(m.capabilities, method)
+}
+
+// Register registers a single method with the client using a generated id and returns that id.
+func (m *Manager) Register(ctx context.Context, method string, options any) (string, error) {
+	ids, err := m.RegisterMany(ctx, protocol.Registration{Method: method, RegisterOptions: options})
+	if err != nil {
+		return "", err
+	}
+	return ids[0], nil
+}
+
+// RegisterMany registers several methods in one request, registrations without an ID get a generated one.
+// Nothing is sent when the client does not support dynamic registration for every method.
+func (m *Manager) RegisterMany(ctx context.Context, registrations ...protocol.Registration) ([]string, error) {
+	m.mu.Lock()
+	if m.caller == nil {
+		m.mu.Unlock()
+		return nil, ErrNotConnected
+	}
+	caller := m.caller
+	ids := make([]string, len(registrations))
+	for i := range registrations {
+		if !m.dynamicLocked(registrations[i].Method) {
+			m.mu.Unlock()
+			return nil, fmt.Errorf("%w: %s", ErrDynamicNotSupported, registrations[i].Method)
+		}
+		if registrations[i].ID == "" {
+			m.next++
+			registrations[i].ID = fmt.Sprintf("glsp-%d", m.next)
+		}
+		ids[i] = registrations[i].ID
+	}
+	m.mu.Unlock()
+
+	err := caller(ctx, protocol.ServerClientRegisterCapability, protocol.RegistrationParams{
+		Registrations: registrations,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, r := range registrations {
+		m.live[r.ID] = r
+	}
+
+	return ids, nil
+}
+
+// Unregister removes registrations by the ids returned from Register.
+func (m *Manager) Unregister(ctx context.Context, ids ...string) error {
+	m.mu.Lock()
+	if m.caller == nil {
+		m.mu.Unlock()
+		return ErrNotConnected
+	}
+	caller := m.caller
+	unregistrations := make([]protocol.Unregistration, 0, len(ids))
+	for _, id := range ids {
+		r, ok := m.live[id]
+		if !ok {
+			m.mu.Unlock()
+			return fmt.Errorf("%w: %s", ErrUnknownRegistration, id)
+		}
+		unregistrations = append(unregistrations, protocol.Unregistration{ID: r.ID, Method: r.Method})
+	}
+	m.mu.Unlock()
+
+	if len(unregistrations) == 0 {
+		return nil
+	}
+
+	err := caller(ctx, protocol.ServerClientUnregisterCapability, protocol.UnregistrationParams{
+		Unregisterations: unregistrations,
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, id := range ids {
+		delete(m.live, id)
+	}
+	return nil
+}
+
+// UnregisterMethod removes every live registration for the method.
+func (m *Manager) UnregisterMethod(ctx context.Context, method string) error {
+	var ids []string
+	for _, r := range m.Live() {
+		if r.Method == method {
+			ids = append(ids, r.ID)
+		}
+	}
+	return m.Unregister(ctx, ids...)
+}
+
+// WatchFiles registers file system watchers through workspace/didChangeWatchedFiles.
+func (m *Manager) WatchFiles(ctx context.Context, watchers ...protocol.FileSystemWatcher) (string, error) {
+	return m.Register(ctx, protocol.MethodWorkspaceDidChangeWatchedFiles, protocol.DidChangeWatchedFilesRegistrationOptions{
+		Watchers: watchers,
+	})
+}
+
+// Live returns the registrations currently held by the client ordered by id.
+func (m *Manager) Live() []protocol.Registration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	registrations := make([]protocol.Registration, 0, len(m.live))
+	for _, r := range m.live {
+		registrations = append(registrations, r)
+	}
+	sort.Slice(registrations, func(i, j int) bool {
+		return registrations[i].ID < registrations[j].ID
+	})
+	return registrations
+}
+
+// IsLive reports whether the registration id is currently held by the client.
+func (m *Manager) IsLive(id string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.live[id]
+	return ok
+}
+
+// Reset forgets all live registrations, used when the connection to the client is gone.
+func (m *Manager) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.live = make(map[string]protocol.Registration)
+}
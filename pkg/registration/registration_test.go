@@ -0,0 +1,107 @@
+package registration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+type call struct {
+	method string
+	params any
+}
+
+func newTestManager(dynamic bool) (*Manager, *[]call) {
+	var calls []call
+	m := New()
+	m.SetCaller(func(ctx context.Context, method string, params any, result any) error {
+		calls = append(calls, call{method: method, params: params})
+		return nil
+	})
+	m.SetClientCapabilities(&protocol.ClientCapabilities{
+		TextDocument: &protocol.TextDocumentClientCapabilities{
+			Completion: &protocol.CompletionClientCapabilities{DynamicRegistration: &dynamic},
+		},
+	})
+	return m, &calls
+}
+
+func TestManager_Register(t *testing.T) {
+	m, calls := newTestManager(true)
+
+	id, err := m.Register(context.Background(), protocol.MethodTextDocumentCompletion, nil)
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if !m.IsLive(id) {
+		t.Errorf("Register() id %s is not live", id)
+	}
+	if len(*calls) != 1 || (*calls)[0].method != protocol.ServerClientRegisterCapability {
+		t.Fatalf("Register() calls = %v", *calls)
+	}
+
+	second, err := m.Register(context.Background(), protocol.MethodTextDocumentCompletion, nil)
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if second == id {
+		t.Errorf("Register() generated the same id twice: %s", id)
+	}
+
+	err = m.Unregister(context.Background(), id)
+	if err != nil {
+		t.Fatalf("Unregister() error = %v", err)
+	}
+	if m.IsLive(id) {
+		t.Errorf("Unregister() id %s is still live", id)
+	}
+	if len(m.Live()) != 1 {
+		t.Errorf("Live() = %v, want 1 registration", m.Live())
+	}
+	params := (*calls)[2].params.(protocol.UnregistrationParams)
+	if len(params.Unregisterations) != 1 || params.Unregisterations[0].ID != id {
+		t.Errorf("Unregister() params = %v", params)
+	}
+}
+
+func TestManager_Register_notSupported(t *testing.T) {
+	m, calls := newTestManager(false)
+
+	if m.Dynamic(protocol.MethodTextDocumentCompletion) {
+		t.Errorf("Dynamic() = true, want false")
+	}
+	_, err := m.Register(context.Background(), protocol.MethodTextDocumentCompletion, nil)
+	if !errors.Is(err, ErrDynamicNotSupported) {
+		t.Errorf("Register() error = %v, want %v", err, ErrDynamicNotSupported)
+	}
+	if len(*calls) != 0 {
+		t.Errorf("Register() sent %d requests, want none", len(*calls))
+	}
+}
+
+func TestManager_Unregister_unknown(t *testing.T) {
+	m, _ := newTestManager(true)
+
+	err := m.Unregister(context.Background(), "missing")
+	if !errors.Is(err, ErrUnknownRegistration) {
+		t.Errorf("Unregister() error = %v, want %v", err, ErrUnknownRegistration)
+	}
+}
+
+func TestManager_SetDynamic(t *testing.T) {
+	m, calls := newTestManager(false)
+	const method = "textDocument/inlayHint"
+
+	if m.Dynamic(method) {
+		t.Errorf("Dynamic(%s) = true before SetDynamic", method)
+	}
+	m.SetDynamic(method, true)
+	if !m.Dynamic(method) {
+		t.Errorf("Dynamic(%s) = false after SetDynamic", method)
+	}
+	if _, err := m.Register(context.Background(), method, nil); err != nil || len(*calls) != 1 {
+		t.Errorf("Register(%s) error = %v, calls = %v", method, err, *calls)
+	}
+}
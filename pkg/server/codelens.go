@@ -0,0 +1,155 @@
+package server
+
+import (
+	"slices"
+	"time"
+
+	"github.com/kjbreil/glsp"
+	"github.com/kjbreil/glsp/pkg/codelens"
+	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/pkg/location"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+	protocol317 "github.com/kjbreil/glsp/protocol_3_17"
+)
+
+// pendingRefresh is a refresh of code lenses and inlay hints that waits for edits to settle.
+type pendingRefresh struct {
+	timer      *time.Timer
+	codeLenses bool
+	inlayHints bool
+}
+
+// codeLensResolveProvider reports whether any language resolves code lenses.
+func (s *Server) codeLensResolveProvider() bool {
+	resolve := false
+	s.languages.Languages(func(def language.LanguageDef) bool {
+		_, resolve = def.(language.CodeLensResolver)
+		return !resolve
+	})
+	return resolve
+}
+
+func (s *Server) textDocumentCodeLens(ctx *glsp.Context, params *protocol.CodeLensParams) ([]protocol.CodeLens, error) {
+	_, file := s.languages.GetFromUri(params.TextDocument.URI)
+	provider, ok := file.(language.CodeLensProvider)
+	doc := s.document(params.TextDocument.URI)
+	if !ok || doc == nil {
+		return nil, nil
+	}
+
+	doc.mu.Lock()
+	defer doc.mu.Unlock()
+	// lenses without a range cannot be shown, they are left out before the data of the others is numbered
+	found := slices.DeleteFunc(provider.CodeLenses(), func(l codelens.Lens) bool { return l.CharRange.ProtocolRange() == nil })
+	list := doc.lenses.set(found)
+
+	lenses := make([]protocol.CodeLens, len(found))
+	for i := range found {
+		lenses[i], _ = found[i].Protocol(resolveData{URI: params.TextDocument.URI, List: list, Index: i})
+	}
+	return lenses, nil
+}
+
+func (s *Server) codeLensResolve(ctx *glsp.Context, params *protocol.CodeLens) (*protocol.CodeLens, error) {
+	data, ok := decodeResolveData(params.Data)
+	if !ok {
+		return params, nil
+	}
+
+	lang, file := s.languages.GetFromUri(data.URI)
+	doc := s.document(data.URI)
+	if file == nil || doc == nil {
+		return params, nil
+	}
+	resolver, ok := lang.Def().(language.CodeLensResolver)
+	if !ok {
+		return params, nil
+	}
+
+	doc.mu.Lock()
+	defer doc.mu.Unlock()
+	// lenses of an older list may have moved, the client asks for the new list after a change
+	lens := doc.lenses.at(data)
+	if lens == nil {
+		return params, nil
+	}
+	*lens = resolver.ResolveCodeLens(file, *lens)
+	resolved, ok := lens.Protocol(params.Data)
+	if !ok {
+		return params, nil
+	}
+	return &resolved, nil
+}
+
+func (s *Server) textDocumentInlayHint(ctx *glsp.Context, params *protocol317.InlayHintParams) ([]protocol317.InlayHint, error) {
+	_, file := s.languages.GetFromUri(params.TextDocument.URI)
+	provider, ok := file.(language.InlayHintProvider)
+	doc := s.document(params.TextDocument.URI)
+	if !ok || doc == nil {
+		return nil, nil
+	}
+
+	doc.mu.Lock()
+	hints := provider.InlayHints(*location.ProtocolRange(&params.Range))
+	doc.mu.Unlock()
+
+	result := make([]protocol317.InlayHint, len(hints))
+	for i := range hints {
+		result[i] = hints[i].Protocol()
+	}
+	return result, nil
+}
+
+// refreshSupport reports whether the client takes workspace/codeLens/refresh and workspace/inlayHint/refresh
+// requests.
+func (s *Server) refreshSupport() (codeLenses, inlayHints bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if workspace := s.clientCapabilities.Workspace; workspace != nil && workspace.CodeLens != nil {
+		codeLenses = workspace.CodeLens.RefreshSupport != nil && *workspace.CodeLens.RefreshSupport
+	}
+	if workspace := s.clientCapabilities.InlayHintWorkspace; workspace != nil {
+		inlayHints = workspace.RefreshSupport != nil && *workspace.RefreshSupport
+	}
+	return codeLenses, inlayHints
+}
+
+// scheduleRefresh asks the client to request the code lenses and inlay hints again after delay, for lang or
+// for every language when lang is nil. Only languages that implement language.Refresher are refreshed, and a
+// refresh scheduled before is merged into this one so a burst of edits refreshes once.
+func (s *Server) scheduleRefresh(ctx *glsp.Context, lang language.LanguageDef, delay time.Duration) {
+	var codeLenses, inlayHints bool
+	s.languages.Languages(func(def language.LanguageDef) bool {
+		if lang != nil && def != lang {
+			return true
+		}
+		if refresher, ok := def.(language.Refresher); ok {
+			codeLenses = codeLenses || refresher.RefreshCodeLenses()
+			inlayHints = inlayHints || refresher.RefreshInlayHints()
+		}
+		return true
+	})
+	codeLensSupport, inlayHintSupport := s.refreshSupport()
+	codeLenses = codeLenses && codeLensSupport
+	inlayHints = inlayHints && inlayHintSupport
+	if !codeLenses && !inlayHints {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.refresh.timer != nil && s.refresh.timer.Stop() {
+		codeLenses = codeLenses || s.refresh.codeLenses
+		inlayHints = inlayHints || s.refresh.inlayHints
+	}
+	s.refresh.codeLenses, s.refresh.inlayHints = codeLenses, inlayHints
+	// the timer calls the client outside of the handler, requests from inside it would never get a response
+	s.refresh.timer = time.AfterFunc(delay, func() {
+		if codeLenses {
+			ctx.Call(protocol.ServerWorkspaceCodeLensRefresh, nil, nil)
+		}
+		if inlayHints {
+			ctx.Call(protocol317.ServerWorkspaceInlayHintRefresh, nil, nil)
+		}
+	})
+}
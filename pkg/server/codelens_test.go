@@ -0,0 +1,122 @@
+package server
+
+import (
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/kjbreil/glsp"
+	"github.com/kjbreil/glsp/pkg/codelens"
+	"github.com/kjbreil/glsp/pkg/commands"
+	"github.com/kjbreil/glsp/pkg/editreader"
+	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/pkg/location"
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// lensFile is a test file with a lens on its first word and a lens without a range.
+type lensFile struct {
+	*testFile
+}
+
+func (f *lensFile) CodeLenses() []codelens.Lens {
+	return []codelens.Lens{
+		{Command: &commands.Reference{Title: "Unset", Name: "unset"}},
+		{
+			CharRange: editreader.CharRange{
+				Start: f.reader.CharBefore(location.Point{Line: 0, Column: 1}),
+				End:   f.reader.CharBefore(location.Point{Line: 0, Column: 4}),
+			},
+			Command: &commands.Reference{Title: "Run", Name: "run"},
+		},
+	}
+}
+
+// refreshLanguage is a test language whose code lenses are refreshed after every change.
+type refreshLanguage struct {
+	testLanguage
+}
+
+func (l *refreshLanguage) RefreshCodeLenses() bool { return true }
+func (l *refreshLanguage) RefreshInlayHints() bool { return false }
+
+func TestTextDocumentCodeLens(t *testing.T) {
+	lang := &testLanguage{parse: func(u uri.DocumentURI, r io.Reader) (language.File, error) {
+		f, err := newTestFile(u, r)
+		return &lensFile{f}, err
+	}}
+	s := New(WithLanguage(lang))
+	initializeServer(t, s, `{"textDocument":{"codeLens":{}}}`)
+
+	u := uri.DocumentURI("file:///a.test")
+	openDocument(t, s, u, "run main")
+	lenses, err := s.textDocumentCodeLens(nil, &protocol.CodeLensParams{TextDocument: protocol.TextDocumentIdentifier{URI: u}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the lens without a range is left out
+	if len(lenses) != 1 || lenses[0].Command == nil || lenses[0].Command.Title != "Run" {
+		t.Fatalf("textDocumentCodeLens() = %v, want the Run lens", lenses)
+	}
+	want := protocol.Range{End: protocol.Position{Character: 4}}
+	if lenses[0].Range != want {
+		t.Errorf("Range = %v, want %v", lenses[0].Range, want)
+	}
+
+	resolved, err := s.codeLensResolve(nil, &lenses[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.Command == nil || resolved.Command.Title != "Run" {
+		t.Errorf("codeLensResolve() = %v, want the Run lens", resolved)
+	}
+}
+
+func TestScheduleRefresh(t *testing.T) {
+	lang := &refreshLanguage{}
+	s := New(WithLanguage(lang))
+	initializeServer(t, s, `{"workspace":{"codeLens":{"refreshSupport":true}}}`)
+
+	var mu sync.Mutex
+	var calls []string
+	done := make(chan struct{}, 10)
+	ctx := &glsp.Context{Call: func(method string, params any, result any) {
+		mu.Lock()
+		calls = append(calls, method)
+		mu.Unlock()
+		done <- struct{}{}
+	}}
+
+	// a burst of changes refreshes once
+	for range 5 {
+		s.scheduleRefresh(ctx, lang, 20*time.Millisecond)
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduleRefresh did not refresh")
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(calls) != 1 || calls[0] != protocol.ServerWorkspaceCodeLensRefresh {
+		t.Errorf("calls = %v, want one %s", calls, protocol.ServerWorkspaceCodeLensRefresh)
+	}
+}
+
+func TestScheduleRefreshUnsupported(t *testing.T) {
+	lang := &refreshLanguage{}
+	s := New(WithLanguage(lang))
+	initializeServer(t, s, `{}`)
+
+	called := false
+	ctx := &glsp.Context{Call: func(method string, params any, result any) { called = true }}
+	s.scheduleRefresh(ctx, lang, 0)
+	time.Sleep(20 * time.Millisecond)
+	if called {
+		t.Errorf("scheduleRefresh refreshed a client without refresh support")
+	}
+}
@@ -0,0 +1,256 @@
+package server
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/kjbreil/glsp"
+	"github.com/kjbreil/glsp/internal/helpers"
+	"github.com/kjbreil/glsp/pkg/completion"
+	"github.com/kjbreil/glsp/pkg/editreader"
+	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/pkg/location"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// completionData is sent as the data of a completion item so completionItem/resolve finds its completion.
+type completionData struct {
+	List  uint64 `json:"list"`
+	Index int    `json:"index"`
+}
+
+// servedCompletions are the completions of the last list sent to the client, items of older lists are not
+// resolved.
+type servedCompletions struct {
+	id    uint64
+	lang  language.LanguageDef
+	items []completion.Completion
+}
+
+func (s *Server) textDocumentCompletion(ctx *glsp.Context, params *protocol.CompletionParams) (any, error) {
+	lang, file := s.languages.GetFromUri(params.TextDocument.URI)
+	doc := s.document(params.TextDocument.URI)
+	if file == nil || doc == nil {
+		return nil, nil
+	}
+
+	doc.mu.Lock()
+	defer doc.mu.Unlock()
+
+	reader, err := doc.fileReader(file)
+	if err != nil {
+		return nil, err
+	}
+	point := location.ProtocolPositionPoint(params.TextDocumentPositionParams)
+	word, prefix := completion.Word(reader, point)
+	c := language.CompletionContext{
+		Point:       point,
+		TriggerKind: protocol.CompletionTriggerKindInvoked,
+		Prefix:      prefix,
+	}
+	if params.Context != nil {
+		c.TriggerKind = params.Context.TriggerKind
+		if params.Context.TriggerCharacter != nil {
+			c.TriggerCharacter = *params.Context.TriggerCharacter
+		}
+	}
+
+	var completions completion.Completions
+	var incomplete bool
+	if completer, ok := file.(language.FileCompleter); ok {
+		completions = completer.Complete(c)
+	} else if completer, ok := lang.Def().(language.LanguageCompleter); ok {
+		completions = completer.Complete(file, c)
+	} else if engine := s.completionEngine(lang.Def()); engine != nil {
+		completions, incomplete = engine.CompleteAt(reader, point)
+	} else {
+		completions = lang.Def().Completions()
+	}
+
+	// the insert range ends at the cursor, the replace range covers the whole word
+	insert := location.Range{Start: word.Start, End: point}
+	insertReplace := s.insertReplaceSupport()
+	snippets := s.snippetSupport()
+
+	// items of a language without resolve support are sent with their documentation
+	resolve := language.CompletionOptionsOf(lang.Def()).Resolve
+	served := completions.Slice()
+	s.mu.Lock()
+	s.completions.id++
+	s.completions.lang = lang.Def()
+	s.completions.items = served
+	list := s.completions.id
+	s.mu.Unlock()
+
+	items := make([]protocol.CompletionItem, len(served))
+	for i := range served {
+		if resolve {
+			items[i] = served[i].ProtocolUnresolved()
+			items[i].Data = completionData{List: list, Index: i}
+		} else {
+			items[i] = served[i].Protocol()
+		}
+		if !snippets {
+			plainText(&items[i])
+		}
+		setTextEdit(&items[i], insert.ProtocolRange(), word.ProtocolRange(), insertReplace)
+	}
+
+	return protocol.CompletionList{
+		IsIncomplete: incomplete,
+		Items:        items,
+	}, nil
+}
+
+// fileReader returns the editreader the text of the file is read from, files that do not keep one are read
+// from their text. The caller holds d.mu.
+func (d *document) fileReader(file language.File) (*editreader.File, error) {
+	if r, ok := file.(language.ReaderFile); ok {
+		return r.Reader(), nil
+	}
+	return editreader.New(strings.NewReader(d.fileText(file)))
+}
+
+// completionEngine returns the engine filtering the static completions of the language, it is nil unless the
+// server was created WithCompletionEngine. The engine is built again when the language returns other
+// completions.
+func (s *Server) completionEngine(lang language.LanguageDef) *completion.Engine {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.completionLimit <= 0 {
+		return nil
+	}
+
+	completions := lang.Completions()
+	engine := s.completionEngines[lang.ID()]
+	if engine == nil || !engine.BuiltFrom(completions) {
+		engine = completion.NewEngine(completions)
+		engine.Limit = s.completionLimit
+		s.completionEngines[lang.ID()] = engine
+	}
+	return engine
+}
+
+// completionItemResolve adds the documentation to a completion item of the last list.
+func (s *Server) completionItemResolve(ctx *glsp.Context, params *protocol.CompletionItem) (*protocol.CompletionItem, error) {
+	var data completionData
+	if raw, err := json.Marshal(params.Data); err != nil || json.Unmarshal(raw, &data) != nil {
+		return params, nil
+	}
+
+	s.mu.Lock()
+	served := s.completions
+	s.mu.Unlock()
+	if data.List != served.id || data.Index < 0 || data.Index >= len(served.items) {
+		return params, nil
+	}
+
+	c := served.items[data.Index]
+	if resolver, ok := served.lang.(language.CompletionResolver); ok {
+		c = resolver.ResolveCompletion(c)
+	}
+	resolved := c.ProtocolUnresolved()
+	params.Detail = resolved.Detail
+	params.AdditionalTextEdits = resolved.AdditionalTextEdits
+	params.Command = resolved.Command
+	params.Documentation = c.Documentation()
+	return params, nil
+}
+
+// languageOptions returns the completion options declared by lang, or the options of every language merged
+// when lang is nil.
+func (s *Server) languageOptions(lang language.LanguageDef) language.CompletionOptions {
+	if lang != nil {
+		return language.CompletionOptionsOf(lang)
+	}
+
+	var merged language.CompletionOptions
+	s.languages.Languages(func(def language.LanguageDef) bool {
+		options := language.CompletionOptionsOf(def)
+		merged.TriggerCharacters = appendUnique(merged.TriggerCharacters, options.TriggerCharacters...)
+		merged.CommitCharacters = appendUnique(merged.CommitCharacters, options.CommitCharacters...)
+		merged.SignatureTriggerCharacters = appendUnique(merged.SignatureTriggerCharacters, options.SignatureTriggerCharacters...)
+		merged.SignatureRetriggerCharacters = appendUnique(merged.SignatureRetriggerCharacters, options.SignatureRetriggerCharacters...)
+		merged.Resolve = merged.Resolve || options.Resolve
+		return true
+	})
+	// languages are kept in a map, sorting keeps the capabilities the same between runs
+	slices.Sort(merged.TriggerCharacters)
+	slices.Sort(merged.CommitCharacters)
+	slices.Sort(merged.SignatureTriggerCharacters)
+	slices.Sort(merged.SignatureRetriggerCharacters)
+	return merged
+}
+
+func appendUnique(s []string, values ...string) []string {
+	for _, v := range values {
+		if !slices.Contains(s, v) {
+			s = append(s, v)
+		}
+	}
+	return s
+}
+
+// completionOptions returns the completion options of lang, or of every language when lang is nil.
+func (s *Server) completionOptions(lang language.LanguageDef) *protocol.CompletionOptions {
+	options := s.languageOptions(lang)
+	return &protocol.CompletionOptions{
+		TriggerCharacters:   options.TriggerCharacters,
+		AllCommitCharacters: options.CommitCharacters,
+		ResolveProvider:     helpers.Ptr(options.Resolve),
+	}
+}
+
+// insertReplaceSupport reports whether the client takes an insert and a replace range for completion items.
+func (s *Server) insertReplaceSupport() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	textDocument := s.clientCapabilities.TextDocument
+	if textDocument == nil || textDocument.Completion == nil || textDocument.Completion.CompletionItem == nil {
+		return false
+	}
+	support := textDocument.Completion.CompletionItem.InsertReplaceSupport
+	return support != nil && *support
+}
+
+// snippetSupport reports whether the client takes snippets as insert text.
+func (s *Server) snippetSupport() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	textDocument := s.clientCapabilities.TextDocument
+	if textDocument == nil || textDocument.Completion == nil || textDocument.Completion.CompletionItem == nil {
+		return false
+	}
+	support := textDocument.Completion.CompletionItem.SnippetSupport
+	return support != nil && *support
+}
+
+// plainText turns the snippet of the item into the plain text it would insert.
+func plainText(item *protocol.CompletionItem) {
+	if item.InsertTextFormat == nil || *item.InsertTextFormat != protocol.InsertTextFormatSnippet {
+		return
+	}
+	if item.InsertText != nil {
+		item.InsertText = helpers.Ptr(completion.PlainText(*item.InsertText))
+	}
+	item.InsertTextFormat = helpers.Ptr(protocol.InsertTextFormatPlainText)
+}
+
+// setTextEdit replaces the insert text of the item with an edit of the word at the cursor. Items that already
+// have an edit keep it.
+func setTextEdit(item *protocol.CompletionItem, insert protocol.Range, replace protocol.Range, insertReplace bool) {
+	if item.TextEdit != nil {
+		return
+	}
+	newText := item.Label
+	if item.InsertText != nil {
+		newText = *item.InsertText
+	}
+
+	if insertReplace {
+		item.TextEdit = protocol.InsertReplaceEdit{NewText: newText, Insert: insert, Replace: replace}
+	} else {
+		item.TextEdit = protocol.TextEdit{Range: replace, NewText: newText}
+	}
+}
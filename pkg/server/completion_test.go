@@ -0,0 +1,153 @@
+package server
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/kjbreil/glsp/internal/helpers"
+	"github.com/kjbreil/glsp/pkg/completion"
+	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/pkg/markdown"
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+func TestTextDocumentCompletionResolve(t *testing.T) {
+	for _, resolve := range []bool{false, true} {
+		lang := &testLanguage{
+			completions: completion.NewCompletions(completion.Completion{
+				Trigger:  "select",
+				Markdown: markdown.Markdown{Description: "selects rows"},
+			}),
+			options: language.CompletionOptions{Resolve: resolve},
+		}
+		s := New(WithLanguage(lang))
+		capabilities := initializeServer(t, s, `{"textDocument":{"completion":{}}}`)
+		if got := *capabilities.CompletionProvider.ResolveProvider; got != resolve {
+			t.Errorf("ResolveProvider = %v, want %v", got, resolve)
+		}
+
+		u := uri.DocumentURI("file:///a.test")
+		openDocument(t, s, u, "sel")
+		result, err := s.textDocumentCompletion(nil, &protocol.CompletionParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: u},
+				Position:     protocol.Position{Line: 0, Character: 3},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		item := result.(protocol.CompletionList).Items[0]
+		// documentation is left for completionItem/resolve only when the language resolves
+		if (item.Documentation == nil) != resolve || (item.Data != nil) != resolve {
+			t.Errorf("resolve %v: Documentation = %v, Data = %v", resolve, item.Documentation, item.Data)
+		}
+	}
+}
+
+func TestTextDocumentCompletionEngine(t *testing.T) {
+	lang := &testLanguage{
+		completions: completion.NewCompletions(completion.Completion{Trigger: "select"}, completion.Completion{Trigger: "update"}),
+	}
+	s := New(WithLanguage(lang), WithCompletionEngine(10))
+	initializeServer(t, s, `{"textDocument":{"completion":{"completionItem":{"insertReplaceSupport":true}}}}`)
+
+	u := uri.DocumentURI("file:///a.test")
+	openDocument(t, s, u, "x upect")
+	complete := func() []protocol.CompletionItem {
+		t.Helper()
+		result, err := s.textDocumentCompletion(nil, &protocol.CompletionParams{
+			TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+				TextDocument: protocol.TextDocumentIdentifier{URI: u},
+				Position:     protocol.Position{Line: 0, Character: 4},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result.(protocol.CompletionList).Items
+	}
+
+	items := complete()
+	if len(items) != 1 || items[0].Label != "update" {
+		t.Fatalf("completion of up = %v, want update", items)
+	}
+	edit := items[0].TextEdit.(protocol.InsertReplaceEdit)
+	wantInsert := protocol.Range{Start: protocol.Position{Character: 2}, End: protocol.Position{Character: 4}}
+	wantReplace := protocol.Range{Start: protocol.Position{Character: 2}, End: protocol.Position{Character: 7}}
+	if edit.Insert != wantInsert || edit.Replace != wantReplace {
+		t.Errorf("edit = %v %v, want %v %v", edit.Insert, edit.Replace, wantInsert, wantReplace)
+	}
+
+	// the engine is built again for the new completions of the language
+	lang.completions = completion.NewCompletions(completion.Completion{Trigger: "upsert"})
+	if items := complete(); len(items) != 1 || items[0].Label != "upsert" {
+		t.Errorf("completion of up after the completions changed = %v, want upsert", items)
+	}
+}
+
+// otherLanguage is a test language with the id "other".
+type otherLanguage struct {
+	testLanguage
+}
+
+func (l *otherLanguage) ID() string { return "other" }
+
+func TestCompletionOptions(t *testing.T) {
+	completions := completion.NewCompletions(completion.Completion{Trigger: "select"})
+	test := &testLanguage{
+		completions: completions,
+		options:     language.CompletionOptions{TriggerCharacters: []string{".", " "}, CommitCharacters: []string{";"}},
+	}
+	other := &otherLanguage{testLanguage{
+		completions: completions,
+		options:     language.CompletionOptions{TriggerCharacters: []string{":", "."}, Resolve: true},
+	}}
+
+	// the options of every language are merged in the static capability
+	s := New(WithLanguage(test), WithLanguage(other))
+	capabilities := initializeServer(t, s, `{"textDocument":{"completion":{}}}`)
+	provider := capabilities.CompletionProvider
+	if want := []string{" ", ".", ":"}; !slices.Equal(provider.TriggerCharacters, want) {
+		t.Errorf("TriggerCharacters = %q, want %q", provider.TriggerCharacters, want)
+	}
+	if want := []string{";"}; !slices.Equal(provider.AllCommitCharacters, want) {
+		t.Errorf("AllCommitCharacters = %q, want %q", provider.AllCommitCharacters, want)
+	}
+	if !*provider.ResolveProvider {
+		t.Errorf("ResolveProvider = false, want true when a language resolves")
+	}
+
+	// each language is registered with its own options
+	s = New(WithLanguage(test), WithLanguage(other))
+	registered := make(chan []protocol.Registration, 2)
+	s.registrations.SetCaller(func(ctx context.Context, method string, params any, result any) error {
+		registered <- params.(protocol.RegistrationParams).Registrations
+		return nil
+	})
+	initializeServer(t, s, `{"textDocument":{"completion":{"dynamicRegistration":true}}}`)
+	if err := s.initialized(nil, &protocol.InitializedParams{}); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]protocol.CompletionOptions{
+		"test":  {TriggerCharacters: []string{".", " "}, AllCommitCharacters: []string{";"}, ResolveProvider: helpers.Ptr(false)},
+		"other": {TriggerCharacters: []string{":", "."}, ResolveProvider: helpers.Ptr(true)},
+	}
+	for range want {
+		var registrations []protocol.Registration
+		select {
+		case registrations = <-registered:
+		case <-time.After(time.Second):
+			t.Fatal("no registration")
+		}
+		options := registrations[0].RegisterOptions.(protocol.CompletionRegistrationOptions)
+		id := *(*options.DocumentSelector)[0].Language
+		if !reflect.DeepEqual(options.CompletionOptions, want[id]) {
+			t.Errorf("%s registered %+v, want %+v", id, options.CompletionOptions, want[id])
+		}
+	}
+}
@@ -1,19 +1,205 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"time"
+
 	"github.com/kjbreil/glsp"
-	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/internal/helpers"
 	"github.com/kjbreil/glsp/pkg/problems"
+	"github.com/kjbreil/glsp/pkg/uri"
 	protocol "github.com/kjbreil/glsp/protocol_3_16"
+	protocol317 "github.com/kjbreil/glsp/protocol_3_17"
 )
 
-func (s *Server) publishDiagnostics(ctx *glsp.Context, file language.File, maxLevel problems.ProblemLevel) {
-	if file == nil {
+// DefaultDiagnosticsDelay is how long edits to a document are coalesced before its diagnostics are published.
+const DefaultDiagnosticsDelay = 200 * time.Millisecond
+
+// pullDiagnostics reports whether the client pulls diagnostics, otherwise they are pushed.
+func (s *Server) pullDiagnostics() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.clientCapabilities.PullDiagnostics()
+}
+
+// scheduleDiagnostics publishes the diagnostics of the document after delay. A run scheduled before is
+// cancelled, so a burst of edits publishes once for the last version.
+func (s *Server) scheduleDiagnostics(ctx *glsp.Context, u uri.DocumentURI, delay time.Duration) {
+	if s.pullDiagnostics() {
+		return
+	}
+
+	s.mu.Lock()
+	doc := s.documents[u]
+	if doc == nil {
+		s.mu.Unlock()
+		return
+	}
+	if doc.cancel != nil {
+		doc.cancel()
+	}
+	runCtx, cancel := context.WithCancel(context.Background())
+	doc.cancel = cancel
+	version := doc.version
+	s.mu.Unlock()
+
+	go func() {
+		defer cancel()
+
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-runCtx.Done():
+			return
+		case <-timer.C:
+		}
+
+		// holding the document lock keeps the file and its version still while its problems are computed
+		// and published, a run for a version that changed since it was scheduled is dropped as the run
+		// scheduled by the change publishes
+		doc.mu.Lock()
+		defer doc.mu.Unlock()
+		s.mu.Lock()
+		current := doc.version
+		s.mu.Unlock()
+		if runCtx.Err() != nil || current != version {
+			return
+		}
+		_, file := s.languages.GetFromUri(u)
+		if file == nil {
+			return
+		}
+		diagnostics := file.Problems().ProtocolDiagnostics(problems.ProblemLevelNone)
+		if runCtx.Err() != nil {
+			return
+		}
+		ctx.Notify(protocol.ServerTextDocumentPublishDiagnostics, protocol.PublishDiagnosticsParams{
+			URI:         u,
+			Version:     helpers.Ptr(protocol.UInteger(version)),
+			Diagnostics: diagnostics,
+		})
+	}()
+}
+
+// clearDiagnostics removes the diagnostics of a closed document from the client.
+func (s *Server) clearDiagnostics(ctx *glsp.Context, u uri.DocumentURI) {
+	if s.pullDiagnostics() {
 		return
 	}
-	diagnostics := file.Problems().ProtocolDiagnostics(maxLevel)
 	ctx.Notify(protocol.ServerTextDocumentPublishDiagnostics, protocol.PublishDiagnosticsParams{
-		URI:         file.Uri(),
-		Diagnostics: diagnostics,
+		URI:         u,
+		Diagnostics: []protocol.Diagnostic{},
 	})
 }
+
+// resultID identifies the diagnostics of a document version while no document changes. Diagnostics can
+// depend on other files, so a change to any open document gives every document a new result id.
+func resultID(generation uint64, version protocol.Integer) string {
+	return strconv.FormatUint(generation, 10) + "-" + strconv.FormatInt(int64(version), 10)
+}
+
+// documentDiagnostics computes the diagnostics of an open document and returns them with its version and
+// result id. The diagnostics are not computed when the document still has the previous result id, then
+// changed is false.
+func (s *Server) documentDiagnostics(u uri.DocumentURI, previousResultID *string) (version protocol.Integer, id string, diagnostics []protocol.Diagnostic, changed bool, err error) {
+	_, file := s.languages.GetFromUri(u)
+	doc := s.document(u)
+	if file == nil || doc == nil {
+		return 0, "", nil, false, ErrFileNotOpened
+	}
+
+	doc.mu.Lock()
+	defer doc.mu.Unlock()
+
+	s.mu.Lock()
+	version = doc.version
+	id = resultID(s.generation, version)
+	s.mu.Unlock()
+
+	if previousResultID != nil && *previousResultID == id {
+		return version, id, nil, false, nil
+	}
+	return version, id, file.Problems().ProtocolDiagnostics(problems.ProblemLevelNone), true, nil
+}
+
+func (s *Server) textDocumentDiagnostic(ctx *glsp.Context, params *protocol317.DocumentDiagnosticParams) (any, error) {
+	_, id, diagnostics, changed, err := s.documentDiagnostics(params.TextDocument.URI, params.PreviousResultId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !changed {
+		return protocol317.RelatedUnchangedDocumentDiagnosticReport{
+			UnchangedDocumentDiagnosticReport: protocol317.UnchangedDocumentDiagnosticReport{
+				Kind:     string(protocol317.DocumentDiagnosticReportKindUnchanged),
+				ResultID: id,
+			},
+		}, nil
+	}
+	return protocol317.RelatedFullDocumentDiagnosticReport{
+		FullDocumentDiagnosticReport: protocol317.FullDocumentDiagnosticReport{
+			Kind:     string(protocol317.DocumentDiagnosticReportKindFull),
+			ResultID: helpers.Ptr(id),
+			Items:    diagnostics,
+		},
+	}, nil
+}
+
+// workspaceDiagnostic reports the diagnostics of every open document.
+func (s *Server) workspaceDiagnostic(ctx *glsp.Context, params *protocol317.WorkspaceDiagnosticParams) (*protocol317.WorkspaceDiagnosticReport, error) {
+	previous := make(map[uri.DocumentURI]string, len(params.PreviousResultIds))
+	for _, id := range params.PreviousResultIds {
+		previous[id.URI] = id.Value
+	}
+
+	s.mu.Lock()
+	uris := make([]uri.DocumentURI, 0, len(s.documents))
+	for u := range s.documents {
+		uris = append(uris, u)
+	}
+	s.mu.Unlock()
+	slices.Sort(uris)
+
+	report := &protocol317.WorkspaceDiagnosticReport{
+		Items: []protocol317.WorkspaceDocumentDiagnosticReport{},
+	}
+	for _, u := range uris {
+		var previousResultID *string
+		if id, ok := previous[u]; ok {
+			previousResultID = &id
+		}
+		version, id, diagnostics, changed, err := s.documentDiagnostics(u, previousResultID)
+		if errors.Is(err, ErrFileNotOpened) {
+			// closed while the report was built
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !changed {
+			report.Items = append(report.Items, protocol317.WorkspaceUnchangedDocumentDiagnosticReport{
+				UnchangedDocumentDiagnosticReport: protocol317.UnchangedDocumentDiagnosticReport{
+					Kind:     string(protocol317.DocumentDiagnosticReportKindUnchanged),
+					ResultID: id,
+				},
+				URI:     u,
+				Version: helpers.Ptr(version),
+			})
+			continue
+		}
+		report.Items = append(report.Items, protocol317.WorkspaceFullDocumentDiagnosticReport{
+			FullDocumentDiagnosticReport: protocol317.FullDocumentDiagnosticReport{
+				Kind:     string(protocol317.DocumentDiagnosticReportKindFull),
+				ResultID: helpers.Ptr(id),
+				Items:    diagnostics,
+			},
+			URI:     u,
+			Version: helpers.Ptr(version),
+		})
+	}
+	return report, nil
+}
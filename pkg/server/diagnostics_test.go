@@ -0,0 +1,150 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kjbreil/glsp"
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+	protocol317 "github.com/kjbreil/glsp/protocol_3_17"
+)
+
+func TestTextDocumentDiagnosticResultID(t *testing.T) {
+	s := New(WithLanguage(&testLanguage{}))
+	initializeServer(t, s, `{"textDocument":{"diagnostic":{}}}`)
+
+	a := uri.DocumentURI("file:///a.test")
+	openDocument(t, s, a, "a")
+	pull := func(previous *string) any {
+		t.Helper()
+		report, err := s.textDocumentDiagnostic(nil, &protocol317.DocumentDiagnosticParams{
+			TextDocument:     protocol.TextDocumentIdentifier{URI: a},
+			PreviousResultId: previous,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return report
+	}
+
+	full, ok := pull(nil).(protocol317.RelatedFullDocumentDiagnosticReport)
+	if !ok {
+		t.Fatalf("first pull is not a full report")
+	}
+	if _, ok := pull(full.ResultID).(protocol317.RelatedUnchangedDocumentDiagnosticReport); !ok {
+		t.Errorf("pull without changes is not unchanged")
+	}
+
+	// diagnostics can depend on other files, opening one changes the result id of a
+	openDocument(t, s, "file:///b.test", "b")
+	if _, ok := pull(full.ResultID).(protocol317.RelatedFullDocumentDiagnosticReport); !ok {
+		t.Errorf("pull after another document changed is not a full report")
+	}
+}
+
+// publishRecorder is a context whose diagnostics notifications are sent on published.
+func publishRecorder() (*glsp.Context, chan protocol.PublishDiagnosticsParams) {
+	published := make(chan protocol.PublishDiagnosticsParams, 16)
+	return &glsp.Context{Notify: func(method string, params any) {
+		if method == protocol.ServerTextDocumentPublishDiagnostics {
+			published <- params.(protocol.PublishDiagnosticsParams)
+		}
+	}}, published
+}
+
+// change replaces the text of the document, the change publishes through ctx.
+func change(t *testing.T, s *Server, ctx *glsp.Context, u uri.DocumentURI, version protocol.Integer, text string) {
+	t.Helper()
+	err := s.textDocumentDidChange(ctx, &protocol.DidChangeTextDocumentParams{
+		TextDocument:   protocol.VersionedTextDocumentIdentifier{TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: u}, Version: version},
+		ContentChanges: []any{protocol.TextDocumentContentChangeEventWhole{Text: text}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestScheduleDiagnosticsDebounce(t *testing.T) {
+	s := New(WithLanguage(&testLanguage{}), WithDiagnosticsDelay(20*time.Millisecond))
+	initializeServer(t, s, `{}`)
+	u := uri.DocumentURI("file:///a.test")
+	openDocument(t, s, u, "a")
+
+	ctx, published := publishRecorder()
+	for version := protocol.Integer(2); version <= 4; version++ {
+		change(t, s, ctx, u, version, "a")
+	}
+
+	select {
+	case params := <-published:
+		if params.URI != u || params.Version == nil || *params.Version != 4 {
+			t.Errorf("published %s version %v, want %s version 4", params.URI, params.Version, u)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("diagnostics not published")
+	}
+	select {
+	case params := <-published:
+		t.Errorf("published again for version %v", params.Version)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestScheduleDiagnosticsCancel(t *testing.T) {
+	s := New(WithLanguage(&testLanguage{}), WithDiagnosticsDelay(20*time.Millisecond))
+	initializeServer(t, s, `{}`)
+	u := uri.DocumentURI("file:///a.test")
+	openDocument(t, s, u, "a")
+
+	ctx, published := publishRecorder()
+	change(t, s, ctx, u, 2, "b")
+	if err := s.textDocumentDidClose(ctx, &protocol.DidCloseTextDocumentParams{TextDocument: protocol.TextDocumentIdentifier{URI: u}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// closing clears the diagnostics, the pending run never publishes
+	if params := <-published; params.Version != nil || len(params.Diagnostics) != 0 {
+		t.Errorf("close published version %v with %d diagnostics, want the diagnostics cleared", params.Version, len(params.Diagnostics))
+	}
+	select {
+	case params := <-published:
+		t.Errorf("cancelled run published version %v", params.Version)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestScheduleDiagnosticsVersionChanged(t *testing.T) {
+	s := New(WithLanguage(&testLanguage{}))
+	initializeServer(t, s, `{}`)
+	u := uri.DocumentURI("file:///a.test")
+	openDocument(t, s, u, "a")
+
+	// the run fires while the document changes, it must not publish the problems of the new text for the
+	// version it was scheduled for
+	ctx, published := publishRecorder()
+	doc := s.document(u)
+	doc.mu.Lock()
+	s.scheduleDiagnostics(ctx, u, 0)
+	time.Sleep(20 * time.Millisecond)
+	_, file := s.languages.GetFromUri(u)
+	file.Reset("b")
+	s.setVersion(doc, 2)
+	doc.mu.Unlock()
+
+	select {
+	case params := <-published:
+		t.Errorf("published version %v after the document changed", params.Version)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	s.scheduleDiagnostics(ctx, u, 0)
+	select {
+	case params := <-published:
+		if params.Version == nil || *params.Version != 2 {
+			t.Errorf("published version %v, want 2", params.Version)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("diagnostics not published")
+	}
+}
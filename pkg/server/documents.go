@@ -0,0 +1,152 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/kjbreil/glsp/pkg/codelens"
+	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/pkg/links"
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// document is the server side state of an open document.
+type document struct {
+	// mu is held while the file is edited or its diagnostics are computed and published
+	mu sync.Mutex
+	// text mirrors the content of the file, it is read for files that do not return their text and is
+	// guarded by mu
+	text string
+	// lenses and links are the last sent for the document so they can be resolved, they are guarded by mu
+	lenses served[codelens.Lens]
+	links  served[links.Link]
+
+	// version and cancel are guarded by Server.mu
+	version protocol.Integer
+	// cancel stops the pending diagnostics run of the document
+	cancel context.CancelFunc
+}
+
+// resolveData is sent as the data of an item of a document so its resolve request finds it in the served
+// list.
+type resolveData struct {
+	URI   uri.DocumentURI `json:"uri"`
+	List  uint64          `json:"list"`
+	Index int             `json:"index"`
+}
+
+// decodeResolveData reads the resolveData the client sent back, ok is false when data is not one.
+func decodeResolveData(data any) (resolveData, bool) {
+	var d resolveData
+	if raw, err := json.Marshal(data); err != nil || json.Unmarshal(raw, &d) != nil || d.URI == "" {
+		return d, false
+	}
+	return d, true
+}
+
+// served is the last list of items of a kind sent for a document, items of older lists are not resolved.
+type served[T any] struct {
+	list  uint64
+	items []T
+}
+
+// set replaces the items and returns the id of the new list.
+func (s *served[T]) set(items []T) uint64 {
+	s.list++
+	s.items = items
+	return s.list
+}
+
+// at returns the item the data points to, nil when it is not in the current list.
+func (s *served[T]) at(data resolveData) *T {
+	if data.List != s.list || data.Index < 0 || data.Index >= len(s.items) {
+		return nil
+	}
+	return &s.items[data.Index]
+}
+
+func (s *Server) openDocument(u uri.DocumentURI, version protocol.Integer, text string) *document {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if doc := s.documents[u]; doc != nil && doc.cancel != nil {
+		doc.cancel()
+	}
+	doc := &document{version: version, text: text}
+	s.documents[u] = doc
+	s.generation++
+	return doc
+}
+
+func (s *Server) document(u uri.DocumentURI) *document {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.documents[u]
+}
+
+// setVersion records the version of the document after a change.
+func (s *Server) setVersion(doc *document, version protocol.Integer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	doc.version = version
+	s.generation++
+}
+
+// saved records that a document was saved, languages can check more of a saved document.
+func (s *Server) saved() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.generation++
+}
+
+// closeDocument forgets the document and cancels its pending diagnostics run.
+func (s *Server) closeDocument(u uri.DocumentURI) *document {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc := s.documents[u]
+	if doc == nil {
+		return nil
+	}
+	if doc.cancel != nil {
+		doc.cancel()
+	}
+	delete(s.documents, u)
+	delete(s.symbolIndex, u)
+	// a file of the workspace keeps the entry it had before it was opened, it is checked against the file
+	// when it is used
+	if entry, ok := s.hierarchyIndex[u]; ok && entry.workspace != nil {
+		s.hierarchyIndex[u] = *entry.workspace
+	} else {
+		delete(s.hierarchyIndex, u)
+	}
+	s.generation++
+	return doc
+}
+
+// replace applies an incremental change to the text, a nil range replaces all of it.
+func (d *document) replace(text string, r *protocol.Range) {
+	if r == nil {
+		d.text = text
+		return
+	}
+	start, end := r.IndexesIn(d.text)
+	if end < start {
+		end = start
+	}
+	d.text = d.text[:start] + text + d.text[end:]
+}
+
+// fileText returns the content of the file of the document, read from the file when it keeps its text. The
+// caller holds d.mu.
+func (d *document) fileText(file language.File) string {
+	switch f := file.(type) {
+	case language.TextFile:
+		return f.Text()
+	case language.ReaderFile:
+		return f.Reader().String()
+	}
+	return d.text
+}
@@ -0,0 +1,530 @@
+package server
+
+import (
+	"github.com/kjbreil/glsp/internal/helpers"
+	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/pkg/registration"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+	protocol317 "github.com/kjbreil/glsp/protocol_3_17"
+)
+
+// feature is an optional feature of the server. Its handlers are wired when a language has the feature and
+// the client supports it, and it is then advertised statically or registered per language.
+type feature struct {
+	method string
+	// has reports whether the language or the files it parses provide the feature
+	has func(lang language.LanguageDef) bool
+	// client reports whether the client supports the feature
+	client func(capabilities *protocol317.ClientCapabilities) bool
+	// wire sets the handlers of the feature, the static capability follows from them
+	wire func(handler *protocol317.Handler)
+	// advertise sets the options of the static capability, it is nil when the defaults are right
+	advertise func(capabilities *protocol317.ServerCapabilities)
+	// options are the options the feature is registered with for the language, it is nil for features that
+	// are only advertised statically
+	options func(lang language.LanguageDef, selector protocol.TextDocumentRegistrationOptions) any
+	// clear removes the static capability, for a feature no language has or the client does not support and
+	// for a feature that is registered per language, so the client does not get it twice
+	clear func(capabilities *protocol317.ServerCapabilities)
+}
+
+// defHas reports whether the LanguageDef implements T.
+func defHas[T any](lang language.LanguageDef) bool {
+	_, ok := lang.(T)
+	return ok
+}
+
+// textDocumentClient returns whether the client sends the text document capability checked by has.
+func textDocumentClient(has func(t *protocol317.TextDocumentClientCapabilities) bool) func(c *protocol317.ClientCapabilities) bool {
+	return func(c *protocol317.ClientCapabilities) bool {
+		return c.TextDocument != nil && has(c.TextDocument)
+	}
+}
+
+func (s *Server) features() []feature {
+	return []feature{
+		{
+			method: protocol.MethodTextDocumentCompletion,
+			has: func(lang language.LanguageDef) bool {
+				completions := lang.Completions()
+				return filesImplement[language.FileCompleter](lang) || defHas[language.LanguageCompleter](lang) ||
+					completions.Len() > 0
+			},
+			client: textDocumentClient(func(t *protocol317.TextDocumentClientCapabilities) bool { return t.Completion != nil }),
+			wire: func(handler *protocol317.Handler) {
+				handler.TextDocumentCompletion = s.textDocumentCompletion
+				handler.CompletionItemResolve = s.completionItemResolve
+			},
+			advertise: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.CompletionProvider = s.completionOptions(nil)
+			},
+			options: func(lang language.LanguageDef, selector protocol.TextDocumentRegistrationOptions) any {
+				return protocol.CompletionRegistrationOptions{
+					TextDocumentRegistrationOptions: selector,
+					CompletionOptions:               *s.completionOptions(lang),
+				}
+			},
+			clear: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.CompletionProvider = nil
+			},
+		},
+		{
+			method: protocol.MethodTextDocumentHover,
+			has:    filesImplement[language.HoverProvider],
+			client: textDocumentClient(func(t *protocol317.TextDocumentClientCapabilities) bool { return t.Hover != nil }),
+			wire: func(handler *protocol317.Handler) {
+				handler.TextDocumentHover = s.textDocumentHover
+			},
+			options: func(lang language.LanguageDef, selector protocol.TextDocumentRegistrationOptions) any {
+				return protocol.HoverRegistrationOptions{
+					TextDocumentRegistrationOptions: selector,
+				}
+			},
+			clear: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.HoverProvider = nil
+			},
+		},
+		{
+			method: protocol.MethodTextDocumentSignatureHelp,
+			has:    filesImplement[language.SignatureHelpProvider],
+			client: textDocumentClient(func(t *protocol317.TextDocumentClientCapabilities) bool { return t.SignatureHelp != nil }),
+			wire: func(handler *protocol317.Handler) {
+				handler.TextDocumentSignatureHelp = s.textDocumentSignatureHelp
+			},
+			advertise: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.SignatureHelpProvider = s.signatureHelpOptions(nil)
+			},
+			options: func(lang language.LanguageDef, selector protocol.TextDocumentRegistrationOptions) any {
+				return protocol.SignatureHelpRegistrationOptions{
+					TextDocumentRegistrationOptions: selector,
+					SignatureHelpOptions:            *s.signatureHelpOptions(lang),
+				}
+			},
+			clear: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.SignatureHelpProvider = nil
+			},
+		},
+		{
+			method: protocol.MethodTextDocumentDefinition,
+			has:    filesImplement[language.SymbolProvider],
+			client: textDocumentClient(func(t *protocol317.TextDocumentClientCapabilities) bool { return t.Definition != nil }),
+			wire: func(handler *protocol317.Handler) {
+				handler.TextDocumentDefinition = s.textDocumentDefinition
+			},
+			options: func(lang language.LanguageDef, selector protocol.TextDocumentRegistrationOptions) any {
+				return protocol.DefinitionRegistrationOptions{
+					TextDocumentRegistrationOptions: selector,
+				}
+			},
+			clear: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.DefinitionProvider = nil
+			},
+		},
+		{
+			method: protocol.MethodTextDocumentDeclaration,
+			has:    filesImplement[language.SymbolProvider],
+			client: textDocumentClient(func(t *protocol317.TextDocumentClientCapabilities) bool { return t.Declaration != nil }),
+			wire: func(handler *protocol317.Handler) {
+				handler.TextDocumentDeclaration = s.textDocumentDeclaration
+			},
+			options: func(lang language.LanguageDef, selector protocol.TextDocumentRegistrationOptions) any {
+				return protocol.DeclarationRegistrationOptions{
+					TextDocumentRegistrationOptions: selector,
+				}
+			},
+			clear: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.DeclarationProvider = nil
+			},
+		},
+		{
+			method: protocol.MethodTextDocumentReferences,
+			has:    filesImplement[language.SymbolProvider],
+			client: textDocumentClient(func(t *protocol317.TextDocumentClientCapabilities) bool { return t.References != nil }),
+			wire: func(handler *protocol317.Handler) {
+				handler.TextDocumentReferences = s.textDocumentReferences
+			},
+			options: func(lang language.LanguageDef, selector protocol.TextDocumentRegistrationOptions) any {
+				return protocol.ReferenceRegistrationOptions{
+					TextDocumentRegistrationOptions: selector,
+				}
+			},
+			clear: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.ReferencesProvider = nil
+			},
+		},
+		{
+			method: protocol.MethodTextDocumentDocumentHighlight,
+			has:    filesImplement[language.SymbolProvider],
+			client: textDocumentClient(func(t *protocol317.TextDocumentClientCapabilities) bool { return t.DocumentHighlight != nil }),
+			wire: func(handler *protocol317.Handler) {
+				handler.TextDocumentDocumentHighlight = s.textDocumentDocumentHighlight
+			},
+			options: func(lang language.LanguageDef, selector protocol.TextDocumentRegistrationOptions) any {
+				return protocol.DocumentHighlightRegistrationOptions{
+					TextDocumentRegistrationOptions: selector,
+				}
+			},
+			clear: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.DocumentHighlightProvider = nil
+			},
+		},
+		{
+			method: protocol.MethodTextDocumentDocumentSymbol,
+			has:    filesImplement[language.DocumentSymbolProvider],
+			client: textDocumentClient(func(t *protocol317.TextDocumentClientCapabilities) bool { return t.DocumentSymbol != nil }),
+			wire: func(handler *protocol317.Handler) {
+				handler.TextDocumentDocumentSymbol = s.textDocumentDocumentSymbol
+			},
+			options: func(lang language.LanguageDef, selector protocol.TextDocumentRegistrationOptions) any {
+				return protocol.DocumentSymbolRegistrationOptions{
+					TextDocumentRegistrationOptions: selector,
+				}
+			},
+			clear: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.DocumentSymbolProvider = nil
+			},
+		},
+		{
+			method: protocol.MethodWorkspaceSymbol,
+			has:    filesImplement[language.DocumentSymbolProvider],
+			client: func(c *protocol317.ClientCapabilities) bool {
+				return c.Workspace != nil && c.Workspace.Symbol != nil
+			},
+			wire: func(handler *protocol317.Handler) {
+				handler.WorkspaceSymbol = s.workspaceSymbol
+			},
+			clear: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.WorkspaceSymbolProvider = nil
+			},
+		},
+		{
+			method: protocol.MethodTextDocumentRename,
+			has: func(lang language.LanguageDef) bool {
+				return filesImplement[language.SymbolProvider](lang) || filesImplement[language.RenameProvider](lang)
+			},
+			client: textDocumentClient(func(t *protocol317.TextDocumentClientCapabilities) bool { return t.Rename != nil }),
+			wire: func(handler *protocol317.Handler) {
+				handler.TextDocumentPrepareRename = s.textDocumentPrepareRename
+				handler.TextDocumentRename = s.textDocumentRename
+			},
+			options: func(lang language.LanguageDef, selector protocol.TextDocumentRegistrationOptions) any {
+				return protocol.RenameRegistrationOptions{
+					TextDocumentRegistrationOptions: selector,
+					RenameOptions:                   protocol.RenameOptions{PrepareProvider: helpers.Ptr(true)},
+				}
+			},
+			clear: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.RenameProvider = nil
+			},
+		},
+		{
+			method: protocol.MethodTextDocumentFormatting,
+			has: func(lang language.LanguageDef) bool {
+				return defHas[language.Formatter](lang) || defHas[language.DocFormatter](lang)
+			},
+			client: textDocumentClient(func(t *protocol317.TextDocumentClientCapabilities) bool { return t.Formatting != nil }),
+			wire: func(handler *protocol317.Handler) {
+				handler.TextDocumentFormatting = s.textDocumentFormatting
+			},
+			options: func(lang language.LanguageDef, selector protocol.TextDocumentRegistrationOptions) any {
+				return protocol.DocumentFormattingRegistrationOptions{
+					TextDocumentRegistrationOptions: selector,
+				}
+			},
+			clear: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.DocumentFormattingProvider = nil
+			},
+		},
+		{
+			method: protocol.MethodTextDocumentRangeFormatting,
+			has:    defHas[language.RangeFormatter],
+			client: textDocumentClient(func(t *protocol317.TextDocumentClientCapabilities) bool { return t.RangeFormatting != nil }),
+			wire: func(handler *protocol317.Handler) {
+				handler.TextDocumentRangeFormatting = s.textDocumentRangeFormatting
+			},
+			options: func(lang language.LanguageDef, selector protocol.TextDocumentRegistrationOptions) any {
+				return protocol.DocumentRangeFormattingRegistrationOptions{
+					TextDocumentRegistrationOptions: selector,
+				}
+			},
+			clear: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.DocumentRangeFormattingProvider = nil
+			},
+		},
+		{
+			method: protocol.MethodTextDocumentOnTypeFormatting,
+			has:    defHas[language.OnTypeFormatter],
+			client: textDocumentClient(func(t *protocol317.TextDocumentClientCapabilities) bool { return t.OnTypeFormatting != nil }),
+			wire: func(handler *protocol317.Handler) {
+				handler.TextDocumentOnTypeFormatting = s.textDocumentOnTypeFormatting
+			},
+			advertise: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.DocumentOnTypeFormattingProvider = s.onTypeFormattingOptions()
+			},
+			clear: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.DocumentOnTypeFormattingProvider = nil
+			},
+		},
+		{
+			// the files of other languages fold on their brackets and indentation
+			method: protocol.MethodTextDocumentFoldingRange,
+			has:    filesImplement[language.FoldingRangeProvider],
+			client: textDocumentClient(func(t *protocol317.TextDocumentClientCapabilities) bool { return t.FoldingRange != nil }),
+			wire: func(handler *protocol317.Handler) {
+				handler.TextDocumentFoldingRange = s.textDocumentFoldingRange
+			},
+			options: func(lang language.LanguageDef, selector protocol.TextDocumentRegistrationOptions) any {
+				return protocol.FoldingRangeRegistrationOptions{
+					TextDocumentRegistrationOptions: selector,
+				}
+			},
+			clear: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.FoldingRangeProvider = nil
+			},
+		},
+		{
+			method: protocol.MethodTextDocumentSelectionRange,
+			has:    filesImplement[language.SelectionRangeProvider],
+			client: textDocumentClient(func(t *protocol317.TextDocumentClientCapabilities) bool { return t.SelectionRange != nil }),
+			wire: func(handler *protocol317.Handler) {
+				handler.TextDocumentSelectionRange = s.textDocumentSelectionRange
+			},
+			options: func(lang language.LanguageDef, selector protocol.TextDocumentRegistrationOptions) any {
+				return protocol.SelectionRangeRegistrationOptions{
+					TextDocumentRegistrationOptions: selector,
+				}
+			},
+			clear: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.SelectionRangeProvider = nil
+			},
+		},
+		{
+			method: protocol.MethodTextDocumentCodeLens,
+			has:    filesImplement[language.CodeLensProvider],
+			client: textDocumentClient(func(t *protocol317.TextDocumentClientCapabilities) bool { return t.CodeLens != nil }),
+			wire: func(handler *protocol317.Handler) {
+				handler.TextDocumentCodeLens = s.textDocumentCodeLens
+				handler.CodeLensResolve = s.codeLensResolve
+			},
+			advertise: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.CodeLensProvider = &protocol.CodeLensOptions{ResolveProvider: helpers.Ptr(s.codeLensResolveProvider())}
+			},
+			options: func(lang language.LanguageDef, selector protocol.TextDocumentRegistrationOptions) any {
+				return protocol.CodeLensRegistrationOptions{
+					TextDocumentRegistrationOptions: selector,
+					CodeLensOptions: protocol.CodeLensOptions{
+						ResolveProvider: helpers.Ptr(defHas[language.CodeLensResolver](lang)),
+					},
+				}
+			},
+			clear: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.CodeLensProvider = nil
+			},
+		},
+		{
+			method: protocol317.MethodTextDocumentInlayHint,
+			has:    filesImplement[language.InlayHintProvider],
+			client: textDocumentClient(func(t *protocol317.TextDocumentClientCapabilities) bool { return t.InlayHint != nil }),
+			wire: func(handler *protocol317.Handler) {
+				handler.TextDocumentInlayHint = s.textDocumentInlayHint
+			},
+			options: func(lang language.LanguageDef, selector protocol.TextDocumentRegistrationOptions) any {
+				return protocol317.InlayHintRegistrationOptions{
+					TextDocumentRegistrationOptions: selector,
+				}
+			},
+			clear: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.InlayHintProvider = nil
+			},
+		},
+		{
+			// files without links of their own have the URLs and paths in their comments and strings linked
+			method: protocol.MethodTextDocumentDocumentLink,
+			has: func(lang language.LanguageDef) bool {
+				return filesImplement[language.DocumentLinkProvider](lang) || filesImplement[language.SemanticsProvider](lang)
+			},
+			client: textDocumentClient(func(t *protocol317.TextDocumentClientCapabilities) bool { return t.DocumentLink != nil }),
+			wire: func(handler *protocol317.Handler) {
+				handler.TextDocumentDocumentLink = s.textDocumentDocumentLink
+				handler.DocumentLinkResolve = s.documentLinkResolve
+			},
+			advertise: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.DocumentLinkProvider = &protocol.DocumentLinkOptions{ResolveProvider: helpers.Ptr(s.documentLinkResolveProvider())}
+			},
+			options: func(lang language.LanguageDef, selector protocol.TextDocumentRegistrationOptions) any {
+				return protocol.DocumentLinkRegistrationOptions{
+					TextDocumentRegistrationOptions: selector,
+					DocumentLinkOptions: protocol.DocumentLinkOptions{
+						ResolveProvider: helpers.Ptr(defHas[language.DocumentLinkResolver](lang)),
+					},
+				}
+			},
+			clear: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.DocumentLinkProvider = nil
+			},
+		},
+		{
+			method: protocol.MethodTextDocumentColor,
+			has: func(lang language.LanguageDef) bool {
+				detector, ok := lang.(language.ColorDetector)
+				return filesImplement[language.ColorProvider](lang) || ok && detector.DetectColors()
+			},
+			client: textDocumentClient(func(t *protocol317.TextDocumentClientCapabilities) bool { return t.ColorProvider != nil }),
+			wire: func(handler *protocol317.Handler) {
+				handler.TextDocumentColor = s.textDocumentColor
+				handler.TextDocumentColorPresentation = s.textDocumentColorPresentation
+			},
+			options: func(lang language.LanguageDef, selector protocol.TextDocumentRegistrationOptions) any {
+				return protocol.DocumentColorRegistrationOptions{
+					TextDocumentRegistrationOptions: selector,
+				}
+			},
+			clear: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.ColorProvider = nil
+			},
+		},
+		{
+			method: protocol.MethodTextDocumentPrepareCallHierarchy,
+			has:    filesImplement[language.CallHierarchyProvider],
+			client: textDocumentClient(func(t *protocol317.TextDocumentClientCapabilities) bool { return t.CallHierarchy != nil }),
+			wire: func(handler *protocol317.Handler) {
+				handler.TextDocumentPrepareCallHierarchy = s.textDocumentPrepareCallHierarchy
+				handler.CallHierarchyIncomingCalls = s.callHierarchyIncomingCalls
+				handler.CallHierarchyOutgoingCalls = s.callHierarchyOutgoingCalls
+			},
+			options: func(lang language.LanguageDef, selector protocol.TextDocumentRegistrationOptions) any {
+				return protocol.CallHierarchyRegistrationOptions{
+					TextDocumentRegistrationOptions: selector,
+				}
+			},
+			clear: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.CallHierarchyProvider = nil
+			},
+		},
+		{
+			method: protocol317.MethodTextDocumentPrepareTypeHierarchy,
+			has:    filesImplement[language.TypeHierarchyProvider],
+			client: textDocumentClient(func(t *protocol317.TextDocumentClientCapabilities) bool { return t.TypeHierarchy != nil }),
+			wire: func(handler *protocol317.Handler) {
+				handler.TextDocumentPrepareTypeHierarchy = s.textDocumentPrepareTypeHierarchy
+				handler.TypeHierarchySupertypes = s.typeHierarchySupertypes
+				handler.TypeHierarchySubtypes = s.typeHierarchySubtypes
+			},
+			clear: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.TypeHierarchyProvider = nil
+			},
+		},
+		{
+			method: protocol.MethodTextDocumentLinkedEditingRange,
+			has:    filesImplement[language.LinkedEditingRangeProvider],
+			client: textDocumentClient(func(t *protocol317.TextDocumentClientCapabilities) bool { return t.LinkedEditingRange != nil }),
+			wire: func(handler *protocol317.Handler) {
+				handler.TextDocumentLinkedEditingRange = s.textDocumentLinkedEditingRange
+			},
+			options: func(lang language.LanguageDef, selector protocol.TextDocumentRegistrationOptions) any {
+				return protocol.LinkedEditingRangeRegistrationOptions{
+					TextDocumentRegistrationOptions: selector,
+				}
+			},
+			clear: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.LinkedEditingRangeProvider = nil
+			},
+		},
+		{
+			method: protocol.MethodTextDocumentCodeAction,
+			has:    filesImplement[language.CodeActionProvider],
+			client: textDocumentClient(func(t *protocol317.TextDocumentClientCapabilities) bool { return t.CodeAction != nil }),
+			wire: func(handler *protocol317.Handler) {
+				handler.TextDocumentCodeAction = s.textDocumentCodeAction
+			},
+			options: func(lang language.LanguageDef, selector protocol.TextDocumentRegistrationOptions) any {
+				return protocol.CodeActionRegistrationOptions{
+					TextDocumentRegistrationOptions: selector,
+				}
+			},
+			clear: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.CodeActionProvider = nil
+			},
+		},
+		{
+			method: registration.MethodSemanticTokens,
+			has:    filesImplement[language.SemanticsProvider],
+			client: textDocumentClient(func(t *protocol317.TextDocumentClientCapabilities) bool { return t.SemanticTokens != nil }),
+			wire: func(handler *protocol317.Handler) {
+				handler.TextDocumentSemanticTokensFull = s.textDocumentSemanticTokensFull
+			},
+			advertise: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.SemanticTokensProvider = s.semanticTokensOptions()
+			},
+			options: func(lang language.LanguageDef, selector protocol.TextDocumentRegistrationOptions) any {
+				return protocol.SemanticTokensRegistrationOptions{
+					TextDocumentRegistrationOptions: selector,
+					SemanticTokensOptions:           *s.semanticTokensOptions(),
+				}
+			},
+			clear: func(capabilities *protocol317.ServerCapabilities) {
+				capabilities.SemanticTokensProvider = nil
+			},
+		},
+	}
+}
+
+// anyLanguageHas reports whether any language has the feature.
+func (s *Server) anyLanguageHas(f feature) bool {
+	has := false
+	s.languages.Languages(func(lang language.LanguageDef) bool {
+		has = f.has(lang)
+		return !has
+	})
+	return has
+}
+
+// wireFeatures wires the handlers of the features some language has and the client supports, and records
+// them as enabled. It runs in initialize, before the client can send the requests of the features.
+func (s *Server) wireFeatures() {
+	for _, f := range s.features() {
+		if s.clientSupports(f) && s.anyLanguageHas(f) {
+			s.enable(f)
+		}
+	}
+}
+
+// enable wires the handlers of the feature unless they are already.
+func (s *Server) enable(f feature) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.enabled[f.method] {
+		return
+	}
+	f.wire(&s.handler)
+	s.enabled[f.method] = true
+}
+
+// featureEnabled reports whether the handlers of the feature were wired.
+func (s *Server) featureEnabled(f feature) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enabled[f.method]
+}
+
+// clientSupports reports whether the client sent the capability of the feature in initialize.
+func (s *Server) clientSupports(f feature) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return f.client(&s.clientCapabilities)
+}
+
+// advertise sets the options of the static capabilities of the enabled features. The capabilities of the
+// features that are going to be registered per language are removed, features the client cannot register
+// dynamically stay static.
+func (s *Server) advertise(capabilities *protocol317.ServerCapabilities) {
+	for _, f := range s.features() {
+		switch {
+		case !s.featureEnabled(f):
+		case f.options != nil && s.registrations.Dynamic(f.method):
+			f.clear(capabilities)
+		case f.advertise != nil:
+			f.advertise(capabilities)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/kjbreil/glsp/pkg/hover"
+	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/pkg/location"
+	"github.com/kjbreil/glsp/pkg/symbols"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// hoverFile is a test file with hovers and document symbols.
+type hoverFile struct {
+	*testFile
+}
+
+func (f *hoverFile) Hover(point location.Point) *hover.Hover { return nil }
+func (f *hoverFile) DocumentSymbols() []symbols.Symbol       { return nil }
+
+// hoverLanguage is a test language that declares hoverFile as the type of its files.
+type hoverLanguage struct {
+	testLanguage
+}
+
+func (l *hoverLanguage) FileType() language.File { return (*hoverFile)(nil) }
+
+// lensLanguage is a test language with the id "lens" that declares lensFile as the type of its files.
+type lensLanguage struct {
+	testLanguage
+}
+
+func (l *lensLanguage) ID() string              { return "lens" }
+func (l *lensLanguage) FileType() language.File { return (*lensFile)(nil) }
+
+func TestInitializeAdvertise(t *testing.T) {
+	s := New(WithLanguage(&hoverLanguage{}))
+	// the client has no document symbols, the language has no code lenses
+	capabilities := initializeServer(t, s, `{"textDocument":{"hover":{},"codeLens":{}}}`)
+
+	if capabilities.HoverProvider == nil {
+		t.Errorf("HoverProvider = nil, want it advertised")
+	}
+	if capabilities.DocumentSymbolProvider != nil {
+		t.Errorf("DocumentSymbolProvider = %v for a client without document symbols", capabilities.DocumentSymbolProvider)
+	}
+	if capabilities.WorkspaceSymbolProvider != nil {
+		t.Errorf("WorkspaceSymbolProvider = %v for a client without workspace symbols", capabilities.WorkspaceSymbolProvider)
+	}
+	if capabilities.CodeLensProvider != nil {
+		t.Errorf("CodeLensProvider = %v for a language without code lenses", capabilities.CodeLensProvider)
+	}
+	if capabilities.CompletionProvider != nil || capabilities.FoldingRangeProvider != nil || capabilities.TypeHierarchyProvider != nil {
+		t.Errorf("features the client does not support are advertised: %+v", capabilities)
+	}
+}
+
+func TestInitializeRegister(t *testing.T) {
+	s := New(WithLanguage(&hoverLanguage{}))
+	registered := make(chan []string, 2)
+	s.registrations.SetCaller(func(ctx context.Context, method string, params any, result any) error {
+		var methods []string
+		for _, r := range params.(protocol.RegistrationParams).Registrations {
+			methods = append(methods, r.Method)
+		}
+		registered <- methods
+		return nil
+	})
+	capabilities := initializeServer(t, s, `{"textDocument":{
+		"hover":{"dynamicRegistration":true},
+		"documentSymbol":{"dynamicRegistration":true},
+		"codeLens":{"dynamicRegistration":true}
+	}}`)
+
+	// the features registered per language are not advertised statically
+	if capabilities.HoverProvider != nil || capabilities.DocumentSymbolProvider != nil || capabilities.CodeLensProvider != nil {
+		t.Errorf("dynamic features are advertised statically: %+v", capabilities)
+	}
+
+	receive := func() []string {
+		t.Helper()
+		select {
+		case methods := <-registered:
+			slices.Sort(methods)
+			return methods
+		case <-time.After(time.Second):
+			t.Fatal("no registration")
+			return nil
+		}
+	}
+
+	if err := s.initialized(nil, &protocol.InitializedParams{}); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{protocol.MethodTextDocumentDocumentSymbol, protocol.MethodTextDocumentHover}
+	if got := receive(); !slices.Equal(got, want) {
+		t.Errorf("registered %v, want %v", got, want)
+	}
+
+	// code lenses are registered for a language added later though no language had them in initialize, their
+	// handlers are wired then
+	if s.handler.TextDocumentCodeLens != nil {
+		t.Errorf("code lens handler wired while no language has code lenses")
+	}
+	s.AddLanguage(&lensLanguage{})
+	want = []string{protocol.MethodTextDocumentCodeLens}
+	if got := receive(); !slices.Equal(got, want) {
+		t.Errorf("registered %v for the added language, want %v", got, want)
+	}
+	if s.handler.TextDocumentCodeLens == nil {
+		t.Errorf("code lens handler not wired for the added language")
+	}
+}
+
+func TestInitializePlainLanguage(t *testing.T) {
+	// a language without FileTyper and without completions has none of the optional features, whatever the
+	// client supports
+	s := New(WithLanguage(&testLanguage{}))
+	capabilities := initializeServer(t, s, `{
+		"textDocument":{
+			"completion":{},"hover":{},"signatureHelp":{},"declaration":{},"definition":{},"references":{},
+			"documentHighlight":{},"documentSymbol":{},"codeAction":{},"codeLens":{},"documentLink":{},
+			"colorProvider":{},"formatting":{},"rangeFormatting":{},"onTypeFormatting":{},"rename":{},
+			"foldingRange":{},"selectionRange":{},"linkedEditingRange":{},"callHierarchy":{},
+			"semanticTokens":{"requests":{},"tokenTypes":[],"tokenModifiers":[],"formats":[]},
+			"typeHierarchy":{},"inlayHint":{}
+		},
+		"workspace":{"symbol":{}}
+	}`)
+
+	raw, err := json.Marshal(capabilities)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var advertised map[string]any
+	if err := json.Unmarshal(raw, &advertised); err != nil {
+		t.Fatal(err)
+	}
+	for name := range advertised {
+		if name != "textDocumentSync" && name != "executeCommandProvider" {
+			t.Errorf("%s advertised for a plain language", name)
+		}
+	}
+	if s.handler.TextDocumentHover != nil || s.handler.TextDocumentFoldingRange != nil {
+		t.Errorf("optional handlers are wired for a plain language")
+	}
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"github.com/kjbreil/glsp"
+	"github.com/kjbreil/glsp/pkg/editreader"
+	"github.com/kjbreil/glsp/pkg/folding"
+	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/pkg/location"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// foldingRangeLimit returns the most folding ranges the client takes, 0 when it has no limit.
+func (s *Server) foldingRangeLimit() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	textDocument := s.clientCapabilities.TextDocument
+	if textDocument == nil || textDocument.FoldingRange == nil || textDocument.FoldingRange.RangeLimit == nil {
+		return 0
+	}
+	return int(*textDocument.FoldingRange.RangeLimit)
+}
+
+func (s *Server) textDocumentFoldingRange(ctx *glsp.Context, params *protocol.FoldingRangeParams) ([]protocol.FoldingRange, error) {
+	_, file := s.languages.GetFromUri(params.TextDocument.URI)
+	doc := s.document(params.TextDocument.URI)
+	if file == nil || doc == nil {
+		return nil, nil
+	}
+
+	doc.mu.Lock()
+	var ranges []folding.Range
+	if provider, ok := file.(language.FoldingRangeProvider); ok {
+		ranges = provider.FoldingRanges()
+	} else {
+		ranges = folding.Fallback(doc.fileText(file))
+	}
+	doc.mu.Unlock()
+
+	if limit := s.foldingRangeLimit(); limit > 0 && len(ranges) > limit {
+		ranges = ranges[:limit]
+	}
+	foldingRanges := make([]protocol.FoldingRange, len(ranges))
+	for i, r := range ranges {
+		foldingRanges[i] = r.Protocol()
+	}
+	return foldingRanges, nil
+}
+
+func (s *Server) textDocumentSelectionRange(ctx *glsp.Context, params *protocol.SelectionRangeParams) ([]protocol.SelectionRange, error) {
+	_, file := s.languages.GetFromUri(params.TextDocument.URI)
+	provider, ok := file.(language.SelectionRangeProvider)
+	doc := s.document(params.TextDocument.URI)
+	if !ok || doc == nil {
+		return nil, nil
+	}
+
+	doc.mu.Lock()
+	defer doc.mu.Unlock()
+	selectionRanges := make([]protocol.SelectionRange, len(params.Positions))
+	for i, position := range params.Positions {
+		point := location.Point{Line: int(position.Line), Column: int(position.Character)}
+		selectionRanges[i] = selectionRange(position, provider.SelectionRanges(point))
+	}
+	return selectionRanges, nil
+}
+
+// selectionRange links the ranges from the outermost in, ranges that are not set are skipped. A position
+// without ranges selects nothing.
+func selectionRange(position protocol.Position, ranges []editreader.CharRange) protocol.SelectionRange {
+	var selection *protocol.SelectionRange
+	for i := len(ranges) - 1; i >= 0; i-- {
+		if r := ranges[i].ProtocolRange(); r != nil {
+			selection = &protocol.SelectionRange{Range: *r, Parent: selection}
+		}
+	}
+	if selection == nil {
+		return protocol.SelectionRange{Range: protocol.Range{Start: position, End: position}}
+	}
+	return *selection
+}
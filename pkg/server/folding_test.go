@@ -0,0 +1,63 @@
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kjbreil/glsp/pkg/editreader"
+	"github.com/kjbreil/glsp/pkg/folding"
+	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/pkg/location"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+type foldingFile struct {
+	*testFile
+}
+
+func (f *foldingFile) FoldingRanges() []folding.Range { return nil }
+
+// foldingLanguage is a test language that declares foldingFile as the type of its files.
+type foldingLanguage struct {
+	testLanguage
+}
+
+func (l *foldingLanguage) FileType() language.File { return (*foldingFile)(nil) }
+
+func TestInitializeFoldingRange(t *testing.T) {
+	client := `{"textDocument":{"foldingRange":{}}}`
+
+	s := New(WithLanguage(&testLanguage{}))
+	if got := initializeServer(t, s, client).FoldingRangeProvider; got != nil {
+		t.Errorf("FoldingRangeProvider = %v without a language with folding ranges, want nil", got)
+	}
+
+	s = New(WithLanguage(&foldingLanguage{}))
+	if got := initializeServer(t, s, client).FoldingRangeProvider; got == nil {
+		t.Errorf("FoldingRangeProvider = nil for a language with folding ranges")
+	}
+}
+
+func TestSelectionRange(t *testing.T) {
+	f, err := editreader.New(strings.NewReader("f(a)"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	call := editreader.CharRange{
+		Start: f.CharBefore(location.Point{Line: 0, Column: 1}),
+		End:   f.CharBefore(location.Point{Line: 0, Column: 4}),
+	}
+
+	position := protocol.Position{Character: 2}
+	// ranges that are not set are skipped
+	got := selectionRange(position, []editreader.CharRange{{}, call})
+	want := protocol.Range{End: protocol.Position{Character: 4}}
+	if got.Range != want || got.Parent != nil {
+		t.Errorf("selectionRange() = %v %v, want %v", got.Range, got.Parent, want)
+	}
+
+	got = selectionRange(position, []editreader.CharRange{{}})
+	if got.Range != (protocol.Range{Start: position, End: position}) {
+		t.Errorf("selectionRange(unset) = %v, want the position", got.Range)
+	}
+}
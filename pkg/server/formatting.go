@@ -0,0 +1,113 @@
+package server
+
+import (
+	"slices"
+
+	"github.com/kjbreil/glsp"
+	"github.com/kjbreil/glsp/pkg/format"
+	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/pkg/location"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+func (s *Server) textDocumentFormatting(ctx *glsp.Context, params *protocol.DocumentFormattingParams) ([]protocol.TextEdit, error) {
+	lang, file := s.languages.GetFromUri(params.TextDocument.URI)
+	doc := s.document(params.TextDocument.URI)
+	if file == nil || doc == nil {
+		return nil, nil
+	}
+
+	doc.mu.Lock()
+	defer doc.mu.Unlock()
+	var formatted string
+	switch formatter := lang.Def().(type) {
+	case language.Formatter:
+		text, err := formatter.Format(file, params.Options)
+		if err != nil {
+			return nil, err
+		}
+		formatted = text
+	case language.DocFormatter:
+		d, width, err := formatter.FormatDoc(file)
+		if err != nil {
+			return nil, err
+		}
+		formatted = format.Render(d, width, params.Options)
+	default:
+		return nil, nil
+	}
+	return format.Edits(protocol.Position{}, doc.fileText(file), format.Apply(formatted, params.Options, protectedRanges(lang.Def(), formatted))), nil
+}
+
+func (s *Server) textDocumentRangeFormatting(ctx *glsp.Context, params *protocol.DocumentRangeFormattingParams) ([]protocol.TextEdit, error) {
+	lang, file := s.languages.GetFromUri(params.TextDocument.URI)
+	doc := s.document(params.TextDocument.URI)
+	if file == nil || doc == nil {
+		return nil, nil
+	}
+	formatter, ok := lang.Def().(language.RangeFormatter)
+	if !ok {
+		return nil, nil
+	}
+
+	doc.mu.Lock()
+	defer doc.mu.Unlock()
+	formatted, err := formatter.FormatRange(file, *location.ProtocolRange(&params.Range), params.Options)
+	if err != nil {
+		return nil, err
+	}
+	text := doc.fileText(file)
+	start, end := params.Range.IndexesIn(text)
+	return format.Edits(params.Range.Start, text[start:end], format.ApplyLines(formatted, params.Options, protectedRanges(lang.Def(), formatted))), nil
+}
+
+func (s *Server) textDocumentOnTypeFormatting(ctx *glsp.Context, params *protocol.DocumentOnTypeFormattingParams) ([]protocol.TextEdit, error) {
+	lang, file := s.languages.GetFromUri(params.TextDocument.URI)
+	doc := s.document(params.TextDocument.URI)
+	if file == nil || doc == nil {
+		return nil, nil
+	}
+	formatter, ok := lang.Def().(language.OnTypeFormatter)
+	// the trigger characters are merged for every language, this one may not format on the character
+	if !ok || !slices.Contains(formatter.FormatTriggerCharacters(), params.Ch) {
+		return nil, nil
+	}
+
+	doc.mu.Lock()
+	defer doc.mu.Unlock()
+	point := location.ProtocolPositionPoint(params.TextDocumentPositionParams)
+	formatted, err := formatter.FormatOnType(file, point, params.Ch, params.Options)
+	if err != nil {
+		return nil, err
+	}
+	return format.Edits(protocol.Position{}, doc.fileText(file), format.Apply(formatted, params.Options, protectedRanges(lang.Def(), formatted))), nil
+}
+
+// protectedRanges returns the ranges of the formatted text the options must not change, none when the
+// language does not mark any.
+func protectedRanges(def language.LanguageDef, formatted string) []location.Range {
+	if protector, ok := def.(language.FormatProtector); ok {
+		return protector.Protected(formatted)
+	}
+	return nil
+}
+
+// onTypeFormattingOptions returns the trigger characters of every language that formats on type, it is nil
+// when none does.
+func (s *Server) onTypeFormattingOptions() *protocol.DocumentOnTypeFormattingOptions {
+	var triggers []string
+	s.languages.Languages(func(def language.LanguageDef) bool {
+		if formatter, ok := def.(language.OnTypeFormatter); ok {
+			triggers = appendUnique(triggers, formatter.FormatTriggerCharacters()...)
+		}
+		return true
+	})
+	if len(triggers) == 0 {
+		return nil
+	}
+	slices.Sort(triggers)
+	return &protocol.DocumentOnTypeFormattingOptions{
+		FirstTriggerCharacter: triggers[0],
+		MoreTriggerCharacter:  triggers[1:],
+	}
+}
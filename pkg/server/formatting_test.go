@@ -0,0 +1,63 @@
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/pkg/location"
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// formatLanguage is a test language that formats a file as it is and protects the lines between backquotes.
+type formatLanguage struct {
+	testLanguage
+}
+
+func (l *formatLanguage) Format(file language.File, options protocol.FormattingOptions) (string, error) {
+	return file.(*testFile).Text(), nil
+}
+
+func (l *formatLanguage) Protected(formatted string) []location.Range {
+	var ranges []location.Range
+	var start *location.Point
+	for line, content := range strings.Split(formatted, "\n") {
+		for column, r := range []rune(content) {
+			if r != '`' {
+				continue
+			}
+			p := location.Point{Line: line, Column: column}
+			if start == nil {
+				start = &p
+				continue
+			}
+			ranges = append(ranges, location.Range{Start: *start, End: location.Point{Line: line, Column: column + 1}})
+			start = nil
+		}
+	}
+	return ranges
+}
+
+func TestTextDocumentFormatting(t *testing.T) {
+	s := New(WithLanguage(&formatLanguage{}))
+	initializeServer(t, s, `{"textDocument":{"formatting":{}}}`)
+
+	u := uri.DocumentURI("file:///a.test")
+	openDocument(t, s, u, "x = `\n    raw  \n`\n    y  \n")
+	edits, err := s.textDocumentFormatting(nil, &protocol.DocumentFormattingParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: u},
+		Options:      protocol.FormattingOptions{"insertSpaces": false, "tabSize": float64(4), "trimTrailingWhitespace": true},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// only the line after the raw string is reindented and trimmed
+	want := protocol.TextEdit{
+		Range:   protocol.Range{Start: protocol.Position{Line: 3}, End: protocol.Position{Line: 3, Character: 7}},
+		NewText: "\ty",
+	}
+	if len(edits) != 1 || edits[0] != want {
+		t.Errorf("textDocumentFormatting() = %v, want [%v]", edits, want)
+	}
+}
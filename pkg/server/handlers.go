@@ -5,7 +5,6 @@ import (
 	"github.com/kjbreil/glsp"
 	"github.com/kjbreil/glsp/pkg/language"
 	"github.com/kjbreil/glsp/pkg/location"
-	"github.com/kjbreil/glsp/pkg/problems"
 	"github.com/kjbreil/glsp/pkg/semantic"
 	protocol "github.com/kjbreil/glsp/protocol_3_16"
 	"strings"
@@ -14,35 +13,55 @@ import (
 var (
 	ErrFileNotOpened = errors.New("file not opened")
 	ErrConfigIssue   = errors.New("configuration issue")
+	ErrNotRenamable  = errors.New("nothing to rename at the position")
 )
 
 func (s *Server) textDocumentDidOpen(ctx *glsp.Context, params *protocol.DidOpenTextDocumentParams) error {
-	file, err := s.languages.CreateFile(params.TextDocument.URI, params.TextDocument.LanguageID, strings.NewReader(params.TextDocument.Text))
+	doc := s.openDocument(params.TextDocument.URI, params.TextDocument.Version, params.TextDocument.Text)
+	doc.mu.Lock()
+	_, err := s.languages.CreateFile(params.TextDocument.URI, params.TextDocument.LanguageID, strings.NewReader(params.TextDocument.Text))
+	doc.mu.Unlock()
 	if err != nil {
+		s.closeDocument(params.TextDocument.URI)
 		return err
 	}
-	s.publishDiagnostics(ctx, file, problems.ProblemLevelNone)
+	s.scheduleDiagnostics(ctx, params.TextDocument.URI, 0)
 	return nil
 }
 
 func (s *Server) textDocumentDidChange(ctx *glsp.Context, params *protocol.DidChangeTextDocumentParams) error {
-	_, file := s.languages.GetFromUri(params.TextDocument.URI)
-	if file == nil {
+	lang, file := s.languages.GetFromUri(params.TextDocument.URI)
+	doc := s.document(params.TextDocument.URI)
+	if file == nil || doc == nil {
 		return ErrFileNotOpened
 	}
+
+	doc.mu.Lock()
 	for _, ct := range params.ContentChanges {
 		switch cc := ct.(type) {
 		case protocol.TextDocumentContentChangeEvent:
-
-			file.Replace(cc.Text, location.ProtocolRange(cc.Range))
-
-			s.publishDiagnostics(ctx, file, problems.ProblemLevelNone)
+			if cc.Range == nil {
+				file.Reset(cc.Text)
+			} else {
+				file.Replace(cc.Text, location.ProtocolRange(cc.Range))
+			}
+			doc.replace(cc.Text, cc.Range)
 
 		case protocol.TextDocumentContentChangeEventWhole:
-			return ErrConfigIssue
+			file.Reset(cc.Text)
+			doc.replace(cc.Text, nil)
 		}
-
 	}
+	s.setVersion(doc, params.TextDocument.Version)
+	doc.mu.Unlock()
+
+	s.scheduleDiagnostics(ctx, params.TextDocument.URI, s.diagnosticsDelay)
+	s.scheduleRefresh(ctx, lang.Def(), s.diagnosticsDelay)
+	return nil
+}
+
+func (s *Server) workspaceDidChangeConfiguration(ctx *glsp.Context, params *protocol.DidChangeConfigurationParams) error {
+	s.scheduleRefresh(ctx, nil, 0)
 	return nil
 }
 
@@ -52,11 +71,22 @@ func (s *Server) textDocumentDidSave(ctx *glsp.Context, params *protocol.DidSave
 		return ErrFileNotOpened
 	}
 
-	file.Reset(*params.Text)
+	if params.Text != nil {
+		if doc := s.document(params.TextDocument.URI); doc != nil {
+			doc.mu.Lock()
+			file.Reset(*params.Text)
+			doc.replace(*params.Text, nil)
+			doc.mu.Unlock()
+		} else {
+			file.Reset(*params.Text)
+		}
+	}
 
-	s.publishDiagnostics(ctx, file, problems.ProblemLevelNone)
+	s.scheduleDiagnostics(ctx, params.TextDocument.URI, 0)
 
 	err := lang.On().Save(file)
+	// the save hook can check more than the text, after it every pulled report is computed again
+	s.saved()
 	if err != nil {
 		return err
 	}
@@ -95,15 +125,26 @@ func (s *Server) textDocumentDidSave(ctx *glsp.Context, params *protocol.DidSave
 }
 
 func (s *Server) textDocumentDidClose(ctx *glsp.Context, params *protocol.DidCloseTextDocumentParams) error {
-	return s.languages.DeleteUri(params.TextDocument.URI)
+	doc := s.closeDocument(params.TextDocument.URI)
+	if doc != nil {
+		// a run already computing finishes before the diagnostics are cleared
+		doc.mu.Lock()
+		defer doc.mu.Unlock()
+	}
+	if err := s.languages.DeleteUri(params.TextDocument.URI); err != nil {
+		return err
+	}
+	s.clearDiagnostics(ctx, params.TextDocument.URI)
+	return nil
 }
 
 func (s *Server) textDocumentSemanticTokensFull(ctx *glsp.Context, params *protocol.SemanticTokensParams) (*protocol.SemanticTokens, error) {
 	_, file := s.languages.GetFromUri(params.TextDocument.URI)
-	if file == nil {
+	provider, ok := file.(language.SemanticsProvider)
+	if !ok {
 		return nil, nil
 	}
-	semantics := file.Semantics()
+	semantics := provider.Semantics()
 	tm := semantics.TokenMap()
 	tokens := semantic.TokenMapToProtocol(tm)
 
@@ -113,28 +154,14 @@ func (s *Server) textDocumentSemanticTokensFull(ctx *glsp.Context, params *proto
 	}, nil
 }
 
-func (s *Server) textDocumentCompletion(ctx *glsp.Context, params *protocol.CompletionParams) (any, error) {
-	var completions []protocol.CompletionItem
-
-	s.languages.Languages(func(lang language.LanguageDef) bool {
-		c := lang.Completions()
-		completions = append(completions, c.Protocol()...)
-		return true
-	})
-
-	return protocol.CompletionList{
-		IsIncomplete: true,
-		Items:        completions,
-	}, nil
-}
-
 func (s *Server) textDocumentHover(ctx *glsp.Context, params *protocol.HoverParams) (*protocol.Hover, error) {
 	_, file := s.languages.GetFromUri(params.TextDocument.URI)
-	if file == nil {
+	provider, ok := file.(language.HoverProvider)
+	if !ok {
 		return nil, nil
 	}
 
-	h := file.Hover(location.ProtocolPositionPoint(params.TextDocumentPositionParams))
+	h := provider.Hover(location.ProtocolPositionPoint(params.TextDocumentPositionParams))
 	if h == nil {
 		return nil, nil
 	}
@@ -145,9 +172,10 @@ func (s *Server) textDocumentHover(ctx *glsp.Context, params *protocol.HoverPara
 
 func (s *Server) textDocumentCodeAction(ctx *glsp.Context, params *protocol.CodeActionParams) (any, error) {
 	_, file := s.languages.GetFromUri(params.TextDocument.URI)
-	if file == nil {
+	provider, ok := file.(language.CodeActionProvider)
+	if !ok {
 		return nil, nil
 	}
 
-	return file.CodeActions(location.ProtocolRange(&params.Range))
+	return provider.CodeActions(location.ProtocolRange(&params.Range))
 }
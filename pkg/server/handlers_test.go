@@ -0,0 +1,88 @@
+package server
+
+import (
+	"io"
+	"testing"
+
+	"github.com/kjbreil/glsp"
+	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/pkg/location"
+	"github.com/kjbreil/glsp/pkg/problems"
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// plainFile is a file that does not return its text, the server reads the text it keeps for the document.
+type plainFile struct {
+	uri uri.DocumentURI
+}
+
+func (f *plainFile) Replace(text string, r *location.Range) {}
+func (f *plainFile) Problems() *problems.Problems           { return &problems.Problems{} }
+func (f *plainFile) Uri() uri.DocumentURI                   { return f.uri }
+func (f *plainFile) Path() string                           { return f.uri.Path() }
+func (f *plainFile) Reset(s string)                         {}
+
+func TestTextDocumentDidChange(t *testing.T) {
+	t.Run("text file", func(t *testing.T) {
+		testDidChange(t, &testLanguage{})
+	})
+	t.Run("plain file", func(t *testing.T) {
+		testDidChange(t, &testLanguage{parse: func(u uri.DocumentURI, r io.Reader) (language.File, error) {
+			return &plainFile{uri: u}, nil
+		}})
+	})
+}
+
+func testDidChange(t *testing.T, lang *testLanguage) {
+	s := New(WithLanguage(lang), WithTextDocumentSync(protocol.TextDocumentSyncKindFull))
+	capabilities := initializeServer(t, s, `{}`)
+	if sync := capabilities.TextDocumentSync.(*protocol.TextDocumentSyncOptions); *sync.Change != protocol.TextDocumentSyncKindFull {
+		t.Errorf("TextDocumentSync.Change = %v, want full", *sync.Change)
+	}
+
+	u := uri.DocumentURI("file:///a.test")
+	openDocument(t, s, u, "select a\nfrom b")
+	ctx := &glsp.Context{Notify: func(method string, params any) {}}
+	tests := []struct {
+		name   string
+		change any
+		want   string
+	}{
+		{
+			name: "incremental",
+			change: protocol.TextDocumentContentChangeEvent{
+				Range: &protocol.Range{Start: protocol.Position{Line: 1, Character: 5}, End: protocol.Position{Line: 1, Character: 6}},
+				Text:  "c",
+			},
+			want: "select a\nfrom c",
+		},
+		{
+			name:   "range not set",
+			change: protocol.TextDocumentContentChangeEvent{Text: "update a"},
+			want:   "update a",
+		},
+		{
+			name:   "whole document",
+			change: protocol.TextDocumentContentChangeEventWhole{Text: "delete\nfrom a"},
+			want:   "delete\nfrom a",
+		},
+	}
+	for i, tt := range tests {
+		err := s.textDocumentDidChange(ctx, &protocol.DidChangeTextDocumentParams{
+			TextDocument:   protocol.VersionedTextDocumentIdentifier{TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: u}, Version: protocol.Integer(i + 2)},
+			ContentChanges: []any{tt.change},
+		})
+		if err != nil {
+			t.Fatalf("%s: textDocumentDidChange() error = %v", tt.name, err)
+		}
+		_, file := s.languages.GetFromUri(u)
+		doc := s.document(u)
+		if got := doc.fileText(file); got != tt.want {
+			t.Errorf("%s: text = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if doc := s.document(u); doc.version != 4 {
+		t.Errorf("version = %d, want 4", doc.version)
+	}
+}
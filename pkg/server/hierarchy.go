@@ -0,0 +1,373 @@
+package server
+
+import (
+	"encoding/json"
+	"io/fs"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/kjbreil/glsp"
+	"github.com/kjbreil/glsp/pkg/hierarchy"
+	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/pkg/location"
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+	protocol317 "github.com/kjbreil/glsp/protocol_3_17"
+)
+
+// hierarchyData is sent as the data of a call or type hierarchy item so the item is found again in its file.
+// The start of its selection tells apart items of the file with the same name.
+type hierarchyData struct {
+	Language  string            `json:"language"`
+	Name      string            `json:"name"`
+	Selection protocol.Position `json:"selection"`
+}
+
+func newHierarchyData(lang *language.Language, item *hierarchy.Item) hierarchyData {
+	_, selection, _ := item.Ranges()
+	return hierarchyData{
+		Language:  lang.Def().ID(),
+		Name:      item.Name,
+		Selection: selection.Start,
+	}
+}
+
+// hierarchyItem reads the data the client sent back with an item, lang is nil when the data is not a
+// hierarchyData of a language of the server.
+func (s *Server) hierarchyItem(data any) (*language.Language, hierarchyData) {
+	var d hierarchyData
+	if raw, err := json.Marshal(data); err != nil || json.Unmarshal(raw, &d) != nil || d.Name == "" {
+		return nil, d
+	}
+	return s.languages.Get(d.Language), d
+}
+
+// indexedHierarchy are the callables and types of a file. Open documents are indexed at a version and the
+// files of the workspace at the time they were modified, the entry is rebuilt when either changes.
+type indexedHierarchy struct {
+	doc       *document
+	version   protocol.Integer
+	modTime   time.Time
+	callables []hierarchy.Callable
+	types     []hierarchy.Type
+	// workspace is the entry of the file of the workspace the open document replaced, it is indexed again
+	// when the document is closed
+	workspace *indexedHierarchy
+}
+
+// located is an entry of a hierarchy with the document it is in.
+type located[T any] struct {
+	uri   uri.DocumentURI
+	entry T
+}
+
+// fileHierarchy returns the callables and types of the file, the ones without a range cannot be shown and
+// are left out.
+func fileHierarchy(file language.File) (callables []hierarchy.Callable, types []hierarchy.Type) {
+	if provider, ok := file.(language.CallHierarchyProvider); ok {
+		for _, c := range provider.Callables() {
+			if _, _, ok := c.Ranges(); ok {
+				callables = append(callables, c)
+			}
+		}
+	}
+	if provider, ok := file.(language.TypeHierarchyProvider); ok {
+		for _, t := range provider.Types() {
+			if _, _, ok := t.Ranges(); ok {
+				types = append(types, t)
+			}
+		}
+	}
+	return callables, types
+}
+
+// documentHierarchy returns the callables and types of an open document from the index, the index is
+// updated when the document changed since.
+func (s *Server) documentHierarchy(u uri.DocumentURI, doc *document) indexedHierarchy {
+	s.mu.Lock()
+	entry, ok := s.hierarchyIndex[u]
+	current := ok && entry.doc == doc && entry.version == doc.version
+	s.mu.Unlock()
+	if current {
+		return entry
+	}
+
+	_, file := s.languages.GetFromUri(u)
+	doc.mu.Lock()
+	entry = indexedHierarchy{doc: doc}
+	entry.callables, entry.types = fileHierarchy(file)
+	s.mu.Lock()
+	entry.version = doc.version
+	// the document may have been closed while its entries were collected
+	if s.documents[u] == doc {
+		if previous, ok := s.hierarchyIndex[u]; ok && previous.doc == nil {
+			entry.workspace = &previous
+		} else if ok {
+			entry.workspace = previous.workspace
+		}
+		s.hierarchyIndex[u] = entry
+	}
+	s.mu.Unlock()
+	doc.mu.Unlock()
+	return entry
+}
+
+// workspaceHierarchy returns the callables and types of a file of the workspace that is not open from the
+// index, the file is parsed again when it was modified since.
+func (s *Server) workspaceHierarchy(lang *language.Language, u uri.DocumentURI, fsys fs.FS, name string, dirEntry fs.DirEntry) indexedHierarchy {
+	info, err := dirEntry.Info()
+	if err != nil {
+		return indexedHierarchy{}
+	}
+	s.mu.Lock()
+	entry, ok := s.hierarchyIndex[u]
+	s.mu.Unlock()
+	if ok && entry.doc == nil && entry.modTime.Equal(info.ModTime()) {
+		return entry
+	}
+
+	file := s.parseWorkspaceFile(lang, u, fsys, name)
+	if file == nil {
+		return indexedHierarchy{}
+	}
+	entry = indexedHierarchy{modTime: info.ModTime()}
+	entry.callables, entry.types = fileHierarchy(file)
+	s.mu.Lock()
+	// the file may have been opened while it was parsed
+	if s.documents[u] == nil {
+		s.hierarchyIndex[u] = entry
+	}
+	s.mu.Unlock()
+	return entry
+}
+
+// hierarchyEntries returns the callables and types of the open documents of the language and of the files of
+// the workspace that are not open.
+func (s *Server) hierarchyEntries(lang *language.Language) (callables []located[hierarchy.Callable], types []located[hierarchy.Type]) {
+	add := func(u uri.DocumentURI, entry indexedHierarchy) {
+		for _, c := range entry.callables {
+			callables = append(callables, located[hierarchy.Callable]{uri: u, entry: c})
+		}
+		for _, t := range entry.types {
+			types = append(types, located[hierarchy.Type]{uri: u, entry: t})
+		}
+	}
+
+	s.mu.Lock()
+	documents := make(map[uri.DocumentURI]*document, len(s.documents))
+	for u, doc := range s.documents {
+		documents[u] = doc
+	}
+	s.mu.Unlock()
+	for _, u := range slices.Sorted(maps.Keys(documents)) {
+		if docLang, _ := s.languages.GetFromUri(u); docLang == lang {
+			add(u, s.documentHierarchy(u, documents[u]))
+		}
+	}
+	s.walkWorkspace(lang, func(u uri.DocumentURI, fsys fs.FS, name string, dirEntry fs.DirEntry) {
+		add(u, s.workspaceHierarchy(lang, u, fsys, name, dirEntry))
+	})
+	return callables, types
+}
+
+// named returns the entries called name, only the ones in the file u when it has any.
+func named[T any](entries []located[T], item func(T) *hierarchy.Item, name string, u uri.DocumentURI) []located[T] {
+	var all, local []located[T]
+	for _, e := range entries {
+		if item(e.entry).Name != name {
+			continue
+		}
+		all = append(all, e)
+		if e.uri == u {
+			local = append(local, e)
+		}
+	}
+	if len(local) > 0 {
+		return local
+	}
+	return all
+}
+
+// find returns the entry of the file u the data points to, the first with its name when none starts at the
+// selection of the data.
+func find[T any](entries []located[T], item func(T) *hierarchy.Item, data hierarchyData, u uri.DocumentURI) (located[T], bool) {
+	candidates := named(entries, item, data.Name, u)
+	for _, c := range candidates {
+		if _, selection, _ := item(c.entry).Ranges(); c.uri == u && selection.Start == data.Selection {
+			return c, true
+		}
+	}
+	for _, c := range candidates {
+		if c.uri == u {
+			return c, true
+		}
+	}
+	return located[T]{}, false
+}
+
+// resolves reports whether name resolves to target from the file u, the way named picks the entries of a
+// name.
+func resolves[T any](entries []located[T], item func(T) *hierarchy.Item, name string, u uri.DocumentURI, target located[T]) bool {
+	_, want, _ := item(target.entry).Ranges()
+	return slices.ContainsFunc(named(entries, item, name, u), func(e located[T]) bool {
+		_, selection, _ := item(e.entry).Ranges()
+		return e.uri == target.uri && item(e.entry).Name == item(target.entry).Name && selection.Start == want.Start
+	})
+}
+
+func callableItem(c hierarchy.Callable) *hierarchy.Item { return &c.Item }
+
+func typeItem(t hierarchy.Type) *hierarchy.Item { return &t.Item }
+
+// mergeCalls joins the calls of the same callable, the callables keep the order of their first call.
+func mergeCalls(calls []hierarchy.Call) []hierarchy.Call {
+	var merged []hierarchy.Call
+	for _, call := range calls {
+		i := slices.IndexFunc(merged, func(c hierarchy.Call) bool { return c.Name == call.Name })
+		if i < 0 {
+			merged = append(merged, hierarchy.Call{Name: call.Name, Ranges: slices.Clone(call.Ranges)})
+		} else {
+			merged[i].Ranges = append(merged[i].Ranges, call.Ranges...)
+		}
+	}
+	return merged
+}
+
+func (s *Server) textDocumentPrepareCallHierarchy(ctx *glsp.Context, params *protocol.CallHierarchyPrepareParams) ([]protocol.CallHierarchyItem, error) {
+	lang, file := s.languages.GetFromUri(params.TextDocument.URI)
+	provider, ok := file.(language.CallHierarchyProvider)
+	if !ok {
+		return nil, nil
+	}
+	name := provider.CallableAt(location.ProtocolPositionPoint(params.TextDocumentPositionParams))
+	if name == "" {
+		return nil, nil
+	}
+
+	callables, _ := s.hierarchyEntries(lang)
+	var items []protocol.CallHierarchyItem
+	for _, c := range named(callables, callableItem, name, params.TextDocument.URI) {
+		if item, ok := c.entry.CallItem(c.uri, newHierarchyData(lang, &c.entry.Item)); ok {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func (s *Server) callHierarchyIncomingCalls(ctx *glsp.Context, params *protocol.CallHierarchyIncomingCallsParams) ([]protocol.CallHierarchyIncomingCall, error) {
+	lang, data := s.hierarchyItem(params.Item.Data)
+	if lang == nil {
+		return nil, nil
+	}
+	callables, _ := s.hierarchyEntries(lang)
+	callee, ok := find(callables, callableItem, data, params.Item.URI)
+	if !ok {
+		return nil, nil
+	}
+
+	var calls []protocol.CallHierarchyIncomingCall
+	for _, caller := range callables {
+		for _, call := range mergeCalls(caller.entry.Calls) {
+			// the call is resolved like the outgoing calls of the caller, a callable of its own file first
+			if !resolves(callables, callableItem, call.Name, caller.uri, callee) {
+				continue
+			}
+			if from, ok := caller.entry.CallItem(caller.uri, newHierarchyData(lang, &caller.entry.Item)); ok {
+				calls = append(calls, protocol.CallHierarchyIncomingCall{From: from, FromRanges: call.ProtocolRanges()})
+			}
+		}
+	}
+	return calls, nil
+}
+
+func (s *Server) callHierarchyOutgoingCalls(ctx *glsp.Context, params *protocol.CallHierarchyOutgoingCallsParams) ([]protocol.CallHierarchyOutgoingCall, error) {
+	lang, data := s.hierarchyItem(params.Item.Data)
+	if lang == nil {
+		return nil, nil
+	}
+	callables, _ := s.hierarchyEntries(lang)
+	caller, ok := find(callables, callableItem, data, params.Item.URI)
+	if !ok {
+		return nil, nil
+	}
+
+	var calls []protocol.CallHierarchyOutgoingCall
+	for _, call := range mergeCalls(caller.entry.Calls) {
+		for _, callee := range named(callables, callableItem, call.Name, caller.uri) {
+			if to, ok := callee.entry.CallItem(callee.uri, newHierarchyData(lang, &callee.entry.Item)); ok {
+				calls = append(calls, protocol.CallHierarchyOutgoingCall{To: to, FromRanges: call.ProtocolRanges()})
+			}
+		}
+	}
+	return calls, nil
+}
+
+func (s *Server) textDocumentPrepareTypeHierarchy(ctx *glsp.Context, params *protocol317.TypeHierarchyPrepareParams) ([]protocol317.TypeHierarchyItem, error) {
+	lang, file := s.languages.GetFromUri(params.TextDocument.URI)
+	provider, ok := file.(language.TypeHierarchyProvider)
+	if !ok {
+		return nil, nil
+	}
+	name := provider.TypeAt(location.ProtocolPositionPoint(params.TextDocumentPositionParams))
+	if name == "" {
+		return nil, nil
+	}
+
+	_, types := s.hierarchyEntries(lang)
+	var items []protocol317.TypeHierarchyItem
+	for _, t := range named(types, typeItem, name, params.TextDocument.URI) {
+		if item, ok := t.entry.TypeItem(t.uri, newHierarchyData(lang, &t.entry.Item)); ok {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
+
+func (s *Server) typeHierarchySupertypes(ctx *glsp.Context, params *protocol317.TypeHierarchySupertypesParams) ([]protocol317.TypeHierarchyItem, error) {
+	lang, data := s.hierarchyItem(params.Item.Data)
+	if lang == nil {
+		return nil, nil
+	}
+	_, types := s.hierarchyEntries(lang)
+	sub, ok := find(types, typeItem, data, params.Item.URI)
+	if !ok {
+		return nil, nil
+	}
+
+	var items []protocol317.TypeHierarchyItem
+	for _, name := range sub.entry.Supertypes {
+		for _, t := range named(types, typeItem, name, sub.uri) {
+			if item, ok := t.entry.TypeItem(t.uri, newHierarchyData(lang, &t.entry.Item)); ok {
+				items = append(items, item)
+			}
+		}
+	}
+	return items, nil
+}
+
+func (s *Server) typeHierarchySubtypes(ctx *glsp.Context, params *protocol317.TypeHierarchySubtypesParams) ([]protocol317.TypeHierarchyItem, error) {
+	lang, data := s.hierarchyItem(params.Item.Data)
+	if lang == nil {
+		return nil, nil
+	}
+	_, types := s.hierarchyEntries(lang)
+	super, ok := find(types, typeItem, data, params.Item.URI)
+	if !ok {
+		return nil, nil
+	}
+
+	var items []protocol317.TypeHierarchyItem
+	for _, t := range types {
+		// the supertypes are resolved like typeHierarchy/supertypes does, a type of its own file first
+		if !slices.ContainsFunc(t.entry.Supertypes, func(name string) bool {
+			return resolves(types, typeItem, name, t.uri, super)
+		}) {
+			continue
+		}
+		if item, ok := t.entry.TypeItem(t.uri, newHierarchyData(lang, &t.entry.Item)); ok {
+			items = append(items, item)
+		}
+	}
+	return items, nil
+}
@@ -0,0 +1,219 @@
+package server
+
+import (
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+
+	"github.com/kjbreil/glsp"
+	"github.com/kjbreil/glsp/pkg/editreader"
+	"github.com/kjbreil/glsp/pkg/hierarchy"
+	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/pkg/location"
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+	protocol317 "github.com/kjbreil/glsp/protocol_3_17"
+)
+
+// hierarchyFile is a test file whose lines are "name: a b", the callable and type name that calls a and b
+// and extends them.
+type hierarchyFile struct {
+	*testFile
+	collected *atomic.Int32
+}
+
+func (f *hierarchyFile) wordRange(line, column int, word string) editreader.CharRange {
+	return editreader.CharRange{
+		Start: f.reader.CharBefore(location.Point{Line: line, Column: column + 1}),
+		End:   f.reader.CharBefore(location.Point{Line: line, Column: column + len(word)}),
+	}
+}
+
+func (f *hierarchyFile) entries() (items []hierarchy.Item, references [][]hierarchy.Call) {
+	f.collected.Add(1)
+	for line, content := range strings.Split(f.Text(), "\n") {
+		name, rest, ok := strings.Cut(content, ":")
+		if !ok {
+			continue
+		}
+		r := f.wordRange(line, 0, name)
+		items = append(items, hierarchy.Item{Name: name, Kind: protocol.SymbolKindFunction, Range: r, Selection: r})
+		var calls []hierarchy.Call
+		column := len(name) + 1
+		for _, word := range strings.Split(rest, " ") {
+			if word != "" {
+				calls = append(calls, hierarchy.Call{Name: word, Ranges: []editreader.CharRange{f.wordRange(line, column, word)}})
+			}
+			column += len(word) + 1
+		}
+		references = append(references, calls)
+	}
+	return items, references
+}
+
+func (f *hierarchyFile) CallableAt(p location.Point) string { return f.nameAt(p) }
+func (f *hierarchyFile) TypeAt(p location.Point) string     { return f.nameAt(p) }
+
+func (f *hierarchyFile) nameAt(p location.Point) string {
+	lines := strings.Split(f.Text(), "\n")
+	name, _, _ := strings.Cut(lines[p.Line], ":")
+	return name
+}
+
+func (f *hierarchyFile) Callables() []hierarchy.Callable {
+	items, references := f.entries()
+	callables := make([]hierarchy.Callable, len(items))
+	for i := range items {
+		callables[i] = hierarchy.Callable{Item: items[i], Calls: references[i]}
+	}
+	return callables
+}
+
+func (f *hierarchyFile) Types() []hierarchy.Type {
+	items, references := f.entries()
+	types := make([]hierarchy.Type, len(items))
+	for i := range items {
+		types[i] = hierarchy.Type{Item: items[i]}
+		for _, c := range references[i] {
+			types[i].Supertypes = append(types[i].Supertypes, c.Name)
+		}
+	}
+	return types
+}
+
+// hierarchyLanguage is a test language whose files are also read from the workspace.
+type hierarchyLanguage struct {
+	testLanguage
+}
+
+func (l *hierarchyLanguage) Extensions() []string { return []string{".test"} }
+
+func newHierarchyServer(t *testing.T, collected *atomic.Int32) *Server {
+	t.Helper()
+	lang := &hierarchyLanguage{testLanguage{parse: func(u uri.DocumentURI, r io.Reader) (language.File, error) {
+		f, err := newTestFile(u, r)
+		return &hierarchyFile{testFile: f, collected: collected}, err
+	}}}
+	workspace := fstest.MapFS{
+		"c.test": {Data: []byte("third: helper")},
+	}
+	s := New(WithLanguage(lang), WithWorkspace("/ws", workspace))
+	initializeServer(t, s, `{"textDocument":{"callHierarchy":{},"typeHierarchy":{}}}`)
+	// a and b both have a helper, the callers in them call their own
+	openDocument(t, s, "file:///ws/a.test", "main: helper\nhelper:")
+	openDocument(t, s, "file:///ws/b.test", "helper:\nother: helper")
+	return s
+}
+
+func TestCallHierarchy(t *testing.T) {
+	var collected atomic.Int32
+	s := newHierarchyServer(t, &collected)
+
+	items, err := s.textDocumentPrepareCallHierarchy(nil, &protocol.CallHierarchyPrepareParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: "file:///ws/a.test"},
+			Position:     protocol.Position{Line: 1},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].Name != "helper" || items[0].URI != "file:///ws/a.test" {
+		t.Fatalf("textDocumentPrepareCallHierarchy() = %v, want the helper of a", items)
+	}
+
+	incoming, err := s.callHierarchyIncomingCalls(nil, &protocol.CallHierarchyIncomingCallsParams{Item: items[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// other calls the helper of b, third has no helper of its own and calls both
+	var callers []string
+	for _, call := range incoming {
+		callers = append(callers, string(call.From.URI)+" "+call.From.Name)
+	}
+	want := []string{"file:///ws/a.test main", "file:///ws/c.test third"}
+	if strings.Join(callers, ",") != strings.Join(want, ",") {
+		t.Errorf("callHierarchyIncomingCalls() callers = %v, want %v", callers, want)
+	}
+	wantRange := protocol.Range{Start: protocol.Position{Character: 6}, End: protocol.Position{Character: 12}}
+	if len(incoming) > 0 && (len(incoming[0].FromRanges) != 1 || incoming[0].FromRanges[0] != wantRange) {
+		t.Errorf("FromRanges = %v, want [%v]", incoming[0].FromRanges, wantRange)
+	}
+
+	outgoing, err := s.callHierarchyOutgoingCalls(nil, &protocol.CallHierarchyOutgoingCallsParams{Item: incoming[0].From})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outgoing) != 1 || outgoing[0].To.URI != "file:///ws/a.test" || outgoing[0].To.Name != "helper" {
+		t.Errorf("callHierarchyOutgoingCalls() = %v, want the helper of a", outgoing)
+	}
+
+	// the callables and types of the open documents and the workspace file were collected once, later
+	// requests use the index
+	if got := collected.Load(); got != 6 {
+		t.Errorf("files collected %d times, want 6", got)
+	}
+}
+
+func TestTypeHierarchy(t *testing.T) {
+	var collected atomic.Int32
+	s := newHierarchyServer(t, &collected)
+
+	items, err := s.textDocumentPrepareTypeHierarchy(nil, &protocol317.TypeHierarchyPrepareParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: "file:///ws/b.test"},
+			Position:     protocol.Position{Line: 0},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(items) != 1 || items[0].URI != "file:///ws/b.test" {
+		t.Fatalf("textDocumentPrepareTypeHierarchy() = %v, want the helper of b", items)
+	}
+
+	subtypes, err := s.typeHierarchySubtypes(nil, &protocol317.TypeHierarchySubtypesParams{Item: items[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, item := range subtypes {
+		names = append(names, string(item.URI)+" "+item.Name)
+	}
+	want := []string{"file:///ws/b.test other", "file:///ws/c.test third"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("typeHierarchySubtypes() = %v, want %v", names, want)
+	}
+
+	supertypes, err := s.typeHierarchySupertypes(nil, &protocol317.TypeHierarchySupertypesParams{Item: subtypes[0]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(supertypes) != 1 || supertypes[0].URI != "file:///ws/b.test" || supertypes[0].Name != "helper" {
+		t.Errorf("typeHierarchySupertypes() = %v, want the helper of b", supertypes)
+	}
+}
+
+func TestHierarchyIndexClose(t *testing.T) {
+	var collected atomic.Int32
+	s := newHierarchyServer(t, &collected)
+	lang := s.languages.Get("test")
+	s.hierarchyEntries(lang)
+
+	// c is indexed from the workspace, opening it indexes the document and closing it brings back the entry
+	// of the workspace file
+	openDocument(t, s, "file:///ws/c.test", "third: helper")
+	s.hierarchyEntries(lang)
+	if err := s.textDocumentDidClose(&glsp.Context{Notify: func(method string, params any) {}}, &protocol.DidCloseTextDocumentParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: "file:///ws/c.test"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	before := collected.Load()
+	s.hierarchyEntries(lang)
+	if got := collected.Load() - before; got != 0 {
+		t.Errorf("collected %d times after c was closed, want the workspace entry reused", got)
+	}
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"github.com/kjbreil/glsp"
+	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/pkg/location"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+func (s *Server) textDocumentLinkedEditingRange(ctx *glsp.Context, params *protocol.LinkedEditingRangeParams) (*protocol.LinkedEditingRanges, error) {
+	_, file := s.languages.GetFromUri(params.TextDocument.URI)
+	provider, ok := file.(language.LinkedEditingRangeProvider)
+	doc := s.document(params.TextDocument.URI)
+	if !ok || doc == nil {
+		return nil, nil
+	}
+
+	doc.mu.Lock()
+	ranges, wordPattern := provider.LinkedEditingRanges(location.ProtocolPositionPoint(params.TextDocumentPositionParams))
+	doc.mu.Unlock()
+
+	linked := &protocol.LinkedEditingRanges{Ranges: make([]protocol.Range, 0, len(ranges))}
+	for _, r := range ranges {
+		// ranges that are not set cannot be edited
+		if pr := r.ProtocolRange(); pr != nil {
+			linked.Ranges = append(linked.Ranges, *pr)
+		}
+	}
+	if len(linked.Ranges) == 0 {
+		return nil, nil
+	}
+	if wordPattern != "" {
+		linked.WordPattern = &wordPattern
+	}
+	return linked, nil
+}
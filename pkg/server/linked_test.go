@@ -0,0 +1,50 @@
+package server
+
+import (
+	"io"
+	"testing"
+
+	"github.com/kjbreil/glsp/pkg/editreader"
+	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/pkg/location"
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// linkedFile is a test file whose first word is linked with a range that is not set.
+type linkedFile struct {
+	*testFile
+}
+
+func (f *linkedFile) LinkedEditingRanges(p location.Point) ([]editreader.CharRange, string) {
+	return []editreader.CharRange{
+		{
+			Start: f.reader.CharBefore(location.Point{Line: 0, Column: 1}),
+			End:   f.reader.CharBefore(location.Point{Line: 0, Column: 3}),
+		},
+		{},
+	}, ""
+}
+
+func TestTextDocumentLinkedEditingRange(t *testing.T) {
+	lang := &testLanguage{parse: func(u uri.DocumentURI, r io.Reader) (language.File, error) {
+		f, err := newTestFile(u, r)
+		return &linkedFile{f}, err
+	}}
+	s := New(WithLanguage(lang))
+	initializeServer(t, s, `{"textDocument":{"linkedEditingRange":{}}}`)
+
+	u := uri.DocumentURI("file:///a.test")
+	openDocument(t, s, u, "div x")
+	linked, err := s.textDocumentLinkedEditingRange(nil, &protocol.LinkedEditingRangeParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{TextDocument: protocol.TextDocumentIdentifier{URI: u}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the range that is not set is left out
+	want := protocol.Range{End: protocol.Position{Character: 3}}
+	if linked == nil || len(linked.Ranges) != 1 || linked.Ranges[0] != want {
+		t.Errorf("textDocumentLinkedEditingRange() = %v, want [%v]", linked, want)
+	}
+}
@@ -0,0 +1,143 @@
+package server
+
+import (
+	"slices"
+
+	"github.com/kjbreil/glsp"
+	"github.com/kjbreil/glsp/pkg/color"
+	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/pkg/links"
+	"github.com/kjbreil/glsp/pkg/location"
+	"github.com/kjbreil/glsp/pkg/semantic"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// documentLinkResolveProvider reports whether any language resolves document links.
+func (s *Server) documentLinkResolveProvider() bool {
+	resolve := false
+	s.languages.Languages(func(def language.LanguageDef) bool {
+		_, resolve = def.(language.DocumentLinkResolver)
+		return !resolve
+	})
+	return resolve
+}
+
+func (s *Server) textDocumentDocumentLink(ctx *glsp.Context, params *protocol.DocumentLinkParams) ([]protocol.DocumentLink, error) {
+	lang, file := s.languages.GetFromUri(params.TextDocument.URI)
+	doc := s.document(params.TextDocument.URI)
+	if file == nil || doc == nil {
+		return nil, nil
+	}
+	_, resolve := lang.Def().(language.DocumentLinkResolver)
+
+	doc.mu.Lock()
+	defer doc.mu.Unlock()
+	var found []links.Link
+	if provider, ok := file.(language.DocumentLinkProvider); ok {
+		found = provider.DocumentLinks()
+	} else if provider, ok := file.(language.SemanticsProvider); ok {
+		found = links.Detect(doc.fileText(file), params.TextDocument.URI, commentsAndStrings(provider.Semantics()))
+	}
+	if !resolve {
+		// a link without a target can only be followed once it is resolved
+		found = slices.DeleteFunc(found, func(l links.Link) bool { return l.Target == "" })
+	}
+	list := doc.links.set(found)
+
+	result := make([]protocol.DocumentLink, len(found))
+	for i := range found {
+		result[i] = found[i].Protocol(resolveData{URI: params.TextDocument.URI, List: list, Index: i})
+	}
+	return result, nil
+}
+
+func (s *Server) documentLinkResolve(ctx *glsp.Context, params *protocol.DocumentLink) (*protocol.DocumentLink, error) {
+	data, ok := decodeResolveData(params.Data)
+	if !ok {
+		return params, nil
+	}
+
+	lang, file := s.languages.GetFromUri(data.URI)
+	doc := s.document(data.URI)
+	if file == nil || doc == nil {
+		return params, nil
+	}
+	resolver, ok := lang.Def().(language.DocumentLinkResolver)
+	if !ok {
+		return params, nil
+	}
+
+	doc.mu.Lock()
+	defer doc.mu.Unlock()
+	link := doc.links.at(data)
+	if link == nil {
+		return params, nil
+	}
+	*link = resolver.ResolveDocumentLink(file, *link)
+	resolved := link.Protocol(params.Data)
+	return &resolved, nil
+}
+
+// commentsAndStrings returns the ranges of the comment and string tokens, links are only detected in them.
+func commentsAndStrings(semantics *semantic.Semantics) []location.Range {
+	regions := []location.Range{}
+	if semantics == nil {
+		return regions
+	}
+	for _, token := range semantics.Slice() {
+		if token.Location != nil && (token.Token == semantic.TokenComment || token.Token == semantic.TokenString) {
+			regions = append(regions, *token.Location)
+		}
+	}
+	return regions
+}
+
+func (s *Server) textDocumentColor(ctx *glsp.Context, params *protocol.DocumentColorParams) ([]protocol.ColorInformation, error) {
+	lang, file := s.languages.GetFromUri(params.TextDocument.URI)
+	doc := s.document(params.TextDocument.URI)
+	if file == nil || doc == nil {
+		return nil, nil
+	}
+
+	doc.mu.Lock()
+	var colors []color.Color
+	if provider, ok := file.(language.ColorProvider); ok {
+		colors = provider.Colors()
+	} else if detector, ok := lang.Def().(language.ColorDetector); ok && detector.DetectColors() {
+		colors = color.Detect(doc.fileText(file))
+	}
+	doc.mu.Unlock()
+
+	result := make([]protocol.ColorInformation, len(colors))
+	for i := range colors {
+		result[i] = colors[i].Protocol()
+	}
+	return result, nil
+}
+
+func (s *Server) textDocumentColorPresentation(ctx *glsp.Context, params *protocol.ColorPresentationParams) ([]protocol.ColorPresentation, error) {
+	_, file := s.languages.GetFromUri(params.TextDocument.URI)
+	doc := s.document(params.TextDocument.URI)
+	if file == nil || doc == nil {
+		return nil, nil
+	}
+
+	doc.mu.Lock()
+	text := doc.fileText(file)
+	doc.mu.Unlock()
+	start, end := params.Range.IndexesIn(text)
+	current := ""
+	if start <= end {
+		current = text[start:end]
+	}
+
+	labels := color.Presentations(params.Color, current)
+	presentations := make([]protocol.ColorPresentation, len(labels))
+	for i, label := range labels {
+		presentations[i] = protocol.ColorPresentation{
+			Label:    label,
+			TextEdit: &protocol.TextEdit{Range: params.Range, NewText: label},
+		}
+	}
+	return presentations, nil
+}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/pkg/links"
+	"github.com/kjbreil/glsp/pkg/location"
+	"github.com/kjbreil/glsp/pkg/semantic"
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// linkFile is a test file with a link to b and a link to resolve.
+type linkFile struct {
+	*testFile
+}
+
+func (f *linkFile) DocumentLinks() []links.Link {
+	return []links.Link{
+		{Range: location.Range{End: location.Point{Column: 4}}},
+		{Range: location.Range{Start: location.Point{Column: 5}, End: location.Point{Column: 9}}, Target: "file:///b"},
+	}
+}
+
+// linkLanguage is a test language whose files are linkFiles, the links without a target are resolved to c.
+type linkLanguage struct {
+	testLanguage
+}
+
+func (l *linkLanguage) FileType() language.File { return (*linkFile)(nil) }
+func (l *linkLanguage) ResolveDocumentLink(file language.File, link links.Link) links.Link {
+	link.Target = "file:///c"
+	return link
+}
+
+// commentFile is a test file that is a comment, links are detected in all of it.
+type commentFile struct {
+	*testFile
+}
+
+func (f *commentFile) Semantics() *semantic.Semantics {
+	return semantic.New(semantic.Semantic{
+		Location: &location.Range{End: location.Point{Column: len(f.Text())}},
+		Token:    semantic.TokenComment,
+	})
+}
+
+// commentLanguage is a test language whose files are commentFiles, colors are detected in them.
+type commentLanguage struct {
+	testLanguage
+}
+
+func (l *commentLanguage) FileType() language.File { return (*commentFile)(nil) }
+func (l *commentLanguage) DetectColors() bool      { return true }
+
+func newCommentLanguage() *commentLanguage {
+	return &commentLanguage{testLanguage{parse: func(u uri.DocumentURI, r io.Reader) (language.File, error) {
+		f, err := newTestFile(u, r)
+		return &commentFile{testFile: f}, err
+	}}}
+}
+
+func TestTextDocumentDocumentLinkResolve(t *testing.T) {
+	s := New(WithLanguage(&linkLanguage{testLanguage{parse: func(u uri.DocumentURI, r io.Reader) (language.File, error) {
+		f, err := newTestFile(u, r)
+		return &linkFile{testFile: f}, err
+	}}}))
+	capabilities := initializeServer(t, s, `{"textDocument":{"documentLink":{}}}`)
+	if capabilities.DocumentLinkProvider == nil || !*capabilities.DocumentLinkProvider.ResolveProvider {
+		t.Fatalf("DocumentLinkProvider = %v, want it to resolve", capabilities.DocumentLinkProvider)
+	}
+
+	u := uri.DocumentURI("file:///a.test")
+	openDocument(t, s, u, "link link")
+	found, err := s.textDocumentDocumentLink(nil, &protocol.DocumentLinkParams{TextDocument: protocol.TextDocumentIdentifier{URI: u}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 2 || found[0].Target != nil || *found[1].Target != "file:///b" {
+		t.Fatalf("links = %v, want one to resolve and one to b", found)
+	}
+
+	resolved, err := s.documentLinkResolve(nil, &found[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resolved.Target == nil || *resolved.Target != "file:///c" {
+		t.Errorf("resolved target = %v, want file:///c", resolved.Target)
+	}
+}
+
+func TestTextDocumentDocumentLinkRelative(t *testing.T) {
+	s := New(WithLanguage(newCommentLanguage()))
+	initializeServer(t, s, `{"textDocument":{"documentLink":{}}}`)
+
+	u := uri.DocumentURI("file:///src/app/main.test")
+	openDocument(t, s, u, "see ../docs/readme.md and lib/util.go")
+	found, err := s.textDocumentDocumentLink(nil, &protocol.DocumentLinkParams{TextDocument: protocol.TextDocumentIdentifier{URI: u}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// paths are resolved against the directory of the document
+	var targets []uri.DocumentURI
+	for _, link := range found {
+		targets = append(targets, *link.Target)
+	}
+	want := []uri.DocumentURI{"file:///src/docs/readme.md", "file:///src/app/lib/util.go"}
+	if !reflect.DeepEqual(targets, want) {
+		t.Errorf("link targets = %v, want %v", targets, want)
+	}
+}
+
+func TestTextDocumentColor(t *testing.T) {
+	s := New(WithLanguage(newCommentLanguage()))
+	capabilities := initializeServer(t, s, `{"textDocument":{"colorProvider":{}}}`)
+	if capabilities.ColorProvider == nil {
+		t.Fatal("ColorProvider = nil, want it advertised")
+	}
+
+	u := uri.DocumentURI("file:///a.test")
+	openDocument(t, s, u, "fill #FF8800")
+	colors, err := s.textDocumentColor(nil, &protocol.DocumentColorParams{TextDocument: protocol.TextDocumentIdentifier{URI: u}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantRange := protocol.Range{Start: protocol.Position{Character: 5}, End: protocol.Position{Character: 12}}
+	if len(colors) != 1 || colors[0].Range != wantRange || colors[0].Color.Red != 1 || colors[0].Color.Blue != 0 {
+		t.Fatalf("colors = %v, want #FF8800 at %v", colors, wantRange)
+	}
+
+	// the presentations keep the upper case hex notation of the document first
+	presentations, err := s.textDocumentColorPresentation(nil, &protocol.ColorPresentationParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: u},
+		Color:        colors[0].Color,
+		Range:        colors[0].Range,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []protocol.ColorPresentation{
+		{Label: "#FF8800", TextEdit: &protocol.TextEdit{Range: wantRange, NewText: "#FF8800"}},
+		{Label: "rgb(255, 136, 0)", TextEdit: &protocol.TextEdit{Range: wantRange, NewText: "rgb(255, 136, 0)"}},
+	}
+	if !reflect.DeepEqual(presentations, want) {
+		t.Errorf("presentations = %v, want %v", presentations, want)
+	}
+}
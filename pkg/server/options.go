@@ -1,8 +1,12 @@
 package server
 
 import (
+	"github.com/kjbreil/glsp/pkg/completion"
 	"github.com/kjbreil/glsp/pkg/language"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+	"io/fs"
 	"log/slog"
+	"time"
 )
 
 func WithLogger(logger *slog.Logger) func(*Server) {
@@ -23,8 +27,57 @@ func WithLanguage(lang language.LanguageDef) func(*Server) {
 	}
 }
 
+// WithTextDocumentSync sets the sync kind advertised to the client. Whole document and incremental changes
+// are both accepted whatever the kind.
+func WithTextDocumentSync(kind protocol.TextDocumentSyncKind) func(*Server) {
+	return func(s *Server) {
+		s.syncKind = kind
+	}
+}
+
+// WithDiagnosticsDelay sets how long edits to a document are coalesced before its diagnostics are published.
+func WithDiagnosticsDelay(delay time.Duration) func(*Server) {
+	return func(s *Server) {
+		s.diagnosticsDelay = delay
+	}
+}
+
+// WithCompletionEngine filters the static completions of the languages by the word typed at the cursor and
+// sends at most limit of them, completion.DefaultLimit when limit is 0.
+func WithCompletionEngine(limit int) func(*Server) {
+	return func(s *Server) {
+		if limit <= 0 {
+			limit = completion.DefaultLimit
+		}
+		s.completionLimit = limit
+	}
+}
+
+// WithWorkspace sets the file system the files that are not open are read from, root is the path of its top
+// directory. Without it the root folder of the client is read.
+func WithWorkspace(root string, fsys fs.FS) func(*Server) {
+	return func(s *Server) {
+		s.workspaceRoot = root
+		s.workspace = fsys
+	}
+}
+
 func WithServerName(name string) func(*Server) {
 	return func(s *Server) {
 		s.languageServerName = name
 	}
 }
+
+// WithStrictParams rejects requests whose parameters have unknown fields or miss required fields. Without
+// methods strict validation applies to every method.
+func WithStrictParams(methods ...string) func(*Server) {
+	return func(s *Server) {
+		if len(methods) == 0 {
+			s.handler.Strict = true
+			return
+		}
+		for _, method := range methods {
+			s.handler.SetStrict(method, true)
+		}
+	}
+}
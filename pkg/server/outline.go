@@ -0,0 +1,129 @@
+package server
+
+import (
+	"sort"
+
+	"github.com/kjbreil/glsp"
+	"github.com/kjbreil/glsp/pkg/completion"
+	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/pkg/symbols"
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// maxWorkspaceSymbols caps the symbols returned for a workspace/symbol query.
+const maxWorkspaceSymbols = 500
+
+// indexedSymbols are the flattened symbols of a document at a version, they are rebuilt when it changes.
+type indexedSymbols struct {
+	doc     *document
+	version protocol.Integer
+	symbols []protocol.SymbolInformation
+}
+
+// hierarchicalDocumentSymbols reports whether the client takes document symbols as a tree.
+func (s *Server) hierarchicalDocumentSymbols() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	textDocument := s.clientCapabilities.TextDocument
+	if textDocument == nil || textDocument.DocumentSymbol == nil {
+		return false
+	}
+	support := textDocument.DocumentSymbol.HierarchicalDocumentSymbolSupport
+	return support != nil && *support
+}
+
+func (s *Server) textDocumentDocumentSymbol(ctx *glsp.Context, params *protocol.DocumentSymbolParams) (any, error) {
+	_, file := s.languages.GetFromUri(params.TextDocument.URI)
+	provider, ok := file.(language.DocumentSymbolProvider)
+	doc := s.document(params.TextDocument.URI)
+	if !ok || doc == nil {
+		return nil, nil
+	}
+
+	doc.mu.Lock()
+	defer doc.mu.Unlock()
+	outline := provider.DocumentSymbols()
+
+	if !s.hierarchicalDocumentSymbols() {
+		return symbols.Flatten(file.Uri(), outline), nil
+	}
+	documentSymbols := make([]protocol.DocumentSymbol, 0, len(outline))
+	for i := range outline {
+		if symbol, ok := outline[i].Protocol(); ok {
+			documentSymbols = append(documentSymbols, symbol)
+		}
+	}
+	return documentSymbols, nil
+}
+
+// documentSymbols returns the flattened symbols of an open document from the index, the index is updated
+// when the document changed since.
+func (s *Server) documentSymbols(u uri.DocumentURI, doc *document) []protocol.SymbolInformation {
+	s.mu.Lock()
+	entry, ok := s.symbolIndex[u]
+	current := ok && entry.doc == doc && entry.version == doc.version
+	s.mu.Unlock()
+	if current {
+		return entry.symbols
+	}
+
+	_, file := s.languages.GetFromUri(u)
+	provider, ok := file.(language.DocumentSymbolProvider)
+	if !ok {
+		return nil
+	}
+
+	doc.mu.Lock()
+	flat := symbols.Flatten(u, provider.DocumentSymbols())
+	s.mu.Lock()
+	version := doc.version
+	// the document may have been closed while its symbols were collected
+	if s.documents[u] == doc {
+		s.symbolIndex[u] = indexedSymbols{doc: doc, version: version, symbols: flat}
+	}
+	s.mu.Unlock()
+	doc.mu.Unlock()
+	return flat
+}
+
+func (s *Server) workspaceSymbol(ctx *glsp.Context, params *protocol.WorkspaceSymbolParams) ([]protocol.SymbolInformation, error) {
+	s.mu.Lock()
+	documents := make(map[uri.DocumentURI]*document, len(s.documents))
+	for u, doc := range s.documents {
+		documents[u] = doc
+	}
+	s.mu.Unlock()
+
+	type scored struct {
+		symbol protocol.SymbolInformation
+		score  int
+	}
+	var matches []scored
+	for u, doc := range documents {
+		for _, symbol := range s.documentSymbols(u, doc) {
+			if score, ok := completion.Match(params.Query, symbol.Name); ok {
+				matches = append(matches, scored{symbol: symbol, score: score})
+			}
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		if matches[i].symbol.Name != matches[j].symbol.Name {
+			return matches[i].symbol.Name < matches[j].symbol.Name
+		}
+		return matches[i].symbol.Location.URI < matches[j].symbol.Location.URI
+	})
+	if len(matches) > maxWorkspaceSymbols {
+		matches = matches[:maxWorkspaceSymbols]
+	}
+
+	result := make([]protocol.SymbolInformation, len(matches))
+	for i, m := range matches {
+		result[i] = m.symbol
+	}
+	return result, nil
+}
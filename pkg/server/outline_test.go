@@ -0,0 +1,80 @@
+package server
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+func TestTextDocumentDocumentSymbol(t *testing.T) {
+	a := uri.DocumentURI("file:///a.test")
+	for _, hierarchical := range []bool{false, true} {
+		s := newSymbolServer(t, fmt.Sprintf(`{"textDocument":{"documentSymbol":{"hierarchicalDocumentSymbolSupport":%v}}}`, hierarchical),
+			map[uri.DocumentURI]string{a: "helper:\nmain: helper"})
+
+		result, err := s.textDocumentDocumentSymbol(nil, &protocol.DocumentSymbolParams{TextDocument: protocol.TextDocumentIdentifier{URI: a}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		switch result := result.(type) {
+		case []protocol.DocumentSymbol:
+			if !hierarchical {
+				t.Errorf("document symbols are a tree for a client without hierarchical support")
+			}
+			for _, symbol := range result {
+				names = append(names, symbol.Name)
+			}
+		case []protocol.SymbolInformation:
+			if hierarchical {
+				t.Errorf("document symbols are flat for a client with hierarchical support")
+			}
+			for _, symbol := range result {
+				names = append(names, symbol.Name)
+			}
+		}
+		if want := []string{"helper", "main"}; !slices.Equal(names, want) {
+			t.Errorf("hierarchical %v: symbols = %v, want %v", hierarchical, names, want)
+		}
+	}
+}
+
+func TestWorkspaceSymbol(t *testing.T) {
+	var many strings.Builder
+	for i := range maxWorkspaceSymbols + 100 {
+		fmt.Fprintf(&many, "item%d:\n", i)
+	}
+	s := newSymbolServer(t, `{}`, map[uri.DocumentURI]string{
+		"file:///a.test": "helper:\nmain: helper",
+		"file:///b.test": "hello:",
+		"file:///c.test": many.String(),
+	})
+
+	query := func(q string) []string {
+		t.Helper()
+		result, err := s.workspaceSymbol(nil, &protocol.WorkspaceSymbolParams{Query: q})
+		if err != nil {
+			t.Fatal(err)
+		}
+		names := make([]string, len(result))
+		for i, symbol := range result {
+			names[i] = symbol.Name
+		}
+		return names
+	}
+
+	// the query matches fuzzily across the open documents
+	if got, want := query("hlp"), []string{"helper"}; !slices.Equal(got, want) {
+		t.Errorf("query hlp = %v, want %v", got, want)
+	}
+	if got, want := query("he"), []string{"hello", "helper"}; !slices.Equal(got, want) {
+		t.Errorf("query he = %v, want %v", got, want)
+	}
+	if got := query(""); len(got) != maxWorkspaceSymbols {
+		t.Errorf("empty query returned %d symbols, want the cap of %d", len(got), maxWorkspaceSymbols)
+	}
+}
@@ -0,0 +1,16 @@
+package server
+
+import (
+	"github.com/kjbreil/glsp/pkg/language"
+)
+
+// filesImplement reports whether the files of the language implement the interface T. It is false for a
+// language that does not implement language.FileTyper.
+func filesImplement[T any](lang language.LanguageDef) bool {
+	typer, ok := lang.(language.FileTyper)
+	if !ok {
+		return false
+	}
+	_, ok = typer.FileType().(T)
+	return ok
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"github.com/kjbreil/glsp/internal/helpers"
+	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/pkg/registration"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+func (s *Server) registerLanguages() {
+	s.languages.Languages(func(lang language.LanguageDef) bool {
+		s.registerLanguage(lang)
+		return true
+	})
+}
+
+// registerLanguage registers the dynamic features the language has and the client supports with a document
+// selector for its id. The handlers of a feature no language had in initialize are wired first.
+func (s *Server) registerLanguage(lang language.LanguageDef) {
+	selector := protocol.TextDocumentRegistrationOptions{
+		DocumentSelector: &protocol.DocumentSelector{
+			{Language: helpers.Ptr(lang.ID())},
+		},
+	}
+
+	var registrations []protocol.Registration
+	for _, feature := range s.features() {
+		if feature.options == nil || !s.clientSupports(feature) || !s.registrations.Dynamic(feature.method) ||
+			!feature.has(lang) {
+			continue
+		}
+		s.enable(feature)
+		registrations = append(registrations, protocol.Registration{
+			Method:          feature.method,
+			RegisterOptions: feature.options(lang, selector),
+		})
+	}
+	if len(registrations) == 0 {
+		return
+	}
+
+	ids, err := s.registrations.RegisterMany(s.ctx, registrations...)
+	if err != nil {
+		s.logger.Error("could not register language capabilities", "language", lang.ID(), "err", err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.languageRegistrations[lang.ID()] = append(s.languageRegistrations[lang.ID()], ids...)
+}
+
+// AddLanguage adds a language to a running server, when the client is already initialized the features of
+// the language are registered with the client and the handlers of features no language had in initialize are
+// wired for them. Features the client does not register dynamically are only offered when a language had
+// them in initialize.
+func (s *Server) AddLanguage(lang language.LanguageDef) {
+	s.languages.AddLanguage(lang)
+
+	s.mu.Lock()
+	initialized := s.clientReady
+	s.mu.Unlock()
+
+	if initialized {
+		go s.registerLanguage(lang)
+	}
+}
+
+// Registrations returns the manager used for dynamic capability registration, it can be used to register
+// file watchers or other capabilities once the client is initialized.
+func (s *Server) Registrations() *registration.Manager {
+	return s.registrations
+}
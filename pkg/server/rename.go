@@ -0,0 +1,227 @@
+package server
+
+import (
+	"sort"
+
+	"github.com/kjbreil/glsp"
+	"github.com/kjbreil/glsp/internal/helpers"
+	"github.com/kjbreil/glsp/pkg/editreader"
+	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/pkg/location"
+	"github.com/kjbreil/glsp/pkg/symbols"
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// documentChangesSupport reports whether the client takes versioned document changes in a workspace edit.
+func (s *Server) documentChangesSupport() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	workspace := s.clientCapabilities.Workspace
+	if workspace == nil || workspace.WorkspaceEdit == nil {
+		return false
+	}
+	support := workspace.WorkspaceEdit.DocumentChanges
+	return support != nil && *support
+}
+
+// workspaceEdit returns the edits as versioned document changes when the client supports them, documents that
+// are not open have no version.
+func (s *Server) workspaceEdit(changes map[uri.DocumentURI][]protocol.TextEdit) *protocol.WorkspaceEdit {
+	if !s.documentChangesSupport() {
+		return &protocol.WorkspaceEdit{Changes: changes}
+	}
+
+	uris := make([]uri.DocumentURI, 0, len(changes))
+	for u := range changes {
+		uris = append(uris, u)
+	}
+	sort.Slice(uris, func(i, j int) bool { return uris[i] < uris[j] })
+
+	documentChanges := make([]any, 0, len(uris))
+	for _, u := range uris {
+		var version *protocol.Integer
+		s.mu.Lock()
+		if doc := s.documents[u]; doc != nil {
+			version = helpers.Ptr(doc.version)
+		}
+		s.mu.Unlock()
+
+		edits := make([]any, len(changes[u]))
+		for i, edit := range changes[u] {
+			edits[i] = edit
+		}
+		documentChanges = append(documentChanges, protocol.TextDocumentEdit{
+			TextDocument: protocol.OptionalVersionedTextDocumentIdentifier{
+				TextDocumentIdentifier: protocol.TextDocumentIdentifier{URI: u},
+				Version:                version,
+			},
+			Edits: edits,
+		})
+	}
+	return &protocol.WorkspaceEdit{DocumentChanges: documentChanges}
+}
+
+// nameAt returns the range and the name of the symbol whose name is at the point.
+func nameAt(syms *symbols.Symbols, p location.Point) (editreader.CharRange, string, bool) {
+	definition, reference := syms.At(p)
+	switch {
+	case reference != nil:
+		return reference.Range, reference.Name, true
+	case definition == nil:
+		return editreader.CharRange{}, "", false
+	}
+	if definition.Declaration != nil {
+		if r := definition.Declaration.Range(); r != nil && r.Contains(p) {
+			return *definition.Declaration, definition.Name, true
+		}
+	}
+	return definition.Selection, definition.Name, true
+}
+
+func (s *Server) textDocumentPrepareRename(ctx *glsp.Context, params *protocol.PrepareRenameParams) (any, error) {
+	_, file := s.languages.GetFromUri(params.TextDocument.URI)
+	point := location.ProtocolPositionPoint(params.TextDocumentPositionParams)
+
+	var (
+		r           editreader.CharRange
+		placeholder string
+		ok          bool
+	)
+	switch provider := file.(type) {
+	case language.RenameProvider:
+		r, placeholder, ok = provider.PrepareRename(point)
+	case language.SymbolProvider:
+		r, placeholder, ok = nameAt(provider.Symbols(), point)
+	}
+	pr := r.ProtocolRange()
+	if !ok || pr == nil {
+		return nil, nil
+	}
+	return protocol.RangeWithPlaceholder{
+		Range:       *pr,
+		Placeholder: placeholder,
+	}, nil
+}
+
+// renameEdits collects the edits of a rename per document, a range is edited once.
+type renameEdits struct {
+	newName string
+	changes map[uri.DocumentURI][]protocol.TextEdit
+	seen    map[uri.DocumentURI]map[protocol.Range]bool
+}
+
+func (e *renameEdits) add(u uri.DocumentURI, r editreader.CharRange) {
+	rp := r.ProtocolRange()
+	if rp == nil {
+		return
+	}
+	pr := *rp
+	if e.seen[u] == nil {
+		e.seen[u] = make(map[protocol.Range]bool)
+	}
+	if e.seen[u][pr] {
+		return
+	}
+	e.seen[u][pr] = true
+	e.changes[u] = append(e.changes[u], protocol.TextEdit{Range: pr, NewText: e.newName})
+}
+
+// definition adds the name of the definition at its definition and declaration.
+func (e *renameEdits) definition(file language.File, d *symbols.Definition) {
+	e.add(file.Uri(), d.Selection)
+	if d.Declaration != nil {
+		e.add(file.Uri(), *d.Declaration)
+	}
+}
+
+// references adds the references in the file that resolve to a renamed definition. A reference without a
+// definition resolves by name, it is renamed when byName is set.
+func (e *renameEdits) references(file language.File, renamed map[*symbols.Definition]bool, name string, byName bool) {
+	if provider, ok := file.(language.SymbolProvider); ok {
+		for _, ref := range provider.Symbols().References() {
+			if renamed[ref.Definition] || byName && ref.Definition == nil && ref.Name == name {
+				e.add(file.Uri(), ref.Range)
+			}
+		}
+	}
+}
+
+func (s *Server) textDocumentRename(ctx *glsp.Context, params *protocol.RenameParams) (*protocol.WorkspaceEdit, error) {
+	lang, file := s.languages.GetFromUri(params.TextDocument.URI)
+	if file == nil {
+		return nil, ErrFileNotOpened
+	}
+	if validator, ok := lang.Def().(language.RenameValidator); ok {
+		if err := validator.ValidName(params.NewName); err != nil {
+			return nil, err
+		}
+	}
+
+	point := location.ProtocolPositionPoint(params.TextDocumentPositionParams)
+	edits := &renameEdits{
+		newName: params.NewName,
+		changes: make(map[uri.DocumentURI][]protocol.TextEdit),
+		seen:    make(map[uri.DocumentURI]map[protocol.Range]bool),
+	}
+
+	provider, isSymbolProvider := file.(language.SymbolProvider)
+	if renamer, ok := file.(language.RenameProvider); ok {
+		r, _, ok := renamer.PrepareRename(point)
+		if !ok {
+			return nil, ErrNotRenamable
+		}
+		if !isSymbolProvider {
+			// without symbols only the name at the point is known
+			edits.add(file.Uri(), r)
+			return s.workspaceEdit(edits.changes), nil
+		}
+	}
+	if !isSymbolProvider {
+		return nil, ErrNotRenamable
+	}
+
+	_, reference := provider.Symbols().At(point)
+	_, name, definitions := s.symbolAt(params.TextDocumentPositionParams)
+	// a symbol that is not defined in any open file is not known well enough to rename
+	if len(definitions) == 0 {
+		return nil, ErrNotRenamable
+	}
+	// a reference without a definition in its file renames every definition with the name, the files that
+	// are not open included
+	byName := reference != nil && reference.Definition == nil
+
+	renamed := make(map[*symbols.Definition]bool, len(definitions))
+	for _, d := range definitions {
+		renamed[d.definition] = true
+		edits.definition(d.file, d.definition)
+	}
+	files := s.workspaceFiles(lang)
+	if byName {
+		for _, f := range files {
+			if provider, ok := f.(language.SymbolProvider); ok {
+				if d := provider.Symbols().Lookup(name); d != nil {
+					renamed[d] = true
+					edits.definition(f, d)
+				}
+			}
+		}
+	}
+
+	// references without a definition resolve to every definition with the name in the open files, they are
+	// renamed only when all of those are
+	renameUnresolved := true
+	lang.Files(func(f language.File) bool {
+		files = append(files, f)
+		if provider, ok := f.(language.SymbolProvider); ok {
+			if d := provider.Symbols().Lookup(name); d != nil && !renamed[d] {
+				renameUnresolved = false
+			}
+		}
+		return true
+	})
+	for _, f := range files {
+		edits.references(f, renamed, name, renameUnresolved)
+	}
+	return s.workspaceEdit(edits.changes), nil
+}
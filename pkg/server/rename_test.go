@@ -0,0 +1,153 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+
+	"github.com/kjbreil/glsp/internal/helpers"
+	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// newRenameServer opens a.test and b.test of a workspace that also has w.test and own.test. The helper of a
+// is used by b and w, own.test has a helper of its own.
+func newRenameServer(t *testing.T, capabilities string, parsed *atomic.Int32) *Server {
+	t.Helper()
+	lang := &hierarchyLanguage{testLanguage{parse: func(u uri.DocumentURI, r io.Reader) (language.File, error) {
+		parsed.Add(1)
+		f, err := newTestFile(u, r)
+		return &symbolFile{testFile: f}, err
+	}}}
+	workspace := fstest.MapFS{
+		"w.test":   {Data: []byte("third: helper")},
+		"own.test": {Data: []byte("helper:\nfourth: helper")},
+	}
+	s := New(WithLanguage(lang), WithWorkspace("/ws", workspace))
+	initializeServer(t, s, capabilities)
+	openDocument(t, s, "file:///ws/a.test", "helper:\nmain: helper\nunknown")
+	openDocument(t, s, "file:///ws/b.test", "other: helper")
+	return s
+}
+
+func TestTextDocumentPrepareRename(t *testing.T) {
+	var parsed atomic.Int32
+	s := newRenameServer(t, `{}`, &parsed)
+
+	tests := []struct {
+		name   string
+		params protocol.TextDocumentPositionParams
+		want   any
+	}{
+		{"definition", at("file:///ws/a.test", 0, 3), protocol.RangeWithPlaceholder{Range: wordLocation("", 0, 0, 6).Range, Placeholder: "helper"}},
+		{"reference", at("file:///ws/b.test", 0, 8), protocol.RangeWithPlaceholder{Range: wordLocation("", 0, 7, 13).Range, Placeholder: "helper"}},
+		{"no symbol", at("file:///ws/a.test", 3, 0), nil},
+	}
+	for _, tt := range tests {
+		got, err := s.textDocumentPrepareRename(nil, &protocol.PrepareRenameParams{TextDocumentPositionParams: tt.params})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: prepareRename = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTextDocumentRename(t *testing.T) {
+	var parsed atomic.Int32
+	s := newRenameServer(t, `{}`, &parsed)
+	rename := func(params protocol.TextDocumentPositionParams) (map[uri.DocumentURI][]protocol.TextEdit, error) {
+		t.Helper()
+		edit, err := s.textDocumentRename(nil, &protocol.RenameParams{TextDocumentPositionParams: params, NewName: "util"})
+		if err != nil {
+			return nil, err
+		}
+		for _, edits := range edit.Changes {
+			slices.SortFunc(edits, func(x, y protocol.TextEdit) int { return int(x.Range.Start.Line) - int(y.Range.Start.Line) })
+		}
+		return edit.Changes, nil
+	}
+	edit := func(line, start, end int) protocol.TextEdit {
+		return protocol.TextEdit{Range: wordLocation("", line, start, end).Range, NewText: "util"}
+	}
+
+	// the helper of a is renamed in a, b and w, the helper of own.test is another symbol
+	want := map[uri.DocumentURI][]protocol.TextEdit{
+		"file:///ws/a.test": {edit(0, 0, 6), edit(1, 6, 12)},
+		"file:///ws/b.test": {edit(0, 7, 13)},
+		"file:///ws/w.test": {edit(0, 7, 13)},
+	}
+	for _, params := range []protocol.TextDocumentPositionParams{at("file:///ws/a.test", 0, 0), at("file:///ws/a.test", 1, 7)} {
+		got, err := rename(params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("rename at %v = %v, want %v", params.Position, got, want)
+		}
+	}
+
+	// the workspace files are parsed once and then read from the index
+	if got := parsed.Load(); got != 4 {
+		t.Errorf("parsed %d files, want 4", got)
+	}
+
+	// a symbol without a definition is not renamed
+	if _, err := rename(at("file:///ws/a.test", 2, 3)); !errors.Is(err, ErrNotRenamable) {
+		t.Errorf("rename of an undefined symbol error = %v, want %v", err, ErrNotRenamable)
+	}
+}
+
+func TestTextDocumentRenameAmbiguous(t *testing.T) {
+	var parsed atomic.Int32
+	s := newRenameServer(t, `{}`, &parsed)
+	// with a second helper open, the reference of b could be to either and is left alone
+	openDocument(t, s, "file:///ws/c.test", "helper:")
+
+	edit, err := s.textDocumentRename(nil, &protocol.RenameParams{TextDocumentPositionParams: at("file:///ws/a.test", 0, 0), NewName: "util"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []uri.DocumentURI
+	for u := range edit.Changes {
+		got = append(got, u)
+	}
+	if want := []uri.DocumentURI{"file:///ws/a.test"}; !slices.Equal(got, want) {
+		t.Errorf("renamed in %v, want %v", got, want)
+	}
+}
+
+func TestTextDocumentRenameDocumentChanges(t *testing.T) {
+	var parsed atomic.Int32
+	s := newRenameServer(t, `{"workspace":{"workspaceEdit":{"documentChanges":true}}}`, &parsed)
+	ctx, _ := publishRecorder()
+	change(t, s, ctx, "file:///ws/b.test", 5, "other: helper")
+
+	edit, err := s.textDocumentRename(nil, &protocol.RenameParams{TextDocumentPositionParams: at("file:///ws/a.test", 0, 0), NewName: "util"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edit.Changes != nil {
+		t.Errorf("Changes = %v, want document changes only", edit.Changes)
+	}
+	// open documents are edited at their version, the files of the workspace have none
+	want := map[uri.DocumentURI]*protocol.Integer{
+		"file:///ws/a.test": helpers.Ptr(protocol.Integer(1)),
+		"file:///ws/b.test": helpers.Ptr(protocol.Integer(5)),
+		"file:///ws/w.test": nil,
+	}
+	got := make(map[uri.DocumentURI]*protocol.Integer)
+	for _, change := range edit.DocumentChanges {
+		documentEdit := change.(protocol.TextDocumentEdit)
+		got[documentEdit.TextDocument.URI] = documentEdit.TextDocument.Version
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("document versions = %v, want %v", got, want)
+	}
+}
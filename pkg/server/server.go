@@ -4,17 +4,31 @@ import (
 	"context"
 	"github.com/kjbreil/glsp"
 	"github.com/kjbreil/glsp/internal/helpers"
+	"github.com/kjbreil/glsp/pkg/completion"
 	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/pkg/registration"
 	"github.com/kjbreil/glsp/pkg/semantic"
+	"github.com/kjbreil/glsp/pkg/uri"
 	protocol "github.com/kjbreil/glsp/protocol_3_16"
+	protocol317 "github.com/kjbreil/glsp/protocol_3_17"
 	glspserv "github.com/kjbreil/glsp/server"
 	"github.com/sourcegraph/jsonrpc2"
+	"io/fs"
 	"log/slog"
+	"sync"
+	"time"
 )
 
 type Server struct {
-	languages *language.Languages
-	handler   protocol.Handler
+	languages     *language.Languages
+	handler       protocol317.Handler
+	registrations *registration.Manager
+	// languageRegistrations holds the ids of the dynamic registrations made for each language
+	languageRegistrations map[string][]string
+	clientReady           bool
+	// clientCapabilities decides which 3.17 features are offered, 3.16 clients get the 3.16 equivalent
+	clientCapabilities protocol317.ClientCapabilities
+	mu                 sync.Mutex
 
 	logger *slog.Logger
 
@@ -22,6 +36,39 @@ type Server struct {
 	server             *glspserv.Server
 	serverType         ServerType
 	ctx                context.Context
+
+	// syncKind is the text document sync kind advertised to the client, changes of either kind are accepted
+	syncKind protocol.TextDocumentSyncKind
+
+	// documents holds the version and diagnostics run of every open document
+	documents map[uri.DocumentURI]*document
+	// generation counts the changes to the open documents, it is part of the pull diagnostics result ids
+	generation       uint64
+	diagnosticsDelay time.Duration
+
+	// completions is the last completion list, its items are resolved on completionItem/resolve
+	completions servedCompletions
+	// completionEngines filter the static completions of each language when completionLimit is set
+	completionEngines map[string]*completion.Engine
+	completionLimit   int
+
+	// symbolIndex holds the symbols of the open documents for workspace/symbol
+	symbolIndex map[uri.DocumentURI]indexedSymbols
+	// hierarchyIndex holds the callables and types of the open documents and the workspace files for the
+	// call and type hierarchies
+	hierarchyIndex map[uri.DocumentURI]indexedHierarchy
+	// workspaceIndex holds the parsed files of the workspace that are not open
+	workspaceIndex map[uri.DocumentURI]indexedFile
+
+	// workspace is read for the files that are not open, workspaceRoot is the path it is rooted at
+	workspace     fs.FS
+	workspaceRoot string
+
+	// refresh is the pending request for the client to refresh code lenses and inlay hints
+	refresh pendingRefresh
+
+	// enabled holds the methods of the features whose handlers are wired
+	enabled map[string]bool
 }
 
 type ServerType int
@@ -33,10 +80,20 @@ const (
 
 func New(opts ...func(server *Server)) *Server {
 	s := &Server{
-		languageServerName: "generic_lsp",
-		languages:          language.NewLanguages(),
-		logger:             slog.Default(),
-		serverType:         ServerTypeStdio,
+		languageServerName:    "generic_lsp",
+		languages:             language.NewLanguages(),
+		registrations:         registration.New(),
+		languageRegistrations: make(map[string][]string),
+		logger:                slog.Default(),
+		serverType:            ServerTypeStdio,
+		syncKind:              protocol.TextDocumentSyncKindIncremental,
+		documents:             make(map[uri.DocumentURI]*document),
+		diagnosticsDelay:      DefaultDiagnosticsDelay,
+		completionEngines:     make(map[string]*completion.Engine),
+		symbolIndex:           make(map[uri.DocumentURI]indexedSymbols),
+		hierarchyIndex:        make(map[uri.DocumentURI]indexedHierarchy),
+		workspaceIndex:        make(map[uri.DocumentURI]indexedFile),
+		enabled:               make(map[string]bool),
 	}
 	for _, opt := range opts {
 		opt(s)
@@ -55,11 +112,9 @@ func (s *Server) Run(ctx context.Context) error {
 	s.handler.TextDocumentDidChange = s.textDocumentDidChange
 	s.handler.TextDocumentDidSave = s.textDocumentDidSave
 	s.handler.TextDocumentDidClose = s.textDocumentDidClose
-	s.handler.TextDocumentSemanticTokensFull = s.textDocumentSemanticTokensFull
-	s.handler.TextDocumentCompletion = s.textDocumentCompletion
-	s.handler.TextDocumentHover = s.textDocumentHover
-	//s.handler.TextDocumentDefinition = s.textDocumentDefinition
-	s.handler.TextDocumentCodeAction = s.textDocumentCodeAction
+	s.handler.WorkspaceDidChangeConfiguration = s.workspaceDidChangeConfiguration
+	s.handler.TextDocumentDiagnostic = s.textDocumentDiagnostic
+	s.handler.WorkspaceDiagnostic = s.workspaceDiagnostic
 	s.handler.WorkspaceExecuteCommand = s.languages.CommandsExecute
 
 	s.server = glspserv.NewServer(
@@ -68,6 +123,7 @@ func (s *Server) Run(ctx context.Context) error {
 		false,
 		func(conn *jsonrpc2.Conn) {
 			s.languages.SetConn(conn)
+			s.registrations.SetConn(conn)
 		},
 	)
 
@@ -94,10 +150,20 @@ func (s *Server) Run(ctx context.Context) error {
 }
 
 func (s *Server) initialized(ctx *glsp.Context, params *protocol.InitializedParams) error {
+	s.mu.Lock()
+	s.clientReady = true
+	s.mu.Unlock()
+
+	// requests to the client cannot be made from inside a handler, the response would never be read
+	go s.registerLanguages()
 	return nil
 }
 
 func (s *Server) shutdown(ctx *glsp.Context) error {
+	s.mu.Lock()
+	s.clientReady = false
+	s.mu.Unlock()
+	s.registrations.Reset()
 	protocol.SetTraceValue(protocol.TraceValueOff)
 	return nil
 }
@@ -107,32 +173,50 @@ func (s *Server) setTrace(ctx *glsp.Context, params *protocol.SetTraceParams) er
 	return nil
 }
 
-func (s *Server) initialize(ctx *glsp.Context, params *protocol.InitializeParams) (any, error) {
+func (s *Server) initialize(ctx *glsp.Context, params *protocol317.InitializeParams) (any, error) {
+	s.mu.Lock()
+	s.clientCapabilities = params.Capabilities
+	s.mu.Unlock()
+	s.setWorkspace(params)
+
+	capabilities316 := params.Capabilities.Protocol316()
+	s.registrations.SetClientCapabilities(&capabilities316)
+	// the 3.16 capabilities do not have inlay hints, their dynamic registration is set on its own
+	inlayHintDynamic := false
+	if t := params.Capabilities.TextDocument; t != nil && t.InlayHint != nil {
+		inlayHintDynamic = t.InlayHint.DynamicRegistration != nil && *t.InlayHint.DynamicRegistration
+	}
+	s.registrations.SetDynamic(protocol317.MethodTextDocumentInlayHint, inlayHintDynamic)
+	// the optional handlers are wired before the capabilities are created from them
+	s.wireFeatures()
+
 	capabilities := s.handler.CreateServerCapabilities()
-	capabilities.SemanticTokensProvider = &protocol.SemanticTokensOptions{
-		Legend: protocol.SemanticTokensLegend{
-			TokenTypes:     semantic.Tokens(),
-			TokenModifiers: nil,
-		},
-		Range: nil,
-		Full:  helpers.Ptr(true),
+	if !params.Capabilities.PullDiagnostics() {
+		// diagnostics are pushed with textDocument/publishDiagnostics instead
+		capabilities.DiagnosticProvider = nil
 	}
 	capabilities.ExecuteCommandProvider = s.languages.CommandProvider()
 	capabilities.TextDocumentSync = &protocol.TextDocumentSyncOptions{
 		OpenClose: helpers.Ptr(true),
-		Change:    helpers.Ptr(protocol.TextDocumentSyncKindIncremental),
+		Change:    helpers.Ptr(s.syncKind),
 		Save:      &protocol.SaveOptions{IncludeText: helpers.Ptr(true)},
 		// WillSaveWaitUntil: ptr(true),
 	}
-	capabilities.CompletionProvider = &protocol.CompletionOptions{
-		WorkDoneProgressOptions: protocol.WorkDoneProgressOptions{},
-		TriggerCharacters:       []string{"@", "®"},
-		AllCommitCharacters:     nil,
-		ResolveProvider:         nil,
-	}
+	s.advertise(&capabilities)
 
-	return protocol.InitializeResult{
+	return protocol317.InitializeResult{
 		Capabilities: capabilities,
 		ServerInfo:   &protocol.InitializeResultServerInfo{Name: s.languageServerName},
 	}, nil
 }
+
+func (s *Server) semanticTokensOptions() *protocol.SemanticTokensOptions {
+	return &protocol.SemanticTokensOptions{
+		Legend: protocol.SemanticTokensLegend{
+			TokenTypes:     semantic.Tokens(),
+			TokenModifiers: nil,
+		},
+		Range: nil,
+		Full:  helpers.Ptr(true),
+	}
+}
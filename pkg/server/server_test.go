@@ -0,0 +1,92 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/kjbreil/glsp"
+	"github.com/kjbreil/glsp/pkg/commands"
+	"github.com/kjbreil/glsp/pkg/completion"
+	"github.com/kjbreil/glsp/pkg/editreader"
+	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/pkg/location"
+	"github.com/kjbreil/glsp/pkg/problems"
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+	protocol317 "github.com/kjbreil/glsp/protocol_3_17"
+)
+
+// testFile is a file of testLanguage, it only keeps its text.
+type testFile struct {
+	uri      uri.DocumentURI
+	reader   *editreader.File
+	problems problems.Problems
+}
+
+func newTestFile(u uri.DocumentURI, r io.Reader) (*testFile, error) {
+	reader, err := editreader.New(r)
+	if err != nil {
+		return nil, err
+	}
+	return &testFile{uri: u, reader: reader}, nil
+}
+
+func (f *testFile) Replace(text string, r *location.Range) { f.reader.Replace(text, r) }
+func (f *testFile) Text() string                           { return f.reader.String() }
+func (f *testFile) Problems() *problems.Problems           { return &f.problems }
+func (f *testFile) Uri() uri.DocumentURI                   { return f.uri }
+func (f *testFile) Path() string                           { return f.uri.Path() }
+func (f *testFile) Reset(s string) {
+	f.reader, _ = editreader.New(strings.NewReader(s))
+}
+
+// testLanguage is a language with the id "test" whose files are parsed by parse.
+type testLanguage struct {
+	parse       func(u uri.DocumentURI, r io.Reader) (language.File, error)
+	completions completion.Completions
+	options     language.CompletionOptions
+}
+
+func (l *testLanguage) Init(functions *language.LanguageFunctions) {}
+func (l *testLanguage) Parse(u uri.DocumentURI, r io.Reader) (language.File, error) {
+	if l.parse != nil {
+		return l.parse(u, r)
+	}
+	return newTestFile(u, r)
+}
+func (l *testLanguage) ID() string                          { return "test" }
+func (l *testLanguage) Commands() []commands.Command        { return nil }
+func (l *testLanguage) Completions() completion.Completions { return l.completions }
+func (l *testLanguage) On() *language.LanguageOn            { return &language.LanguageOn{} }
+func (l *testLanguage) CompletionOptions() language.CompletionOptions {
+	return l.options
+}
+
+// initializeServer initializes the server for a client with the capabilities in JSON.
+func initializeServer(t *testing.T, s *Server, capabilities string) protocol317.ServerCapabilities {
+	t.Helper()
+	var params protocol317.InitializeParams
+	if err := json.Unmarshal([]byte(`{"capabilities":`+capabilities+`}`), &params); err != nil {
+		t.Fatal(err)
+	}
+	result, err := s.initialize(nil, &params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result.(protocol317.InitializeResult).Capabilities
+}
+
+// openDocument opens a document of the test language.
+func openDocument(t *testing.T, s *Server, u uri.DocumentURI, text string) {
+	t.Helper()
+	// the diagnostics of the document are published to nowhere
+	ctx := &glsp.Context{Notify: func(method string, params any) {}}
+	err := s.textDocumentDidOpen(ctx, &protocol.DidOpenTextDocumentParams{
+		TextDocument: protocol.TextDocumentItem{URI: u, LanguageID: "test", Version: 1, Text: text},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"github.com/kjbreil/glsp"
+	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/pkg/location"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// labelOffsetSupport reports whether the client takes parameter labels as offsets in the signature label.
+func (s *Server) labelOffsetSupport() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	textDocument := s.clientCapabilities.TextDocument
+	if textDocument == nil || textDocument.SignatureHelp == nil || textDocument.SignatureHelp.SignatureInformation == nil {
+		return false
+	}
+	parameterInformation := textDocument.SignatureHelp.SignatureInformation.ParameterInformation
+	return parameterInformation != nil && parameterInformation.LabelOffsetSupport != nil && *parameterInformation.LabelOffsetSupport
+}
+
+// signatureHelpOptions returns the signature help options of lang, or of every language when lang is nil.
+func (s *Server) signatureHelpOptions(lang language.LanguageDef) *protocol.SignatureHelpOptions {
+	options := s.languageOptions(lang)
+	return &protocol.SignatureHelpOptions{
+		TriggerCharacters:   options.SignatureTriggerCharacters,
+		RetriggerCharacters: options.SignatureRetriggerCharacters,
+	}
+}
+
+func (s *Server) textDocumentSignatureHelp(ctx *glsp.Context, params *protocol.SignatureHelpParams) (*protocol.SignatureHelp, error) {
+	_, file := s.languages.GetFromUri(params.TextDocument.URI)
+	provider, ok := file.(language.SignatureHelpProvider)
+	doc := s.document(params.TextDocument.URI)
+	if !ok || doc == nil {
+		return nil, nil
+	}
+
+	c := language.SignatureContext{
+		Point:       location.ProtocolPositionPoint(params.TextDocumentPositionParams),
+		TriggerKind: protocol.SignatureHelpTriggerKindInvoked,
+	}
+	if params.Context != nil {
+		c.TriggerKind = params.Context.TriggerKind
+		if params.Context.TriggerCharacter != nil {
+			c.TriggerCharacter = *params.Context.TriggerCharacter
+		}
+		c.IsRetrigger = params.Context.IsRetrigger
+		if active := params.Context.ActiveSignatureHelp; active != nil && active.ActiveSignature != nil {
+			if i := int(*active.ActiveSignature); i < len(active.Signatures) {
+				c.ActiveSignature = active.Signatures[i].Label
+			}
+		}
+	}
+
+	doc.mu.Lock()
+	help := provider.SignatureHelp(c)
+	doc.mu.Unlock()
+	if help == nil || len(help.Signatures) == 0 {
+		return nil, nil
+	}
+
+	if c.IsRetrigger && c.ActiveSignature != "" {
+		if i := help.Index(c.ActiveSignature); i >= 0 {
+			help.ActiveSignature = i
+		}
+	}
+	return help.Protocol(s.labelOffsetSupport()), nil
+}
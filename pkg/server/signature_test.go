@@ -0,0 +1,96 @@
+package server
+
+import (
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/kjbreil/glsp/internal/helpers"
+	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/pkg/signature"
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// signatureFile is a test file that shows two overloads of f and keeps the context it was last asked with.
+type signatureFile struct {
+	*testFile
+	context language.SignatureContext
+}
+
+func (f *signatureFile) SignatureHelp(c language.SignatureContext) *signature.Help {
+	f.context = c
+	return &signature.Help{Signatures: []signature.Signature{{Label: "f(a)"}, {Label: "f(a, b)"}}}
+}
+
+// signatureLanguage is a test language whose files are signatureFiles.
+type signatureLanguage struct {
+	testLanguage
+}
+
+func (l *signatureLanguage) FileType() language.File { return (*signatureFile)(nil) }
+
+func TestTextDocumentSignatureHelp(t *testing.T) {
+	lang := &signatureLanguage{testLanguage{
+		parse: func(u uri.DocumentURI, r io.Reader) (language.File, error) {
+			f, err := newTestFile(u, r)
+			return &signatureFile{testFile: f}, err
+		},
+		options: language.CompletionOptions{SignatureTriggerCharacters: []string{"("}, SignatureRetriggerCharacters: []string{","}},
+	}}
+	s := New(WithLanguage(lang))
+	capabilities := initializeServer(t, s, `{"textDocument":{"signatureHelp":{}}}`)
+
+	// the trigger characters are the ones of the language
+	provider := capabilities.SignatureHelpProvider
+	if provider == nil {
+		t.Fatal("SignatureHelpProvider = nil, want it advertised")
+	}
+	if !slices.Equal(provider.TriggerCharacters, []string{"("}) || !slices.Equal(provider.RetriggerCharacters, []string{","}) {
+		t.Errorf("trigger characters = %q, retrigger characters = %q, want ( and ,", provider.TriggerCharacters, provider.RetriggerCharacters)
+	}
+
+	u := uri.DocumentURI("file:///a.test")
+	openDocument(t, s, u, "f(a, ")
+	_, file := s.languages.GetFromUri(u)
+
+	help, err := s.textDocumentSignatureHelp(nil, &protocol.SignatureHelpParams{
+		TextDocumentPositionParams: at(u, 0, 2),
+		Context: &protocol.SignatureHelpContext{
+			TriggerKind:      protocol.SignatureHelpTriggerKindTriggerCharacter,
+			TriggerCharacter: helpers.Ptr("("),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := file.(*signatureFile).context
+	if c.TriggerKind != protocol.SignatureHelpTriggerKindTriggerCharacter || c.TriggerCharacter != "(" || c.IsRetrigger {
+		t.Errorf("context = %+v, want triggered by (", c)
+	}
+	if *help.ActiveSignature != 0 {
+		t.Errorf("ActiveSignature = %d, want 0", *help.ActiveSignature)
+	}
+
+	// the client moved to the second signature, it stays active when help is triggered again
+	help.ActiveSignature = helpers.Ptr(protocol.UInteger(1))
+	help, err = s.textDocumentSignatureHelp(nil, &protocol.SignatureHelpParams{
+		TextDocumentPositionParams: at(u, 0, 5),
+		Context: &protocol.SignatureHelpContext{
+			TriggerKind:         protocol.SignatureHelpTriggerKindTriggerCharacter,
+			TriggerCharacter:    helpers.Ptr(","),
+			IsRetrigger:         true,
+			ActiveSignatureHelp: help,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c = file.(*signatureFile).context
+	if c.TriggerCharacter != "," || !c.IsRetrigger || c.ActiveSignature != "f(a, b)" {
+		t.Errorf("retrigger context = %+v, want retriggered by , in f(a, b)", c)
+	}
+	if *help.ActiveSignature != 1 {
+		t.Errorf("ActiveSignature after the retrigger = %d, want 1", *help.ActiveSignature)
+	}
+}
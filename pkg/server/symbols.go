@@ -0,0 +1,149 @@
+package server
+
+import (
+	"github.com/kjbreil/glsp"
+	"github.com/kjbreil/glsp/internal/helpers"
+	"github.com/kjbreil/glsp/pkg/editreader"
+	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/pkg/location"
+	"github.com/kjbreil/glsp/pkg/symbols"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// fileDefinition is a definition with the file it is in.
+type fileDefinition struct {
+	file       language.File
+	definition *symbols.Definition
+}
+
+// symbolAt returns the name of the symbol at the position and the definitions it resolves to. A reference
+// without a definition in its file resolves by name to the definitions in the open files of the language.
+func (s *Server) symbolAt(params protocol.TextDocumentPositionParams) (*language.Language, string, []fileDefinition) {
+	lang, file := s.languages.GetFromUri(params.TextDocument.URI)
+	provider, ok := file.(language.SymbolProvider)
+	if !ok {
+		return nil, "", nil
+	}
+
+	definition, reference := provider.Symbols().At(location.ProtocolPositionPoint(params))
+	switch {
+	case definition != nil:
+		return lang, definition.Name, []fileDefinition{{file: file, definition: definition}}
+	case reference == nil:
+		return nil, "", nil
+	case reference.Definition != nil:
+		return lang, reference.Name, []fileDefinition{{file: file, definition: reference.Definition}}
+	}
+
+	var definitions []fileDefinition
+	lang.Files(func(f language.File) bool {
+		if provider, ok := f.(language.SymbolProvider); ok {
+			if d := provider.Symbols().Lookup(reference.Name); d != nil {
+				definitions = append(definitions, fileDefinition{file: f, definition: d})
+			}
+		}
+		return true
+	})
+	return lang, reference.Name, definitions
+}
+
+// appendLocation appends the location of the range in the file, a range that is not set is left out.
+func appendLocation(locations []protocol.Location, file language.File, r editreader.CharRange) []protocol.Location {
+	pr := r.ProtocolRange()
+	if pr == nil {
+		return locations
+	}
+	return append(locations, protocol.Location{URI: file.Uri(), Range: *pr})
+}
+
+func (s *Server) textDocumentDefinition(ctx *glsp.Context, params *protocol.DefinitionParams) (any, error) {
+	_, _, definitions := s.symbolAt(params.TextDocumentPositionParams)
+
+	locations := make([]protocol.Location, 0, len(definitions))
+	for _, d := range definitions {
+		locations = appendLocation(locations, d.file, d.definition.Selection)
+	}
+	return locations, nil
+}
+
+func (s *Server) textDocumentDeclaration(ctx *glsp.Context, params *protocol.DeclarationParams) (any, error) {
+	_, _, definitions := s.symbolAt(params.TextDocumentPositionParams)
+
+	locations := make([]protocol.Location, 0, len(definitions))
+	for _, d := range definitions {
+		if d.definition.Declaration != nil {
+			locations = appendLocation(locations, d.file, *d.definition.Declaration)
+		} else {
+			locations = appendLocation(locations, d.file, d.definition.Selection)
+		}
+	}
+	return locations, nil
+}
+
+func (s *Server) textDocumentReferences(ctx *glsp.Context, params *protocol.ReferenceParams) ([]protocol.Location, error) {
+	lang, _, definitions := s.symbolAt(params.TextDocumentPositionParams)
+	if lang == nil {
+		return nil, nil
+	}
+
+	locations := []protocol.Location{}
+	// references by name match every definition with the name, they are listed once
+	seen := make(map[*symbols.Reference]bool)
+	for _, d := range definitions {
+		if params.Context.IncludeDeclaration {
+			locations = appendLocation(locations, d.file, d.definition.Selection)
+		}
+		lang.Files(func(f language.File) bool {
+			if provider, ok := f.(language.SymbolProvider); ok {
+				for _, ref := range provider.Symbols().Uses(d.definition) {
+					if !seen[ref] {
+						seen[ref] = true
+						locations = appendLocation(locations, f, ref.Range)
+					}
+				}
+			}
+			return true
+		})
+	}
+	return locations, nil
+}
+
+func (s *Server) textDocumentDocumentHighlight(ctx *glsp.Context, params *protocol.DocumentHighlightParams) ([]protocol.DocumentHighlight, error) {
+	_, file := s.languages.GetFromUri(params.TextDocument.URI)
+	lang, name, definitions := s.symbolAt(params.TextDocumentPositionParams)
+	if lang == nil {
+		return nil, nil
+	}
+	syms := file.(language.SymbolProvider).Symbols()
+
+	// only the definition in this file is highlighted, references to other files match by name
+	definition := &symbols.Definition{Name: name}
+	for _, d := range definitions {
+		if d.file == file {
+			definition = d.definition
+		}
+	}
+
+	var highlights []protocol.DocumentHighlight
+	if r := definition.Selection.ProtocolRange(); r != nil {
+		highlights = append(highlights, protocol.DocumentHighlight{
+			Range: *r,
+			Kind:  helpers.Ptr(protocol.DocumentHighlightKindWrite),
+		})
+	}
+	for _, ref := range syms.Uses(definition) {
+		r := ref.Range.ProtocolRange()
+		if r == nil {
+			continue
+		}
+		kind := protocol.DocumentHighlightKindRead
+		if ref.Write {
+			kind = protocol.DocumentHighlightKindWrite
+		}
+		highlights = append(highlights, protocol.DocumentHighlight{
+			Range: *r,
+			Kind:  helpers.Ptr(kind),
+		})
+	}
+	return highlights, nil
+}
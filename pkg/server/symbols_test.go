@@ -0,0 +1,189 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kjbreil/glsp/internal/helpers"
+	"github.com/kjbreil/glsp/pkg/editreader"
+	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/pkg/location"
+	"github.com/kjbreil/glsp/pkg/symbols"
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// symbolFile is a test file of words, a word ending in a colon defines the name and the other words refer to
+// it. A reference resolves to the definition in its file, when there is one. The symbols are kept until the
+// file changes, like those of a file that records them while it parses.
+type symbolFile struct {
+	*testFile
+	syms *symbols.Symbols
+}
+
+func (f *symbolFile) Replace(text string, r *location.Range) {
+	f.testFile.Replace(text, r)
+	f.syms = nil
+}
+
+func (f *symbolFile) Reset(s string) {
+	f.testFile.Reset(s)
+	f.syms = nil
+}
+
+func (f *symbolFile) Symbols() *symbols.Symbols {
+	if f.syms != nil {
+		return f.syms
+	}
+	syms := symbols.New()
+	var references []func()
+	for line, content := range strings.Split(f.Text(), "\n") {
+		column := 0
+		for _, word := range strings.Split(content, " ") {
+			r := editreader.CharRange{
+				Start: f.reader.CharBefore(location.Point{Line: line, Column: column + 1}),
+				End:   f.reader.CharBefore(location.Point{Line: line, Column: column + len(word)}),
+			}
+			switch name, ok := strings.CutSuffix(word, ":"); {
+			case word == "":
+			case ok:
+				r.End = f.reader.CharBefore(location.Point{Line: line, Column: column + len(name)})
+				syms.Define(name, protocol.SymbolKindFunction, r, r)
+			default:
+				// references resolve once every definition of the file is known
+				references = append(references, func() { syms.Reference(word, r, syms.Lookup(word)) })
+			}
+			column += len(word) + 1
+		}
+	}
+	for _, reference := range references {
+		reference()
+	}
+	f.syms = syms
+	return syms
+}
+
+func (f *symbolFile) DocumentSymbols() []symbols.Symbol {
+	var outline []symbols.Symbol
+	for _, d := range f.Symbols().Definitions() {
+		outline = append(outline, symbols.Symbol{Name: d.Name, Kind: d.Kind, Range: d.Range, Selection: d.Selection})
+	}
+	return outline
+}
+
+// newSymbolServer opens the documents of a server whose files are symbolFiles.
+func newSymbolServer(t *testing.T, capabilities string, documents map[uri.DocumentURI]string) *Server {
+	t.Helper()
+	s := New(WithLanguage(&testLanguage{parse: func(u uri.DocumentURI, r io.Reader) (language.File, error) {
+		f, err := newTestFile(u, r)
+		return &symbolFile{testFile: f}, err
+	}}))
+	initializeServer(t, s, capabilities)
+	for u, text := range documents {
+		openDocument(t, s, u, text)
+	}
+	return s
+}
+
+// at returns the position params of the point in the document.
+func at(u uri.DocumentURI, line, character int) protocol.TextDocumentPositionParams {
+	return protocol.TextDocumentPositionParams{
+		TextDocument: protocol.TextDocumentIdentifier{URI: u},
+		Position:     protocol.Position{Line: protocol.UInteger(line), Character: protocol.UInteger(character)},
+	}
+}
+
+// wordLocation returns the location of the characters start to end on the line of the document.
+func wordLocation(u uri.DocumentURI, line, start, end int) protocol.Location {
+	return protocol.Location{URI: u, Range: protocol.Range{
+		Start: protocol.Position{Line: protocol.UInteger(line), Character: protocol.UInteger(start)},
+		End:   protocol.Position{Line: protocol.UInteger(line), Character: protocol.UInteger(end)},
+	}}
+}
+
+func TestTextDocumentDefinition(t *testing.T) {
+	a, b, c := uri.DocumentURI("file:///a.test"), uri.DocumentURI("file:///b.test"), uri.DocumentURI("file:///c.test")
+	s := newSymbolServer(t, `{}`, map[uri.DocumentURI]string{
+		a: "helper:\nmain: helper\n",
+		b: "other: helper",
+		c: "helper:",
+	})
+
+	tests := []struct {
+		name   string
+		params protocol.TextDocumentPositionParams
+		want   []protocol.Location
+	}{
+		{"definition", at(a, 0, 2), []protocol.Location{wordLocation(a, 0, 0, 6)}},
+		{"resolved reference", at(a, 1, 8), []protocol.Location{wordLocation(a, 0, 0, 6)}},
+		// b does not define helper, its reference resolves to every open definition by name
+		{"reference by name", at(b, 0, 8), []protocol.Location{wordLocation(a, 0, 0, 6), wordLocation(c, 0, 0, 6)}},
+		{"no symbol", at(a, 2, 0), []protocol.Location{}},
+	}
+	for _, tt := range tests {
+		result, err := s.textDocumentDefinition(nil, &protocol.DefinitionParams{TextDocumentPositionParams: tt.params})
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := result.([]protocol.Location)
+		slices.SortFunc(got, func(x, y protocol.Location) int { return strings.Compare(string(x.URI), string(y.URI)) })
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: definition = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTextDocumentReferences(t *testing.T) {
+	a, b, c := uri.DocumentURI("file:///a.test"), uri.DocumentURI("file:///b.test"), uri.DocumentURI("file:///c.test")
+	s := newSymbolServer(t, `{}`, map[uri.DocumentURI]string{
+		a: "helper:\nmain: helper",
+		b: "other: helper",
+		// c has a helper of its own, its references are not to the helper of a
+		c: "helper:\nthird: helper",
+	})
+
+	references := func(includeDeclaration bool) []protocol.Location {
+		t.Helper()
+		got, err := s.textDocumentReferences(nil, &protocol.ReferenceParams{
+			TextDocumentPositionParams: at(a, 0, 0),
+			Context:                    protocol.ReferenceContext{IncludeDeclaration: includeDeclaration},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		slices.SortFunc(got, func(x, y protocol.Location) int {
+			return strings.Compare(fmt.Sprint(x), fmt.Sprint(y))
+		})
+		return got
+	}
+
+	want := []protocol.Location{wordLocation(a, 1, 6, 12), wordLocation(b, 0, 7, 13)}
+	if got := references(false); !slices.Equal(got, want) {
+		t.Errorf("references = %v, want %v", got, want)
+	}
+	want = []protocol.Location{wordLocation(a, 0, 0, 6), wordLocation(a, 1, 6, 12), wordLocation(b, 0, 7, 13)}
+	if got := references(true); !slices.Equal(got, want) {
+		t.Errorf("references with the declaration = %v, want %v", got, want)
+	}
+}
+
+func TestTextDocumentDocumentHighlight(t *testing.T) {
+	a := uri.DocumentURI("file:///a.test")
+	s := newSymbolServer(t, `{}`, map[uri.DocumentURI]string{a: "helper:\nmain: helper other"})
+
+	got, err := s.textDocumentDocumentHighlight(nil, &protocol.DocumentHighlightParams{TextDocumentPositionParams: at(a, 1, 7)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []protocol.DocumentHighlight{
+		{Range: wordLocation(a, 0, 0, 6).Range, Kind: helpers.Ptr(protocol.DocumentHighlightKindWrite)},
+		{Range: wordLocation(a, 1, 6, 12).Range, Kind: helpers.Ptr(protocol.DocumentHighlightKindRead)},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("highlights = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kjbreil/glsp/pkg/language"
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol317 "github.com/kjbreil/glsp/protocol_3_17"
+)
+
+// setWorkspace uses the root folder of the client as the workspace unless one was set with WithWorkspace.
+func (s *Server) setWorkspace(params *protocol317.InitializeParams) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.workspace != nil {
+		return
+	}
+
+	var root *uri.DocumentURI
+	switch {
+	case params.RootURI != nil:
+		root = params.RootURI
+	case len(params.WorkspaceFolders) > 0:
+		root = &params.WorkspaceFolders[0].URI
+	}
+	if root == nil || root.Schema() != "file" {
+		return
+	}
+	s.workspaceRoot = root.Path()
+	s.workspace = os.DirFS(s.workspaceRoot)
+}
+
+// indexedFile is a parsed file of the workspace at the time it was modified, it is parsed again when it
+// changes.
+type indexedFile struct {
+	modTime time.Time
+	file    language.File
+}
+
+// workspaceFiles returns the files of the language in the workspace that are not open. Only languages that
+// implement language.FileExtensions have workspace files, hidden directories are skipped.
+func (s *Server) workspaceFiles(lang *language.Language) []language.File {
+	var files []language.File
+	s.walkWorkspace(lang, func(u uri.DocumentURI, fsys fs.FS, name string, entry fs.DirEntry) {
+		if file := s.workspaceFile(lang, u, fsys, name, entry); file != nil {
+			files = append(files, file)
+		}
+	})
+	return files
+}
+
+// workspaceFile returns a file of the workspace that is not open from the index, the file is parsed again
+// when it was modified since. It returns nil when the file cannot be read or parsed.
+func (s *Server) workspaceFile(lang *language.Language, u uri.DocumentURI, fsys fs.FS, name string, entry fs.DirEntry) language.File {
+	info, err := entry.Info()
+	if err != nil {
+		return nil
+	}
+	s.mu.Lock()
+	indexed, ok := s.workspaceIndex[u]
+	s.mu.Unlock()
+	if ok && indexed.modTime.Equal(info.ModTime()) {
+		return indexed.file
+	}
+
+	file := s.parseWorkspaceFile(lang, u, fsys, name)
+	if file == nil {
+		return nil
+	}
+	s.mu.Lock()
+	// the file may have been opened while it was parsed
+	if s.documents[u] == nil {
+		s.workspaceIndex[u] = indexedFile{modTime: info.ModTime(), file: file}
+	}
+	s.mu.Unlock()
+	return file
+}
+
+// walkWorkspace calls fn with each file of the language in the workspace that is not open.
+func (s *Server) walkWorkspace(lang *language.Language, fn func(u uri.DocumentURI, fsys fs.FS, name string, entry fs.DirEntry)) {
+	extensions, ok := lang.Def().(language.FileExtensions)
+	s.mu.Lock()
+	fsys, root := s.workspace, s.workspaceRoot
+	s.mu.Unlock()
+	if !ok || fsys == nil {
+		return
+	}
+	exts := extensions.Extensions()
+
+	_ = fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			// unreadable directories are left out
+			return nil
+		case entry.IsDir():
+			if name != "." && strings.HasPrefix(entry.Name(), ".") {
+				return fs.SkipDir
+			}
+			return nil
+		case !slices.Contains(exts, path.Ext(name)):
+			return nil
+		}
+
+		u := uri.URIFromPath("file", filepath.Join(root, filepath.FromSlash(name)))
+		if s.document(u) != nil {
+			return nil
+		}
+		fn(u, fsys, name, entry)
+		return nil
+	})
+}
+
+// parseWorkspaceFile parses the file name of the workspace, it returns nil when the file cannot be read or
+// parsed.
+func (s *Server) parseWorkspaceFile(lang *language.Language, u uri.DocumentURI, fsys fs.FS, name string) language.File {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	file, err := lang.Def().Parse(u, f)
+	if err != nil {
+		s.logger.Debug("could not parse workspace file", "uri", u, "err", err)
+		return nil
+	}
+	return file
+}
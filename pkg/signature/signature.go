@@ -0,0 +1,115 @@
+package signature
+
+import (
+	"strings"
+	"unicode/utf16"
+
+	"github.com/kjbreil/glsp/pkg/markdown"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// Help is the signature help at a position, the signatures that can be called there with the one that is
+// active and the parameter the cursor is at.
+type Help struct {
+	Signatures      []Signature
+	ActiveSignature int
+	ActiveParameter int
+}
+
+// Signature is a callable signature, Label is shown to the user and the parameters are parts of it.
+type Signature struct {
+	Label      string
+	Markdown   markdown.Markdown
+	Parameters []Parameter
+}
+
+// Parameter is a parameter of a signature, Start and End are the byte offsets of its label in the label of
+// the signature.
+type Parameter struct {
+	Start    int
+	End      int
+	Markdown markdown.Markdown
+}
+
+// AddParameter adds the parameter whose label is the first occurrence of label in the signature label after
+// the previous parameter, it reports false when the label is not found.
+func (s *Signature) AddParameter(label string, md markdown.Markdown) bool {
+	from := 0
+	if len(s.Parameters) > 0 {
+		from = s.Parameters[len(s.Parameters)-1].End
+	}
+	i := strings.Index(s.Label[from:], label)
+	if i < 0 {
+		return false
+	}
+	s.Parameters = append(s.Parameters, Parameter{Start: from + i, End: from + i + len(label), Markdown: md})
+	return true
+}
+
+// Index returns the index of the signature with the label, -1 when there is none.
+func (h *Help) Index(label string) int {
+	for i, s := range h.Signatures {
+		if s.Label == label {
+			return i
+		}
+	}
+	return -1
+}
+
+// Protocol returns the signature help. Parameter labels are sent as offsets in the signature label when
+// labelOffsets is set, otherwise as their text.
+func (h *Help) Protocol(labelOffsets bool) *protocol.SignatureHelp {
+	help := &protocol.SignatureHelp{
+		Signatures:      make([]protocol.SignatureInformation, len(h.Signatures)),
+		ActiveSignature: ptr(protocol.UInteger(h.ActiveSignature)),
+		ActiveParameter: ptr(protocol.UInteger(h.ActiveParameter)),
+	}
+	for i := range h.Signatures {
+		help.Signatures[i] = h.Signatures[i].Protocol(labelOffsets)
+	}
+	return help
+}
+
+func (s *Signature) Protocol(labelOffsets bool) protocol.SignatureInformation {
+	information := protocol.SignatureInformation{
+		Label:         s.Label,
+		Documentation: documentation(s.Markdown),
+		Parameters:    make([]protocol.ParameterInformation, len(s.Parameters)),
+	}
+	for i, p := range s.Parameters {
+		information.Parameters[i].Documentation = documentation(p.Markdown)
+		if labelOffsets {
+			// offsets are counted in UTF-16 code units
+			information.Parameters[i].Label = []protocol.UInteger{
+				utf16Len(s.Label[:p.Start]),
+				utf16Len(s.Label[:p.End]),
+			}
+		} else {
+			information.Parameters[i].Label = s.Label[p.Start:p.End]
+		}
+	}
+	return information
+}
+
+// documentation renders the markdown, it is nil when the markdown is empty.
+func documentation(md markdown.Markdown) any {
+	if md == (markdown.Markdown{}) {
+		return nil
+	}
+	return protocol.MarkupContent{
+		Kind:  protocol.MarkupKindMarkdown,
+		Value: md.String(),
+	}
+}
+
+func utf16Len(s string) protocol.UInteger {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return protocol.UInteger(n)
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
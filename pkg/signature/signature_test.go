@@ -0,0 +1,32 @@
+package signature
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/kjbreil/glsp/pkg/markdown"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+func TestSignatureProtocol(t *testing.T) {
+	s := Signature{Label: "größe(a int, b int)"}
+	if !s.AddParameter("a int", markdown.Markdown{}) || !s.AddParameter("b int", markdown.Markdown{Main: "b"}) {
+		t.Fatal("AddParameter() did not find the parameters")
+	}
+	if s.AddParameter("c int", markdown.Markdown{}) {
+		t.Error("AddParameter() found a parameter that is not in the label")
+	}
+
+	offsets := s.Protocol(true)
+	if got, want := offsets.Parameters[0].Label, []protocol.UInteger{6, 11}; !reflect.DeepEqual(got, want) {
+		t.Errorf("label offsets = %v, want %v", got, want)
+	}
+	if offsets.Parameters[0].Documentation != nil || offsets.Parameters[1].Documentation == nil {
+		t.Errorf("documentation = %v, %v", offsets.Parameters[0].Documentation, offsets.Parameters[1].Documentation)
+	}
+
+	labels := s.Protocol(false)
+	if got := labels.Parameters[1].Label; got != "b int" {
+		t.Errorf("label = %v, want b int", got)
+	}
+}
@@ -0,0 +1,91 @@
+package symbols
+
+import (
+	"github.com/kjbreil/glsp/pkg/editreader"
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// Symbol is a node in the outline of a file.
+type Symbol struct {
+	Name   string
+	Kind   protocol.SymbolKind
+	Detail string
+	// Range covers the whole symbol, Selection only its name. Selection is the whole range when it is not
+	// set, symbols without a range are left out.
+	Range      editreader.CharRange
+	Selection  editreader.CharRange
+	Deprecated bool
+	Children   []Symbol
+}
+
+// ranges returns the range and selection of the symbol, ok is false when it has no range.
+func (s *Symbol) ranges() (r protocol.Range, selection protocol.Range, ok bool) {
+	full := s.Range.ProtocolRange()
+	if full == nil {
+		return protocol.Range{}, protocol.Range{}, false
+	}
+	if name := s.Selection.ProtocolRange(); name != nil {
+		return *full, *name, true
+	}
+	return *full, *full, true
+}
+
+// Protocol returns the symbol as a document symbol with its children, ok is false when the symbol has no
+// range.
+func (s *Symbol) Protocol() (symbol protocol.DocumentSymbol, ok bool) {
+	r, selection, ok := s.ranges()
+	if !ok {
+		return symbol, false
+	}
+	symbol = protocol.DocumentSymbol{
+		Name:           s.Name,
+		Kind:           s.Kind,
+		Range:          r,
+		SelectionRange: selection,
+	}
+	if s.Detail != "" {
+		symbol.Detail = &s.Detail
+	}
+	if s.Deprecated {
+		symbol.Tags = []protocol.SymbolTag{protocol.SymbolTagDeprecated}
+	}
+	for i := range s.Children {
+		if child, ok := s.Children[i].Protocol(); ok {
+			symbol.Children = append(symbol.Children, child)
+		}
+	}
+	return symbol, true
+}
+
+// Flatten returns the symbols and all their children as symbol information of the document, the name of the
+// parent of a child is its container name.
+func Flatten(u uri.DocumentURI, outline []Symbol) []protocol.SymbolInformation {
+	var flat []protocol.SymbolInformation
+	var walk func(symbols []Symbol, container *string)
+	walk = func(symbols []Symbol, container *string) {
+		for i := range symbols {
+			s := &symbols[i]
+			r, _, ok := s.ranges()
+			if !ok {
+				continue
+			}
+			information := protocol.SymbolInformation{
+				Name: s.Name,
+				Kind: s.Kind,
+				Location: protocol.Location{
+					URI:   u,
+					Range: r,
+				},
+				ContainerName: container,
+			}
+			if s.Deprecated {
+				information.Tags = []protocol.SymbolTag{protocol.SymbolTagDeprecated}
+			}
+			flat = append(flat, information)
+			walk(s.Children, &s.Name)
+		}
+	}
+	walk(outline, nil)
+	return flat
+}
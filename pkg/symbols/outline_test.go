@@ -0,0 +1,43 @@
+package symbols
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kjbreil/glsp/pkg/editreader"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+func TestSymbolProtocol(t *testing.T) {
+	f, err := editreader.New(strings.NewReader("table users"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outline := []Symbol{
+		{
+			Name:  "users",
+			Kind:  protocol.SymbolKindClass,
+			Range: word(t, f, 0, 0, 10),
+			Children: []Symbol{
+				{Name: "unset", Kind: protocol.SymbolKindField},
+			},
+		},
+	}
+	symbol, ok := outline[0].Protocol()
+	if !ok {
+		t.Fatalf("Protocol() ok = false")
+	}
+	// without a selection the name is the whole symbol
+	want := protocol.Range{End: protocol.Position{Character: 11}}
+	if symbol.Range != want || symbol.SelectionRange != want {
+		t.Errorf("Protocol() ranges = %v %v, want %v", symbol.Range, symbol.SelectionRange, want)
+	}
+	if len(symbol.Children) != 0 {
+		t.Errorf("Protocol() children = %v, the child without a range is left out", symbol.Children)
+	}
+
+	if flat := Flatten("file:///a", outline); len(flat) != 1 || flat[0].Location.Range != want {
+		t.Errorf("Flatten() = %v", flat)
+	}
+}
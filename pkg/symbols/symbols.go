@@ -0,0 +1,128 @@
+package symbols
+
+import (
+	"github.com/kjbreil/glsp/pkg/editreader"
+	"github.com/kjbreil/glsp/pkg/location"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// Definition is a named symbol defined in a file.
+type Definition struct {
+	Name string
+	Kind protocol.SymbolKind
+	// Range covers the whole definition, Selection only its name.
+	Range     editreader.CharRange
+	Selection editreader.CharRange
+	// Declaration is the name at the declaration when the symbol is declared apart from its definition.
+	Declaration *editreader.CharRange
+}
+
+// Reference is a use of a symbol.
+type Reference struct {
+	Name  string
+	Range editreader.CharRange
+	// Definition is the definition the reference resolves to. A reference without one resolves by name to the
+	// definitions in the open files of the language.
+	Definition *Definition
+	// Write is set when the reference assigns to the symbol.
+	Write bool
+}
+
+// Symbols are the definitions and references of a file, a file fills them in while it parses.
+type Symbols struct {
+	definitions []*Definition
+	references  []*Reference
+}
+
+func New() *Symbols {
+	return &Symbols{}
+}
+
+// Define adds a definition, selection is the range of its name.
+func (s *Symbols) Define(name string, kind protocol.SymbolKind, r editreader.CharRange, selection editreader.CharRange) *Definition {
+	d := &Definition{
+		Name:      name,
+		Kind:      kind,
+		Range:     r,
+		Selection: selection,
+	}
+	s.definitions = append(s.definitions, d)
+	return d
+}
+
+// Reference adds a use of the symbol named name, definition is nil when it is not defined in the file.
+func (s *Symbols) Reference(name string, r editreader.CharRange, definition *Definition) *Reference {
+	ref := &Reference{
+		Name:       name,
+		Range:      r,
+		Definition: definition,
+	}
+	s.references = append(s.references, ref)
+	return ref
+}
+
+// Lookup returns the definition named name, nil when the file does not define it.
+func (s *Symbols) Lookup(name string) *Definition {
+	if s == nil {
+		return nil
+	}
+	for _, d := range s.definitions {
+		if d.Name == name {
+			return d
+		}
+	}
+	return nil
+}
+
+// Definitions returns the definitions in the order they were added.
+func (s *Symbols) Definitions() []*Definition {
+	if s == nil {
+		return nil
+	}
+	return s.definitions
+}
+
+// References returns the references in the order they were added.
+func (s *Symbols) References() []*Reference {
+	if s == nil {
+		return nil
+	}
+	return s.references
+}
+
+// At returns the definition or the reference whose name is at the point, both are nil when there is none.
+func (s *Symbols) At(p location.Point) (*Definition, *Reference) {
+	if s == nil {
+		return nil, nil
+	}
+	for _, ref := range s.references {
+		if contains(ref.Range, p) {
+			return nil, ref
+		}
+	}
+	for _, d := range s.definitions {
+		if contains(d.Selection, p) || d.Declaration != nil && contains(*d.Declaration, p) {
+			return d, nil
+		}
+	}
+	return nil, nil
+}
+
+// Uses returns the references to the definition, references without a definition are matched by name.
+func (s *Symbols) Uses(d *Definition) []*Reference {
+	if s == nil {
+		return nil
+	}
+	var uses []*Reference
+	for _, ref := range s.references {
+		if ref.Definition == d || ref.Definition == nil && ref.Name == d.Name {
+			uses = append(uses, ref)
+		}
+	}
+	return uses
+}
+
+func contains(r editreader.CharRange, p location.Point) bool {
+	lr := r.Range()
+	return lr != nil && lr.Contains(p)
+}
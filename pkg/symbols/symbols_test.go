@@ -0,0 +1,54 @@
+package symbols
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kjbreil/glsp/pkg/editreader"
+	"github.com/kjbreil/glsp/pkg/location"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// word returns the range of the characters from column start to end on the line.
+func word(t *testing.T, f *editreader.File, line, start, end int) editreader.CharRange {
+	t.Helper()
+	return editreader.CharRange{
+		Start: f.CharBefore(location.Point{Line: line, Column: start + 1}),
+		End:   f.CharBefore(location.Point{Line: line, Column: end + 1}),
+	}
+}
+
+func TestSymbols(t *testing.T) {
+	f, err := editreader.New(strings.NewReader("let total = 1\ntotal = total + other"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := New()
+	total := s.Define("total", protocol.SymbolKindVariable, word(t, f, 0, 0, 12), word(t, f, 0, 4, 8))
+	s.Reference("total", word(t, f, 1, 0, 4), total).Write = true
+	s.Reference("total", word(t, f, 1, 8, 12), total)
+	other := s.Reference("other", word(t, f, 1, 16, 20), nil)
+
+	if d, ref := s.At(location.Point{Line: 0, Column: 6}); d != total || ref != nil {
+		t.Errorf("At(definition) = %v, %v", d, ref)
+	}
+	if d, ref := s.At(location.Point{Line: 1, Column: 21}); d != nil || ref != other {
+		t.Errorf("At(end of other) = %v, %v", d, ref)
+	}
+	if d, ref := s.At(location.Point{Line: 1, Column: 6}); d != nil || ref != nil {
+		t.Errorf("At(operator) = %v, %v", d, ref)
+	}
+	if uses := s.Uses(total); len(uses) != 2 || !uses[0].Write || uses[1].Write {
+		t.Errorf("Uses(total) = %v", uses)
+	}
+	if uses := s.Uses(&Definition{Name: "other"}); len(uses) != 1 || uses[0] != other {
+		t.Errorf("Uses(other) = %v", uses)
+	}
+	if got := s.Lookup("total"); got != total {
+		t.Errorf("Lookup(total) = %v", got)
+	}
+	if got := word(t, f, 0, 4, 8).String(); got != "total" {
+		t.Errorf("word = %q", got)
+	}
+}
@@ -36,3 +36,9 @@ func cancelRtnErr(ctx context.Context, fn func() (any, error)) (any, error) {
 		return rtn.rtn, rtn.err
 	}
 }
+
+// CancelRtnErr runs fn for a request and returns the error of ctx as soon as the request is cancelled, the
+// handlers of later protocol versions use it to share the cancellation of this one.
+func CancelRtnErr(ctx context.Context, fn func() (any, error)) (any, error) {
+	return cancelRtnErr(ctx, fn)
+}
@@ -1,13 +1,15 @@
 package protocol
 
 import (
-	"encoding/json"
 	"errors"
 	"sync"
 
 	"github.com/kjbreil/glsp"
 )
 
+// ErrNotInitialized is returned for every request that arrives before initialize.
+var ErrNotInitialized = errors.New("server not initialized")
+
 type Handler struct {
 	// Base Protocol
 	CancelRequest CancelRequestFunc
@@ -82,6 +84,11 @@ type Handler struct {
 	TextDocumentLinkedEditingRange      TextDocumentLinkedEditingRangeFunc
 	TextDocumentMoniker                 TextDocumentMonikerFunc
 
+	// Strict rejects parameters with unknown fields or missing required fields with InvalidParams
+	Strict bool
+	// StrictMethods turns strict validation on or off per method, overriding Strict
+	StrictMethods map[string]bool
+
 	initialized bool
 	lock        sync.Mutex
 
@@ -134,7 +141,7 @@ func (h *Handler) StopID(id int) {
 // ([glsp.Handler] interface)
 func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validParams bool, err error) {
 	if !h.IsInitialized() && (context.Method != MethodInitialize) {
-		return nil, true, true, errors.New("server not initialized")
+		return nil, true, true, ErrNotInitialized
 	}
 
 	h.registerCtx(context)
@@ -146,7 +153,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.CancelRequest != nil {
 			validMethod = true
 			var params CancelParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				err = cancelErr(context.Context, func() error { return h.CancelRequest(context, &params) })
 			}
@@ -156,7 +163,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.Progress != nil {
 			validMethod = true
 			var params ProgressParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				err = cancelErr(context.Context, func() error { return h.Progress(context, &params) })
 			}
@@ -168,7 +175,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.Initialize != nil {
 			validMethod = true
 			var params InitializeParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				if r, err = h.Initialize(context, &params); err == nil {
 					h.SetInitialized(true)
@@ -180,7 +187,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.Initialized != nil {
 			validMethod = true
 			var params InitializedParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				err = cancelErr(context.Context, func() error { return h.Initialized(context, &params) })
 			}
@@ -206,7 +213,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.LogTrace != nil {
 			validMethod = true
 			var params LogTraceParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				err = cancelErr(context.Context, func() error { return h.LogTrace(context, &params) })
 			}
@@ -216,7 +223,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.SetTrace != nil {
 			validMethod = true
 			var params SetTraceParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				err = cancelErr(context.Context, func() error { return h.SetTrace(context, &params) })
 			}
@@ -228,7 +235,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.WindowWorkDoneProgressCancel != nil {
 			validMethod = true
 			var params WorkDoneProgressCancelParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				err = cancelErr(context.Context, func() error { return h.WindowWorkDoneProgressCancel(context, &params) })
 			}
@@ -240,7 +247,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.WorkspaceDidChangeWorkspaceFolders != nil {
 			validMethod = true
 			var params DidChangeWorkspaceFoldersParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				err = cancelErr(context.Context, func() error { return h.WorkspaceDidChangeWorkspaceFolders(context, &params) })
 			}
@@ -250,7 +257,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.WorkspaceDidChangeConfiguration != nil {
 			validMethod = true
 			var params DidChangeConfigurationParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				err = cancelErr(context.Context, func() error { return h.WorkspaceDidChangeConfiguration(context, &params) })
 			}
@@ -260,7 +267,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.WorkspaceDidChangeWatchedFiles != nil {
 			validMethod = true
 			var params DidChangeWatchedFilesParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				err = cancelErr(context.Context, func() error { return h.WorkspaceDidChangeWatchedFiles(context, &params) })
 			}
@@ -270,7 +277,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.WorkspaceSymbol != nil {
 			validMethod = true
 			var params WorkspaceSymbolParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.WorkspaceSymbol(context, &params) })
 			}
@@ -280,7 +287,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.WorkspaceExecuteCommand != nil {
 			validMethod = true
 			var params ExecuteCommandParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.WorkspaceExecuteCommand(context, &params) })
 			}
@@ -290,7 +297,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.WorkspaceWillCreateFiles != nil {
 			validMethod = true
 			var params CreateFilesParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.WorkspaceWillCreateFiles(context, &params) })
 			}
@@ -300,7 +307,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.WorkspaceDidCreateFiles != nil {
 			validMethod = true
 			var params CreateFilesParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				err = cancelErr(context.Context, func() error { return h.WorkspaceDidCreateFiles(context, &params) })
 			}
@@ -310,7 +317,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.WorkspaceWillRenameFiles != nil {
 			validMethod = true
 			var params RenameFilesParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.WorkspaceWillRenameFiles(context, &params) })
 			}
@@ -320,7 +327,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.WorkspaceDidRenameFiles != nil {
 			validMethod = true
 			var params RenameFilesParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				err = cancelErr(context.Context, func() error { return h.WorkspaceDidRenameFiles(context, &params) })
 			}
@@ -330,7 +337,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.WorkspaceWillDeleteFiles != nil {
 			validMethod = true
 			var params DeleteFilesParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.WorkspaceWillDeleteFiles(context, &params) })
 			}
@@ -340,7 +347,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.WorkspaceDidDeleteFiles != nil {
 			validMethod = true
 			var params DeleteFilesParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				err = cancelErr(context.Context, func() error { return h.WorkspaceDidDeleteFiles(context, &params) })
 			}
@@ -353,7 +360,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 			validMethod = true
 			var params DidOpenTextDocumentParams
 
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				err = cancelErr(context.Context, func() error { return h.TextDocumentDidOpen(context, &params) })
 			}
@@ -363,7 +370,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentDidChange != nil {
 			validMethod = true
 			var params DidChangeTextDocumentParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				err = cancelErr(context.Context, func() error { return h.TextDocumentDidChange(context, &params) })
 			}
@@ -373,7 +380,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentWillSave != nil {
 			validMethod = true
 			var params WillSaveTextDocumentParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				err = cancelErr(context.Context, func() error { return h.TextDocumentWillSave(context, &params) })
 			}
@@ -383,7 +390,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentWillSaveWaitUntil != nil {
 			validMethod = true
 			var params WillSaveTextDocumentParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.TextDocumentWillSaveWaitUntil(context, &params) })
 			}
@@ -393,7 +400,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentDidSave != nil {
 			validMethod = true
 			var params DidSaveTextDocumentParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				err = cancelErr(context.Context, func() error { return h.TextDocumentDidSave(context, &params) })
 			}
@@ -403,7 +410,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentDidClose != nil {
 			validMethod = true
 			var params DidCloseTextDocumentParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				err = cancelErr(context.Context, func() error { return h.TextDocumentDidClose(context, &params) })
 			}
@@ -415,7 +422,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentCompletion != nil {
 			validMethod = true
 			var params CompletionParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.TextDocumentCompletion(context, &params) })
 			}
@@ -425,7 +432,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.CompletionItemResolve != nil {
 			validMethod = true
 			var params CompletionItem
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.CompletionItemResolve(context, &params) })
 			}
@@ -435,7 +442,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentHover != nil {
 			validMethod = true
 			var params HoverParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.TextDocumentHover(context, &params) })
 			}
@@ -445,7 +452,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentSignatureHelp != nil {
 			validMethod = true
 			var params SignatureHelpParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.TextDocumentSignatureHelp(context, &params) })
 			}
@@ -455,7 +462,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentDeclaration != nil {
 			validMethod = true
 			var params DeclarationParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.TextDocumentDeclaration(context, &params) })
 			}
@@ -465,7 +472,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentDefinition != nil {
 			validMethod = true
 			var params DefinitionParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.TextDocumentDefinition(context, &params) })
 			}
@@ -475,7 +482,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentTypeDefinition != nil {
 			validMethod = true
 			var params TypeDefinitionParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.TextDocumentTypeDefinition(context, &params) })
 			}
@@ -485,7 +492,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentImplementation != nil {
 			validMethod = true
 			var params ImplementationParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.TextDocumentImplementation(context, &params) })
 			}
@@ -495,7 +502,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentReferences != nil {
 			validMethod = true
 			var params ReferenceParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.TextDocumentReferences(context, &params) })
 			}
@@ -505,7 +512,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentDocumentHighlight != nil {
 			validMethod = true
 			var params DocumentHighlightParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.TextDocumentDocumentHighlight(context, &params) })
 			}
@@ -515,7 +522,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentDocumentSymbol != nil {
 			validMethod = true
 			var params DocumentSymbolParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.TextDocumentDocumentSymbol(context, &params) })
 			}
@@ -525,7 +532,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentCodeAction != nil {
 			validMethod = true
 			var params CodeActionParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.TextDocumentCodeAction(context, &params) })
 			}
@@ -535,7 +542,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.CodeActionResolve != nil {
 			validMethod = true
 			var params CodeAction
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.CodeActionResolve(context, &params) })
 			}
@@ -545,7 +552,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentCodeLens != nil {
 			validMethod = true
 			var params CodeLensParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.TextDocumentCodeLens(context, &params) })
 			}
@@ -555,7 +562,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentDidClose != nil {
 			validMethod = true
 			var params CodeLens
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.CodeLensResolve(context, &params) })
 			}
@@ -565,7 +572,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentDocumentLink != nil {
 			validMethod = true
 			var params DocumentLinkParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.TextDocumentDocumentLink(context, &params) })
 			}
@@ -575,7 +582,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.DocumentLinkResolve != nil {
 			validMethod = true
 			var params DocumentLink
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.DocumentLinkResolve(context, &params) })
 			}
@@ -585,7 +592,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentColor != nil {
 			validMethod = true
 			var params DocumentColorParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.TextDocumentColor(context, &params) })
 			}
@@ -595,7 +602,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentColorPresentation != nil {
 			validMethod = true
 			var params ColorPresentationParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.TextDocumentColorPresentation(context, &params) })
 			}
@@ -605,7 +612,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentFormatting != nil {
 			validMethod = true
 			var params DocumentFormattingParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.TextDocumentFormatting(context, &params) })
 			}
@@ -615,7 +622,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentRangeFormatting != nil {
 			validMethod = true
 			var params DocumentRangeFormattingParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.TextDocumentRangeFormatting(context, &params) })
 			}
@@ -625,7 +632,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentOnTypeFormatting != nil {
 			validMethod = true
 			var params DocumentOnTypeFormattingParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.TextDocumentOnTypeFormatting(context, &params) })
 			}
@@ -635,7 +642,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentRename != nil {
 			validMethod = true
 			var params RenameParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.TextDocumentRename(context, &params) })
 			}
@@ -645,7 +652,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentPrepareRename != nil {
 			validMethod = true
 			var params PrepareRenameParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.TextDocumentPrepareRename(context, &params) })
 			}
@@ -655,7 +662,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentFoldingRange != nil {
 			validMethod = true
 			var params FoldingRangeParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.TextDocumentFoldingRange(context, &params) })
 			}
@@ -665,7 +672,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentSelectionRange != nil {
 			validMethod = true
 			var params SelectionRangeParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.TextDocumentSelectionRange(context, &params) })
 			}
@@ -675,7 +682,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentPrepareCallHierarchy != nil {
 			validMethod = true
 			var params CallHierarchyPrepareParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.TextDocumentPrepareCallHierarchy(context, &params) })
 			}
@@ -685,7 +692,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.CallHierarchyIncomingCalls != nil {
 			validMethod = true
 			var params CallHierarchyIncomingCallsParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.CallHierarchyIncomingCalls(context, &params) })
 			}
@@ -695,7 +702,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.CallHierarchyOutgoingCalls != nil {
 			validMethod = true
 			var params CallHierarchyOutgoingCallsParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.CallHierarchyOutgoingCalls(context, &params) })
 			}
@@ -705,7 +712,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentSemanticTokensFull != nil {
 			validMethod = true
 			var params SemanticTokensParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.TextDocumentSemanticTokensFull(context, &params) })
 			}
@@ -715,7 +722,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentSemanticTokensFullDelta != nil {
 			validMethod = true
 			var params SemanticTokensDeltaParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.TextDocumentSemanticTokensFullDelta(context, &params) })
 			}
@@ -725,7 +732,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentSemanticTokensRange != nil {
 			validMethod = true
 			var params SemanticTokensRangeParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.TextDocumentSemanticTokensRange(context, &params) })
 			}
@@ -742,7 +749,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentLinkedEditingRange != nil {
 			validMethod = true
 			var params LinkedEditingRangeParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.TextDocumentLinkedEditingRange(context, &params) })
 			}
@@ -752,7 +759,7 @@ func (h *Handler) Handle(context *glsp.Context) (r any, validMethod bool, validP
 		if h.TextDocumentMoniker != nil {
 			validMethod = true
 			var params MonikerParams
-			if err = json.Unmarshal(context.Params, &params); err == nil {
+			if err = h.UnmarshalParams(context, &params); err == nil {
 				validParams = true
 				r, err = cancelRtnErr(context.Context, func() (any, error) { return h.TextDocumentMoniker(context, &params) })
 			}
@@ -899,7 +906,12 @@ func (h *Handler) CreateServerCapabilities() ServerCapabilities {
 	}
 
 	if h.TextDocumentRename != nil {
-		capabilities.RenameProvider = true
+		if h.TextDocumentPrepareRename != nil {
+			value := true
+			capabilities.RenameProvider = &RenameOptions{PrepareProvider: &value}
+		} else {
+			capabilities.RenameProvider = true
+		}
 	}
 
 	if h.TextDocumentFoldingRange != nil {
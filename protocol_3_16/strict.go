@@ -0,0 +1,230 @@
+package protocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/kjbreil/glsp"
+)
+
+// ErrInvalidParams is wrapped by every error returned from strict parameter validation.
+var ErrInvalidParams = errors.New("invalid params")
+
+var (
+	// requiredFields holds the required JSON fields of the types whose tags do not say which fields the spec
+	// requires
+	requiredFields   = make(map[reflect.Type]map[string]bool)
+	requiredFieldsMu sync.RWMutex
+)
+
+// SetRequiredFields sets the JSON fields of the type of v that strict validation requires, in place of the
+// fields without `omitempty` in its tags. It is for types with fields the spec marks optional that are not
+// tagged `omitempty`, those are left out of fields.
+func SetRequiredFields(v any, fields ...string) {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	required := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		required[f] = true
+	}
+
+	requiredFieldsMu.Lock()
+	defer requiredFieldsMu.Unlock()
+	requiredFields[t] = required
+}
+
+// IsStrict reports whether parameters of the method are validated strictly. StrictMethods overrides Strict
+// for the methods it contains.
+func (h *Handler) IsStrict(method string) bool {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if strict, ok := h.StrictMethods[method]; ok {
+		return strict
+	}
+	return h.Strict
+}
+
+// SetStrict toggles strict parameter validation for a single method.
+func (h *Handler) SetStrict(method string, strict bool) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.StrictMethods == nil {
+		h.StrictMethods = make(map[string]bool)
+	}
+	h.StrictMethods[method] = strict
+}
+
+// UnmarshalParams decodes the parameters of the request into params. In strict mode parameters that contain
+// unknown fields or miss fields that are required by the struct tags are rejected.
+func (h *Handler) UnmarshalParams(context *glsp.Context, params any) error {
+	if h.IsStrict(context.Method) {
+		if err := ValidateParams(context.Params, params); err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(context.Params, params)
+}
+
+// ValidateParams checks the raw JSON against the struct tags of the type of v. A field without `omitempty` in
+// its tag is required unless the fields of its type were set with SetRequiredFields, null is accepted as a
+// value for a required pointer. Every field in the JSON has to
+// be known by the type. Fields typed as any are not descended into.
+func ValidateParams(data json.RawMessage, v any) error {
+	return validateValue("params", data, reflect.TypeOf(v))
+}
+
+func validateValue(path string, data json.RawMessage, t reflect.Type) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if data[0] != '{' {
+			// types with their own unmarshaler can be encoded as something other than an object, type
+			// mismatches for the rest are reported by the decoder
+			return nil
+		}
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(data, &object); err != nil {
+			return nil
+		}
+		return validateObject(path, object, t)
+
+	case reflect.Slice, reflect.Array:
+		if data[0] != '[' {
+			return nil
+		}
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil
+		}
+		for i, item := range items {
+			if err := validateValue(fmt.Sprintf("%s[%d]", path, i), item, t.Elem()); err != nil {
+				return err
+			}
+		}
+
+	case reflect.Map:
+		if data[0] != '{' {
+			return nil
+		}
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(data, &object); err != nil {
+			return nil
+		}
+		for key, value := range object {
+			if err := validateValue(path+"."+key, value, t.Elem()); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateObject(path string, object map[string]json.RawMessage, t reflect.Type) error {
+	fields := jsonFields(t)
+
+	var missing []string
+	for name, field := range fields {
+		value, ok := object[name]
+		if !ok {
+			if field.required {
+				missing = append(missing, name)
+			}
+			continue
+		}
+		if err := validateValue(path+"."+name, value, field.t); err != nil {
+			return err
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("%w: %s: missing required field %s", ErrInvalidParams, path, strings.Join(missing, ", "))
+	}
+
+	var unknown []string
+	for name := range object {
+		if _, ok := fields[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("%w: %s: unknown field %s", ErrInvalidParams, path, strings.Join(unknown, ", "))
+	}
+
+	return nil
+}
+
+type jsonField struct {
+	t        reflect.Type
+	required bool
+	depth    int
+}
+
+// jsonFields returns the fields of the struct by their JSON name, fields of embedded structs are promoted
+// and a shallower field hides a deeper one the same way encoding/json does.
+func jsonFields(t reflect.Type) map[string]jsonField {
+	fields := make(map[string]jsonField)
+	collectFields(t, 0, fields)
+	return fields
+}
+
+func collectFields(t reflect.Type, depth int, fields map[string]jsonField) {
+	requiredFieldsMu.RLock()
+	required, listed := requiredFields[t]
+	requiredFieldsMu.RUnlock()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				collectFields(ft, depth+1, fields)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		if existing, ok := fields[name]; ok && existing.depth <= depth {
+			continue
+		}
+		field := jsonField{
+			t:        f.Type,
+			required: !strings.Contains(options, "omitempty"),
+			depth:    depth,
+		}
+		if listed {
+			field.required = required[name]
+		}
+		fields[name] = field
+	}
+}
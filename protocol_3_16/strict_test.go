@@ -0,0 +1,118 @@
+package protocol
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kjbreil/glsp"
+)
+
+func TestValidateParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  any
+		data    string
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			params: &DidSaveTextDocumentParams{},
+			data:   `{"textDocument":{"uri":"file:///a.txt"},"text":"a"}`,
+		},
+		{
+			name:   "optional field omitted",
+			params: &DidSaveTextDocumentParams{},
+			data:   `{"textDocument":{"uri":"file:///a.txt"}}`,
+		},
+		{
+			name:    "missing nested required field",
+			params:  &DidSaveTextDocumentParams{},
+			data:    `{"textDocument":{}}`,
+			wantErr: "params.textDocument: missing required field uri",
+		},
+		{
+			name:    "unknown field",
+			params:  &DidSaveTextDocumentParams{},
+			data:    `{"textDocument":{"uri":"file:///a.txt"},"txt":"a"}`,
+			wantErr: "params: unknown field txt",
+		},
+		{
+			name:   "embedded fields are promoted",
+			params: &HoverParams{},
+			data:   `{"textDocument":{"uri":"file:///a.txt"},"position":{"line":1,"character":2},"workDoneToken":1}`,
+		},
+		{
+			name:    "embedded required field",
+			params:  &HoverParams{},
+			data:    `{"textDocument":{"uri":"file:///a.txt"},"position":{"line":1}}`,
+			wantErr: "params.position: missing required field character",
+		},
+		{
+			name:   "required pointer accepts null",
+			params: &InitializeParams{},
+			data:   `{"processId":null,"rootUri":null,"capabilities":{}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateParams(json.RawMessage(tt.data), tt.params)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ValidateParams() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidParams) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ValidateParams() error = %v, want %s", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSetRequiredFields(t *testing.T) {
+	type optionalTags struct {
+		Required string `json:"required"`
+		Optional string `json:"optional"`
+	}
+	SetRequiredFields(optionalTags{}, "required")
+
+	if err := ValidateParams(json.RawMessage(`{"required":"a"}`), &optionalTags{}); err != nil {
+		t.Errorf("ValidateParams() error = %v", err)
+	}
+	err := ValidateParams(json.RawMessage(`{"optional":"a"}`), &optionalTags{})
+	if !errors.Is(err, ErrInvalidParams) || !strings.Contains(err.Error(), "missing required field required") {
+		t.Errorf("ValidateParams() error = %v, want the required field missing", err)
+	}
+}
+
+func TestHandler_strictPerMethod(t *testing.T) {
+	var called bool
+	h := Handler{
+		TextDocumentDidSave: func(ctx *glsp.Context, params *DidSaveTextDocumentParams) error {
+			called = true
+			return nil
+		},
+	}
+	h.SetInitialized(true)
+
+	ctx := &glsp.Context{
+		Method:  MethodTextDocumentDidSave,
+		Params:  json.RawMessage(`{"textDocument":{}}`),
+		Context: context.Background(),
+	}
+
+	_, validMethod, validParams, err := h.Handle(ctx)
+	if !validMethod || !validParams || err != nil || !called {
+		t.Fatalf("Handle() lenient = %v %v %v", validMethod, validParams, err)
+	}
+
+	called = false
+	h.SetStrict(MethodTextDocumentDidSave, true)
+	_, validMethod, validParams, err = h.Handle(ctx)
+	if !validMethod || validParams || !errors.Is(err, ErrInvalidParams) || called {
+		t.Fatalf("Handle() strict = %v %v %v", validMethod, validParams, err)
+	}
+}
@@ -2,6 +2,7 @@ package protocol
 
 import (
 	"github.com/kjbreil/glsp"
+	"github.com/kjbreil/glsp/pkg/uri"
 	protocol316 "github.com/kjbreil/glsp/protocol_3_16"
 )
 
@@ -26,6 +27,11 @@ type DiagnosticClientCapabilities struct {
 	RelatedDocumentSupport bool `json:"relatedDocumentSupport"`
 }
 
+func init() {
+	// the spec makes both capabilities optional, strict validation must not require them
+	protocol316.SetRequiredFields(DiagnosticClientCapabilities{})
+}
+
 /**
  * Diagnostic options.
  *
@@ -186,7 +192,7 @@ type RelatedFullDocumentDiagnosticReport struct {
 	 *
 	 * @since 3.17.0
 	 */
-	RelatedDocuments map[protocol316.DocumentUri]interface{} `json:"relatedDocuments,omitempty"`
+	RelatedDocuments map[uri.DocumentURI]interface{} `json:"relatedDocuments,omitempty"`
 }
 
 /**
@@ -205,7 +211,7 @@ type RelatedUnchangedDocumentDiagnosticReport struct {
 	 *
 	 * @since 3.17.0
 	 */
-	RelatedDocuments map[protocol316.DocumentUri]interface{} `json:"relatedDocuments,omitempty"`
+	RelatedDocuments map[uri.DocumentURI]interface{} `json:"relatedDocuments,omitempty"`
 }
 
 /**
@@ -214,7 +220,7 @@ type RelatedUnchangedDocumentDiagnosticReport struct {
  * @since 3.17.0
  */
 type DocumentDiagnosticReportPartialResult struct {
-	RelatedDocuments map[protocol316.DocumentUri]interface{} `json:"relatedDocuments"`
+	RelatedDocuments map[uri.DocumentURI]interface{} `json:"relatedDocuments"`
 }
 
 /**
@@ -225,3 +231,102 @@ type DocumentDiagnosticReportPartialResult struct {
 type DiagnosticServerCancellationData struct {
 	RetriggerRequest bool `json:"retriggerRequest"`
 }
+
+const MethodWorkspaceDiagnostic = protocol316.Method("workspace/diagnostic")
+
+type WorkspaceDiagnosticFunc func(context *glsp.Context, params *WorkspaceDiagnosticParams) (*WorkspaceDiagnosticReport, error)
+
+/**
+ * Parameters of the workspace diagnostic request.
+ *
+ * @since 3.17.0
+ */
+type WorkspaceDiagnosticParams struct {
+	protocol316.WorkDoneProgressParams
+	protocol316.PartialResultParams
+
+	/**
+	 * The additional identifier provided during registration.
+	 */
+	Identifier *string `json:"identifier,omitempty"`
+
+	/**
+	 * The currently known diagnostic reports with their
+	 * previous result ids.
+	 */
+	PreviousResultIds []PreviousResultId `json:"previousResultIds"`
+}
+
+/**
+ * A previous result id in a workspace pull request.
+ *
+ * @since 3.17.0
+ */
+type PreviousResultId struct {
+	/**
+	 * The URI for which the client knows a
+	 * result id.
+	 */
+	URI uri.DocumentURI `json:"uri"`
+
+	/**
+	 * The value of the previous result id.
+	 */
+	Value string `json:"value"`
+}
+
+/**
+ * A workspace diagnostic report.
+ *
+ * @since 3.17.0
+ */
+type WorkspaceDiagnosticReport struct {
+	Items []WorkspaceDocumentDiagnosticReport `json:"items"`
+}
+
+/**
+ * A full document diagnostic report for a workspace diagnostic result.
+ *
+ * @since 3.17.0
+ */
+type WorkspaceFullDocumentDiagnosticReport struct {
+	FullDocumentDiagnosticReport
+
+	/**
+	 * The URI for which diagnostic information is reported.
+	 */
+	URI uri.DocumentURI `json:"uri"`
+
+	/**
+	 * The version number for which the diagnostics are reported.
+	 * If the document is not marked as open `null` can be provided.
+	 */
+	Version *protocol316.Integer `json:"version"`
+}
+
+/**
+ * An unchanged document diagnostic report for a workspace diagnostic result.
+ *
+ * @since 3.17.0
+ */
+type WorkspaceUnchangedDocumentDiagnosticReport struct {
+	UnchangedDocumentDiagnosticReport
+
+	/**
+	 * The URI for which diagnostic information is reported.
+	 */
+	URI uri.DocumentURI `json:"uri"`
+
+	/**
+	 * The version number for which the diagnostics are reported.
+	 * If the document is not marked as open `null` can be provided.
+	 */
+	Version *protocol316.Integer `json:"version"`
+}
+
+/**
+ * A workspace diagnostic document report.
+ *
+ * @since 3.17.0
+ */
+type WorkspaceDocumentDiagnosticReport any // WorkspaceFullDocumentDiagnosticReport | WorkspaceUnchangedDocumentDiagnosticReport
@@ -27,6 +27,49 @@ type ClientCapabilities struct {
 	protocol316.ClientCapabilities
 
 	TextDocument *TextDocumentClientCapabilities `json:"textDocument,omitempty"`
+
+	// InlayHintWorkspace holds workspace.inlayHint, the workspace capabilities of the embedded 3.16 struct are
+	// an unnamed type that cannot be extended, so it is decoded on its own.
+	InlayHintWorkspace *InlayHintWorkspaceClientCapabilities `json:"-"`
+}
+
+// ([json.Unmarshaler] interface)
+func (self *ClientCapabilities) UnmarshalJSON(data []byte) error {
+	type clientCapabilities ClientCapabilities
+	var value clientCapabilities
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	var workspace struct {
+		Workspace *struct {
+			InlayHint *InlayHintWorkspaceClientCapabilities `json:"inlayHint"`
+		} `json:"workspace"`
+	}
+	if err := json.Unmarshal(data, &workspace); err != nil {
+		return err
+	}
+
+	*self = ClientCapabilities(value)
+	if workspace.Workspace != nil {
+		self.InlayHintWorkspace = workspace.Workspace.InlayHint
+	}
+	return nil
+}
+
+// Protocol316 returns the capabilities in their 3.16 shape. The text document capabilities of the embedded
+// 3.16 struct are hidden by the 3.17 ones when decoding, so they are copied over.
+func (self *ClientCapabilities) Protocol316() protocol316.ClientCapabilities {
+	capabilities := self.ClientCapabilities
+	if self.TextDocument != nil {
+		capabilities.TextDocument = &self.TextDocument.TextDocumentClientCapabilities
+	}
+	return capabilities
+}
+
+// PullDiagnostics reports whether the client can pull diagnostics with textDocument/diagnostic.
+func (self *ClientCapabilities) PullDiagnostics() bool {
+	return self.TextDocument != nil && self.TextDocument.Diagnostic != nil
 }
 
 /**
@@ -41,6 +84,20 @@ type TextDocumentClientCapabilities struct {
 	 * @since 3.17.0
 	 */
 	Diagnostic *DiagnosticClientCapabilities `json:"diagnostic,omitempty"`
+
+	/**
+	 * Capabilities specific to the `textDocument/inlayHint` request.
+	 *
+	 * @since 3.17.0
+	 */
+	InlayHint *InlayHintClientCapabilities `json:"inlayHint,omitempty"`
+
+	/**
+	 * Capabilities specific to the various type hierarchy requests.
+	 *
+	 * @since 3.17.0
+	 */
+	TypeHierarchy *TypeHierarchyClientCapabilities `json:"typeHierarchy,omitempty"`
 }
 
 type ServerCapabilities struct {
@@ -52,6 +109,20 @@ type ServerCapabilities struct {
 	 * @since 3.17.0
 	 */
 	DiagnosticProvider any `json:"diagnosticProvider,omitempty"` // nil | DiagnosticOptions | DiagnosticRegistrationOptions
+
+	/**
+	 * The server provides inlay hints.
+	 *
+	 * @since 3.17.0
+	 */
+	InlayHintProvider any `json:"inlayHintProvider,omitempty"` // nil | bool | InlayHintOptions | InlayHintRegistrationOptions
+
+	/**
+	 * The server provides type hierarchy support.
+	 *
+	 * @since 3.17.0
+	 */
+	TypeHierarchyProvider any `json:"typeHierarchyProvider,omitempty"` // nil | bool | TypeHierarchyOptions | TypeHierarchyRegistrationOptions
 }
 
 func (self *ServerCapabilities) UnmarshalJSON(data []byte) error {
@@ -85,7 +156,9 @@ func (self *ServerCapabilities) UnmarshalJSON(data []byte) error {
 		WorkspaceSymbolProvider          json.RawMessage                              `json:"workspaceSymbolProvider,omitempty"`    // nil | bool | WorkspaceSymbolOptions
 		Workspace                        *protocol316.ServerCapabilitiesWorkspace     `json:"workspace,omitempty"`
 		Experimental                     *any                                         `json:"experimental,omitempty"`
-		DiagnosticProvider               json.RawMessage                              `json:"diagnosticProvider,omitempty"` // nil | DiagnosticOptions | DiagnosticRegistrationOptions
+		DiagnosticProvider               json.RawMessage                              `json:"diagnosticProvider,omitempty"`    // nil | DiagnosticOptions | DiagnosticRegistrationOptions
+		InlayHintProvider                json.RawMessage                              `json:"inlayHintProvider,omitempty"`     // nil | bool | InlayHintOptions | InlayHintRegistrationOptions
+		TypeHierarchyProvider            json.RawMessage                              `json:"typeHierarchyProvider,omitempty"` // nil | bool | TypeHierarchyOptions | TypeHierarchyRegistrationOptions
 	}
 
 	if err := json.Unmarshal(data, &value); err == nil {
@@ -450,6 +523,34 @@ func (self *ServerCapabilities) UnmarshalJSON(data []byte) error {
 			}
 		}
 
+		if value.InlayHintProvider != nil {
+			var value_ bool
+			if err = json.Unmarshal(value.InlayHintProvider, &value_); err == nil {
+				self.InlayHintProvider = value_
+			} else {
+				var value_ InlayHintRegistrationOptions
+				if err = json.Unmarshal(value.InlayHintProvider, &value_); err == nil {
+					self.InlayHintProvider = value_
+				} else {
+					return err
+				}
+			}
+		}
+
+		if value.TypeHierarchyProvider != nil {
+			var value_ bool
+			if err = json.Unmarshal(value.TypeHierarchyProvider, &value_); err == nil {
+				self.TypeHierarchyProvider = value_
+			} else {
+				var value_ TypeHierarchyRegistrationOptions
+				if err = json.Unmarshal(value.TypeHierarchyProvider, &value_); err == nil {
+					self.TypeHierarchyProvider = value_
+				} else {
+					return err
+				}
+			}
+		}
+
 		return nil
 	} else {
 		return err
@@ -0,0 +1,49 @@
+package protocol
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kjbreil/glsp"
+	protocol316 "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+func TestHandleCancel(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	var h Handler
+	h.SetInitialized(true)
+	h.TextDocumentDiagnostic = func(context *glsp.Context, params *DocumentDiagnosticParams) (any, error) {
+		<-release
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, validMethod, validParams, err := h.Handle(&glsp.Context{
+		Method:  MethodTextDocumentDiagnostic,
+		Params:  []byte(`{"textDocument":{"uri":"file:///a.txt"}}`),
+		Context: ctx,
+	})
+	if !validMethod || !validParams {
+		t.Fatalf("Handle() validMethod = %v, validParams = %v", validMethod, validParams)
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Handle() err = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestValidateParamsOptional(t *testing.T) {
+	// the diagnostic capabilities are optional in the spec though their tags have no omitempty
+	data := []byte(`{"processId":null,"rootUri":null,"capabilities":{"textDocument":{"diagnostic":{}}}}`)
+	if err := protocol316.ValidateParams(data, &InitializeParams{}); err != nil {
+		t.Errorf("ValidateParams() error = %v", err)
+	}
+
+	data = []byte(`{"processId":null,"rootUri":null,"capabilities":{"textDocument":{"diagnostic":{"x":true}}}}`)
+	if err := protocol316.ValidateParams(data, &InitializeParams{}); !errors.Is(err, protocol316.ErrInvalidParams) {
+		t.Errorf("ValidateParams() error = %v, want an unknown field", err)
+	}
+}
@@ -0,0 +1,285 @@
+package protocol
+
+import (
+	"encoding/json"
+
+	"github.com/kjbreil/glsp"
+	protocol316 "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_inlayHint
+
+/**
+ * Inlay hint client capabilities.
+ *
+ * @since 3.17.0
+ */
+type InlayHintClientCapabilities struct {
+	/**
+	 * Whether inlay hints support dynamic registration.
+	 */
+	DynamicRegistration *bool `json:"dynamicRegistration,omitempty"`
+
+	/**
+	 * Indicates which properties a client can resolve lazily on an inlay
+	 * hint.
+	 */
+	ResolveSupport *struct {
+		/**
+		 * The properties that a client can resolve lazily.
+		 */
+		Properties []string `json:"properties"`
+	} `json:"resolveSupport,omitempty"`
+}
+
+/**
+ * Inlay hint options used during static registration.
+ *
+ * @since 3.17.0
+ */
+type InlayHintOptions struct {
+	protocol316.WorkDoneProgressOptions
+
+	/**
+	 * The server provides support to resolve additional
+	 * information for an inlay hint item.
+	 */
+	ResolveProvider *bool `json:"resolveProvider,omitempty"`
+}
+
+/**
+ * Inlay hint options used during static or dynamic registration.
+ *
+ * @since 3.17.0
+ */
+type InlayHintRegistrationOptions struct {
+	InlayHintOptions
+	protocol316.TextDocumentRegistrationOptions
+	protocol316.StaticRegistrationOptions
+}
+
+const MethodTextDocumentInlayHint = protocol316.Method("textDocument/inlayHint")
+
+type TextDocumentInlayHintFunc func(context *glsp.Context, params *InlayHintParams) ([]InlayHint, error)
+
+/**
+ * A parameter literal used in inlay hint requests.
+ *
+ * @since 3.17.0
+ */
+type InlayHintParams struct {
+	protocol316.WorkDoneProgressParams
+
+	/**
+	 * The text document.
+	 */
+	TextDocument protocol316.TextDocumentIdentifier `json:"textDocument"`
+
+	/**
+	 * The visible document range for which inlay hints should be computed.
+	 */
+	Range protocol316.Range `json:"range"`
+}
+
+/**
+ * Inlay hint information.
+ *
+ * @since 3.17.0
+ */
+type InlayHint struct {
+	/**
+	 * The position of this hint.
+	 */
+	Position protocol316.Position `json:"position"`
+
+	/**
+	 * The label of this hint. A human readable string or an array of
+	 * InlayHintLabelPart label parts.
+	 *
+	 * *Note* that neither the string nor the label part can be empty.
+	 */
+	Label any `json:"label"` // string | []InlayHintLabelPart
+
+	/**
+	 * The kind of this hint. Can be omitted in which case the client
+	 * should fall back to a reasonable default.
+	 */
+	Kind *InlayHintKind `json:"kind,omitempty"`
+
+	/**
+	 * Optional text edits that are performed when accepting this inlay hint.
+	 */
+	TextEdits []protocol316.TextEdit `json:"textEdits,omitempty"`
+
+	/**
+	 * The tooltip text when you hover over this item.
+	 */
+	Tooltip any `json:"tooltip,omitempty"` // nil | string | MarkupContent
+
+	/**
+	 * Render padding before the hint.
+	 */
+	PaddingLeft *bool `json:"paddingLeft,omitempty"`
+
+	/**
+	 * Render padding after the hint.
+	 */
+	PaddingRight *bool `json:"paddingRight,omitempty"`
+
+	/**
+	 * A data entry field that is preserved on an inlay hint between
+	 * a `textDocument/inlayHint` and a `inlayHint/resolve` request.
+	 */
+	Data any `json:"data,omitempty"`
+}
+
+// ([json.Unmarshaler] interface)
+func (self *InlayHint) UnmarshalJSON(data []byte) error {
+	var value struct {
+		Position     protocol316.Position   `json:"position"`
+		Label        json.RawMessage        `json:"label"` // string | []InlayHintLabelPart
+		Kind         *InlayHintKind         `json:"kind"`
+		TextEdits    []protocol316.TextEdit `json:"textEdits"`
+		Tooltip      json.RawMessage        `json:"tooltip"` // nil | string | MarkupContent
+		PaddingLeft  *bool                  `json:"paddingLeft"`
+		PaddingRight *bool                  `json:"paddingRight"`
+		Data         any                    `json:"data"`
+	}
+
+	if err := json.Unmarshal(data, &value); err == nil {
+		self.Position = value.Position
+		self.Kind = value.Kind
+		self.TextEdits = value.TextEdits
+		self.PaddingLeft = value.PaddingLeft
+		self.PaddingRight = value.PaddingRight
+		self.Data = value.Data
+
+		var value_ string
+		if err = json.Unmarshal(value.Label, &value_); err == nil {
+			self.Label = value_
+		} else {
+			var value_ []InlayHintLabelPart
+			if err = json.Unmarshal(value.Label, &value_); err == nil {
+				self.Label = value_
+			} else {
+				return err
+			}
+		}
+
+		if self.Tooltip, err = unmarshalTooltip(value.Tooltip); err != nil {
+			return err
+		}
+
+		return nil
+	} else {
+		return err
+	}
+}
+
+/**
+ * An inlay hint label part allows for interactive and composite labels
+ * of inlay hints.
+ *
+ * @since 3.17.0
+ */
+type InlayHintLabelPart struct {
+	/**
+	 * The value of this label part.
+	 */
+	Value string `json:"value"`
+
+	/**
+	 * The tooltip text when you hover over this label part. Depending on
+	 * the client capability `inlayHint.resolveSupport` clients might resolve
+	 * this property late using the resolve request.
+	 */
+	Tooltip any `json:"tooltip,omitempty"` // nil | string | MarkupContent
+
+	/**
+	 * An optional source code location that represents this
+	 * label part.
+	 */
+	Location *protocol316.Location `json:"location,omitempty"`
+
+	/**
+	 * An optional command for this label part.
+	 */
+	Command *protocol316.Command `json:"command,omitempty"`
+}
+
+// ([json.Unmarshaler] interface)
+func (self *InlayHintLabelPart) UnmarshalJSON(data []byte) error {
+	var value struct {
+		Value    string                `json:"value"`
+		Tooltip  json.RawMessage       `json:"tooltip"` // nil | string | MarkupContent
+		Location *protocol316.Location `json:"location"`
+		Command  *protocol316.Command  `json:"command"`
+	}
+
+	if err := json.Unmarshal(data, &value); err == nil {
+		self.Value = value.Value
+		self.Location = value.Location
+		self.Command = value.Command
+		self.Tooltip, err = unmarshalTooltip(value.Tooltip)
+		return err
+	} else {
+		return err
+	}
+}
+
+func unmarshalTooltip(data json.RawMessage) (any, error) {
+	if data == nil {
+		return nil, nil
+	}
+	var value_ string
+	if err := json.Unmarshal(data, &value_); err == nil {
+		return value_, nil
+	}
+	var markup protocol316.MarkupContent
+	if err := json.Unmarshal(data, &markup); err != nil {
+		return nil, err
+	}
+	return markup, nil
+}
+
+/**
+ * Inlay hint kinds.
+ *
+ * @since 3.17.0
+ */
+type InlayHintKind protocol316.UInteger
+
+const (
+	/**
+	 * An inlay hint that for a type annotation.
+	 */
+	InlayHintKindType = InlayHintKind(1)
+
+	/**
+	 * An inlay hint that is for a parameter.
+	 */
+	InlayHintKindParameter = InlayHintKind(2)
+)
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#inlayHint_resolve
+
+const MethodInlayHintResolve = protocol316.Method("inlayHint/resolve")
+
+type InlayHintResolveFunc func(context *glsp.Context, params *InlayHint) (*InlayHint, error)
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#workspace_inlayHint_refresh
+
+/**
+ * Client workspace capabilities specific to inlay hints.
+ *
+ * @since 3.17.0
+ */
+type InlayHintWorkspaceClientCapabilities struct {
+	/**
+	 * Whether the client implementation supports a refresh request sent from
+	 * the server to the client.
+	 */
+	RefreshSupport *bool `json:"refreshSupport,omitempty"`
+}
+
+const ServerWorkspaceInlayHintRefresh = protocol316.Method("workspace/inlayHint/refresh")
@@ -0,0 +1,143 @@
+package protocol
+
+import (
+	"github.com/kjbreil/glsp"
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol316 "github.com/kjbreil/glsp/protocol_3_16"
+)
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#textDocument_prepareTypeHierarchy
+
+/**
+ * @since 3.17.0
+ */
+type TypeHierarchyClientCapabilities struct {
+	/**
+	 * Whether implementation supports dynamic registration. If this is set to
+	 * `true` the client supports the new `(TextDocumentRegistrationOptions &
+	 * StaticRegistrationOptions)` return value for the corresponding server
+	 * capability as well.
+	 */
+	DynamicRegistration *bool `json:"dynamicRegistration,omitempty"`
+}
+
+/**
+ * Type hierarchy options used during static registration.
+ *
+ * @since 3.17.0
+ */
+type TypeHierarchyOptions struct {
+	protocol316.WorkDoneProgressOptions
+}
+
+/**
+ * Type hierarchy options used during static or dynamic registration.
+ *
+ * @since 3.17.0
+ */
+type TypeHierarchyRegistrationOptions struct {
+	protocol316.TextDocumentRegistrationOptions
+	TypeHierarchyOptions
+	protocol316.StaticRegistrationOptions
+}
+
+const MethodTextDocumentPrepareTypeHierarchy = protocol316.Method("textDocument/prepareTypeHierarchy")
+
+type TextDocumentPrepareTypeHierarchyFunc func(context *glsp.Context, params *TypeHierarchyPrepareParams) ([]TypeHierarchyItem, error)
+
+/**
+ * The parameter of a `textDocument/prepareTypeHierarchy` request.
+ *
+ * @since 3.17.0
+ */
+type TypeHierarchyPrepareParams struct {
+	protocol316.TextDocumentPositionParams
+	protocol316.WorkDoneProgressParams
+}
+
+/**
+ * @since 3.17.0
+ */
+type TypeHierarchyItem struct {
+	/**
+	 * The name of this item.
+	 */
+	Name string `json:"name"`
+
+	/**
+	 * The kind of this item.
+	 */
+	Kind protocol316.SymbolKind `json:"kind"`
+
+	/**
+	 * Tags for this item.
+	 */
+	Tags []protocol316.SymbolTag `json:"tags,omitempty"`
+
+	/**
+	 * More detail for this item, e.g. the signature of a function.
+	 */
+	Detail *string `json:"detail,omitempty"`
+
+	/**
+	 * The resource identifier of this item.
+	 */
+	URI uri.DocumentURI `json:"uri"`
+
+	/**
+	 * The range enclosing this symbol not including leading/trailing whitespace
+	 * but everything else, e.g. comments and code.
+	 */
+	Range protocol316.Range `json:"range"`
+
+	/**
+	 * The range that should be selected and revealed when this symbol is being
+	 * picked, e.g. the name of a function. Must be contained by the
+	 * [`range`](#TypeHierarchyItem.range).
+	 */
+	SelectionRange protocol316.Range `json:"selectionRange"`
+
+	/**
+	 * A data entry field that is preserved between a type hierarchy prepare and
+	 * supertypes or subtypes requests. It could also be used to identify the
+	 * type hierarchy in the server, helping improve the performance on
+	 * resolving supertypes and subtypes.
+	 */
+	Data any `json:"data,omitempty"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#typeHierarchy_supertypes
+
+const MethodTypeHierarchySupertypes = protocol316.Method("typeHierarchy/supertypes")
+
+type TypeHierarchySupertypesFunc func(context *glsp.Context, params *TypeHierarchySupertypesParams) ([]TypeHierarchyItem, error)
+
+/**
+ * The parameter of a `typeHierarchy/supertypes` request.
+ *
+ * @since 3.17.0
+ */
+type TypeHierarchySupertypesParams struct {
+	protocol316.WorkDoneProgressParams
+	protocol316.PartialResultParams
+
+	Item TypeHierarchyItem `json:"item"`
+}
+
+// https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/#typeHierarchy_subtypes
+
+const MethodTypeHierarchySubtypes = protocol316.Method("typeHierarchy/subtypes")
+
+type TypeHierarchySubtypesFunc func(context *glsp.Context, params *TypeHierarchySubtypesParams) ([]TypeHierarchyItem, error)
+
+/**
+ * The parameter of a `typeHierarchy/subtypes` request.
+ *
+ * @since 3.17.0
+ */
+type TypeHierarchySubtypesParams struct {
+	protocol316.WorkDoneProgressParams
+	protocol316.PartialResultParams
+
+	Item TypeHierarchyItem `json:"item"`
+}
@@ -0,0 +1,110 @@
+package proxy
+
+import (
+	contextpkg "context"
+	"encoding/json"
+	"sync"
+
+	"github.com/kjbreil/glsp"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// forwarder handles the requests and notifications of a backend by forwarding them to the editor. Requests
+// get an id picked by the proxy on the editor connection, $/cancelRequest of the backend cancels them.
+type forwarder struct {
+	proxy   *Proxy
+	backend int
+
+	requests map[jsonrpc2.ID]contextpkg.CancelFunc
+	mu       sync.Mutex
+}
+
+// ([glsp.Handler] interface)
+func (f *forwarder) Handle(context *glsp.Context) (r any, validMethod bool, validParams bool, err error) {
+	validMethod = true
+	validParams = true
+
+	switch context.Method {
+	case protocol.ServerTextDocumentPublishDiagnostics:
+		var params protocol.PublishDiagnosticsParams
+		if err = json.Unmarshal(context.Params, &params); err != nil {
+			validParams = false
+			return
+		}
+		f.proxy.publishDiagnostics(f.backend, &params)
+
+	case protocol.MethodCancelRequest:
+		var params protocol.CancelParams
+		if err = json.Unmarshal(context.Params, &params); err != nil {
+			validParams = false
+			return
+		}
+		f.mu.Lock()
+		cancel, ok := f.requests[requestID(params.ID)]
+		f.mu.Unlock()
+		if ok {
+			cancel()
+		}
+
+	case protocol.ServerWindowShowMessage, protocol.ServerWindowLogMessage, protocol.ServerTelemetryEvent,
+		protocol.MethodProgress, protocol.MethodLogTrace:
+		f.proxy.notifyEditor(context.Method, context.Params)
+
+	default:
+		ctx, cancel := contextpkg.WithCancel(context.Context)
+		defer cancel()
+		f.track(context.ID, cancel)
+		defer f.untrack(context.ID)
+
+		var result json.RawMessage
+		if err = f.proxy.callEditor(ctx, context.Method, context.Params, &result); err == nil {
+			r = result
+		}
+	}
+
+	return
+}
+
+func (f *forwarder) track(id jsonrpc2.ID, cancel contextpkg.CancelFunc) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.requests == nil {
+		f.requests = make(map[jsonrpc2.ID]contextpkg.CancelFunc)
+	}
+	f.requests[id] = cancel
+}
+
+func (f *forwarder) untrack(id jsonrpc2.ID) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.requests, id)
+}
+
+// publishDiagnostics stores the diagnostics of the backend for the document and publishes the diagnostics of
+// every backend to the editor.
+func (p *Proxy) publishDiagnostics(backend int, params *protocol.PublishDiagnosticsParams) {
+	p.mu.Lock()
+	byBackend, ok := p.diagnostics[params.URI]
+	if !ok {
+		byBackend = make(map[int][]protocol.Diagnostic)
+		p.diagnostics[params.URI] = byBackend
+	}
+	byBackend[backend] = params.Diagnostics
+
+	diagnostics := []protocol.Diagnostic{}
+	for i := range p.Backends {
+		diagnostics = append(diagnostics, byBackend[i]...)
+	}
+	// backends clear the diagnostics of closed documents, the document is forgotten when none are left
+	if len(diagnostics) == 0 {
+		delete(p.diagnostics, params.URI)
+	}
+	p.mu.Unlock()
+
+	p.notifyEditor(protocol.ServerTextDocumentPublishDiagnostics, &protocol.PublishDiagnosticsParams{
+		URI:         params.URI,
+		Version:     params.Version,
+		Diagnostics: diagnostics,
+	})
+}
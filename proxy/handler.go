@@ -0,0 +1,385 @@
+package proxy
+
+import (
+	contextpkg "context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/kjbreil/glsp"
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+	protocol317 "github.com/kjbreil/glsp/protocol_3_17"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// broadcastNotifications are sent to every backend.
+var broadcastNotifications = map[string]bool{
+	protocol.MethodSetTrace:                           true,
+	protocol.MethodWindowWorkDoneProgressCancel:       true,
+	protocol.MethodWorkspaceDidChangeConfiguration:    true,
+	protocol.MethodWorkspaceDidChangeWatchedFiles:     true,
+	protocol.MethodWorkspaceDidChangeWorkspaceFolders: true,
+	protocol.MethodWorkspaceDidCreateFiles:            true,
+	protocol.MethodWorkspaceDidRenameFiles:            true,
+	protocol.MethodWorkspaceDidDeleteFiles:            true,
+}
+
+// documentNotifications are sent to the backends of the document without tracking them.
+var documentNotifications = map[string]bool{
+	protocol.MethodTextDocumentDidSave:  true,
+	protocol.MethodTextDocumentWillSave: true,
+}
+
+// workspaceRequests are sent to every backend, the first result that is not null is returned.
+var workspaceRequests = map[string]bool{
+	protocol.MethodWorkspaceWillCreateFiles: true,
+	protocol.MethodWorkspaceWillRenameFiles: true,
+	protocol.MethodWorkspaceWillDeleteFiles: true,
+}
+
+// listRequests answer with lists, the lists of every backend of the document are merged.
+var listRequests = map[string]func(results []json.RawMessage) (any, error){
+	protocol.MethodTextDocumentCompletion:        mergeCompletions,
+	protocol.MethodTextDocumentCodeAction:        mergeLists,
+	protocol.MethodTextDocumentCodeLens:          mergeLists,
+	protocol.MethodTextDocumentDocumentLink:      mergeLists,
+	protocol.MethodTextDocumentReferences:        mergeLists,
+	protocol.MethodTextDocumentFoldingRange:      mergeLists,
+	protocol.MethodTextDocumentColor:             mergeLists,
+	protocol.MethodWorkspaceSymbol:               mergeLists,
+	protocol317.MethodTextDocumentDiagnostic:     mergeDiagnosticReports,
+	protocol.MethodTextDocumentDocumentHighlight: mergeLists,
+}
+
+// taggedRequests answer with items the editor can resolve later, the items are tagged with their backend.
+var taggedRequests = map[string]bool{
+	protocol.MethodTextDocumentCompletion:   true,
+	protocol.MethodTextDocumentCodeAction:   true,
+	protocol.MethodTextDocumentCodeLens:     true,
+	protocol.MethodTextDocumentDocumentLink: true,
+}
+
+// resolveRequests resolve an item tagged with its backend.
+var resolveRequests = map[string]bool{
+	protocol.MethodCompletionItemResolve: true,
+	protocol.MethodCodeActionResolve:     true,
+	protocol.MethodCodeLensResolve:       true,
+	protocol.MethodDocumentLinkResolve:   true,
+}
+
+// ([glsp.Handler] interface)
+func (p *Proxy) Handle(context *glsp.Context) (r any, validMethod bool, validParams bool, err error) {
+	validMethod = true
+	validParams = true
+
+	switch method := context.Method; {
+	case method == protocol317.MethodInitialize:
+		var params protocol317.InitializeParams
+		if err = json.Unmarshal(context.Params, &params); err != nil {
+			validParams = false
+			return
+		}
+		r, err = p.request(context, func(ctx contextpkg.Context) (any, error) {
+			return p.initialize(ctx, &params)
+		})
+
+	case method == protocol.MethodInitialized:
+		// the backends were sent initialized when they answered initialize, requests they made since then
+		// are held back until the editor is ready for them
+		p.readyOnce.Do(func() { close(p.ready) })
+
+	case method == protocol.MethodShutdown:
+		r, err = p.request(context, func(ctx contextpkg.Context) (any, error) {
+			var errs []error
+			for _, result := range p.fanOut(ctx, p.allBackends(), method, nil) {
+				errs = append(errs, result.err)
+			}
+			return nil, errors.Join(errs...)
+		})
+
+	case method == protocol.MethodExit:
+		for _, backend := range p.Backends {
+			if err := backend.client.Exit(context.Context); err != nil {
+				p.Log.Error("could not exit backend", "backend", backend.Name, "err", err)
+			}
+		}
+		err = p.Close()
+
+	case method == protocol.MethodCancelRequest:
+		var params protocol.CancelParams
+		if err = json.Unmarshal(context.Params, &params); err != nil {
+			validParams = false
+			return
+		}
+		p.cancelRequest(requestID(params.ID))
+
+	case method == protocol.MethodTextDocumentDidOpen:
+		var params protocol.DidOpenTextDocumentParams
+		if err = json.Unmarshal(context.Params, &params); err != nil {
+			validParams = false
+			return
+		}
+		p.mu.Lock()
+		p.languages[params.TextDocument.URI] = params.TextDocument.LanguageID
+		p.mu.Unlock()
+		err = p.eachBackend(params.TextDocument.URI, func(backend *Backend) error {
+			return backend.client.DidOpen(context.Context, &params)
+		})
+
+	case method == protocol.MethodTextDocumentDidChange:
+		var params protocol.DidChangeTextDocumentParams
+		if err = json.Unmarshal(context.Params, &params); err != nil {
+			validParams = false
+			return
+		}
+		err = p.eachBackend(params.TextDocument.URI, func(backend *Backend) error {
+			return backend.client.DidChange(context.Context, &params)
+		})
+
+	case method == protocol.MethodTextDocumentDidClose:
+		var params protocol.DidCloseTextDocumentParams
+		if err = json.Unmarshal(context.Params, &params); err != nil {
+			validParams = false
+			return
+		}
+		err = p.eachBackend(params.TextDocument.URI, func(backend *Backend) error {
+			return backend.client.DidClose(context.Context, &params)
+		})
+		p.mu.Lock()
+		delete(p.languages, params.TextDocument.URI)
+		delete(p.diagnostics, params.TextDocument.URI)
+		p.mu.Unlock()
+
+	case broadcastNotifications[method]:
+		for _, backend := range p.Backends {
+			err = errors.Join(err, backend.client.Notify(context.Context, method, context.Params))
+		}
+
+	case documentNotifications[method]:
+		documentURI, ok := paramsURI(context.Params)
+		if !ok {
+			validParams = false
+			return
+		}
+		err = p.eachBackend(documentURI, func(backend *Backend) error {
+			return backend.client.Notify(context.Context, method, context.Params)
+		})
+
+	case method == protocol.MethodWorkspaceExecuteCommand:
+		var params protocol.ExecuteCommandParams
+		if err = json.Unmarshal(context.Params, &params); err != nil {
+			validParams = false
+			return
+		}
+		backend, ok := p.commandBackend(params.Command)
+		if !ok {
+			err = fmt.Errorf("%w: %s", ErrUnknownCommand, params.Command)
+			return
+		}
+		r, err = p.request(context, func(ctx contextpkg.Context) (any, error) {
+			return p.first(p.fanOut(ctx, []int{backend}, method, context.Params))
+		})
+
+	case workspaceRequests[method]:
+		r, err = p.request(context, func(ctx contextpkg.Context) (any, error) {
+			return p.first(p.fanOut(ctx, p.allBackends(), method, context.Params))
+		})
+
+	case method == protocol.MethodWorkspaceSymbol:
+		r, err = p.request(context, func(ctx contextpkg.Context) (any, error) {
+			return p.merge(method, p.fanOut(ctx, p.allBackends(), method, context.Params))
+		})
+
+	case resolveRequests[method]:
+		backend, params, ok := untag(context.Params)
+		if !ok || backend >= len(p.Backends) {
+			validParams = false
+			err = fmt.Errorf("%w: item was not created by the proxy", ErrNoBackend)
+			return
+		}
+		r, err = p.request(context, func(ctx contextpkg.Context) (any, error) {
+			results := p.fanOut(ctx, []int{backend}, method, params)
+			if results[0].err != nil {
+				return nil, results[0].err
+			}
+			return tag(results[0].raw, backend), nil
+		})
+
+	default:
+		documentURI, ok := paramsURI(context.Params)
+		if !ok {
+			validMethod = false
+			return
+		}
+		r, err = p.request(context, func(ctx contextpkg.Context) (any, error) {
+			return p.documentRequest(ctx, method, documentURI, context.Params)
+		})
+	}
+
+	return
+}
+
+// request runs a request of the editor with a context that is cancelled by $/cancelRequest.
+func (p *Proxy) request(context *glsp.Context, fn func(ctx contextpkg.Context) (any, error)) (any, error) {
+	ctx, cancel := contextpkg.WithCancel(context.Context)
+	defer cancel()
+
+	p.trackRequest(context.ID, cancel)
+	defer p.untrackRequest(context.ID)
+
+	return fn(ctx)
+}
+
+func (p *Proxy) documentRequest(ctx contextpkg.Context, method string, documentURI uri.DocumentURI, params json.RawMessage) (any, error) {
+	backends := p.backends(documentURI)
+	if isSemanticTokens(method) {
+		// tokens are encoded with the legend of a single backend
+		backends = slicesKeep(backends, p.semanticTokens)
+	}
+	if len(backends) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoBackend, documentURI)
+	}
+	if method == protocol317.MethodTextDocumentDiagnostic {
+		params = withoutPreviousResult(params)
+	}
+
+	results := p.fanOut(ctx, backends, method, params)
+	if taggedRequests[method] {
+		for i := range results {
+			results[i].raw = tag(results[i].raw, results[i].backend)
+		}
+	}
+
+	if listRequests[method] != nil {
+		return p.merge(method, results)
+	}
+	return p.first(results)
+}
+
+func (p *Proxy) eachBackend(documentURI uri.DocumentURI, fn func(backend *Backend) error) error {
+	var errs []error
+	for _, i := range p.backends(documentURI) {
+		if err := fn(p.Backends[i]); err != nil {
+			errs = append(errs, fmt.Errorf("backend %s: %w", p.Backends[i].Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+type backendResult struct {
+	backend int
+	raw     json.RawMessage
+	err     error
+}
+
+// fanOut sends the request to the backends at the same time, the results are in the order of the backends.
+func (p *Proxy) fanOut(ctx contextpkg.Context, backends []int, method string, params any) []backendResult {
+	results := make([]backendResult, len(backends))
+	var wg sync.WaitGroup
+	for i, backend := range backends {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i].backend = backend
+			results[i].err = p.Backends[backend].client.Call(ctx, method, params, &results[i].raw)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// first returns the first result that is not null, errors are only returned when no backend answered.
+func (p *Proxy) first(results []backendResult) (any, error) {
+	var errs []error
+	for _, result := range results {
+		if result.err != nil {
+			errs = append(errs, p.backendError(result))
+			continue
+		}
+		if !isNull(result.raw) {
+			return result.raw, nil
+		}
+	}
+	if len(errs) == len(results) {
+		return nil, errors.Join(errs...)
+	}
+	return nil, nil
+}
+
+// merge merges the lists of the backends that answered, errors are only returned when no backend answered.
+func (p *Proxy) merge(method string, results []backendResult) (any, error) {
+	var raws []json.RawMessage
+	var errs []error
+	for _, result := range results {
+		if result.err != nil {
+			errs = append(errs, p.backendError(result))
+			continue
+		}
+		raws = append(raws, result.raw)
+	}
+	if len(raws) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	for _, err := range errs {
+		p.Log.Error("backend request failed", "method", method, "err", err)
+	}
+	return listRequests[method](raws)
+}
+
+func (p *Proxy) backendError(result backendResult) error {
+	return fmt.Errorf("backend %s: %w", p.Backends[result.backend].Name, result.err)
+}
+
+// paramsURI returns the uri of the document the params refer to.
+func paramsURI(params json.RawMessage) (uri.DocumentURI, bool) {
+	var value struct {
+		TextDocument *struct {
+			URI uri.DocumentURI `json:"uri"`
+		} `json:"textDocument"`
+		Item *struct {
+			URI uri.DocumentURI `json:"uri"`
+		} `json:"item"`
+	}
+	if err := json.Unmarshal(params, &value); err != nil {
+		return "", false
+	}
+	switch {
+	case value.TextDocument != nil:
+		return value.TextDocument.URI, true
+	case value.Item != nil:
+		return value.Item.URI, true
+	}
+	return "", false
+}
+
+// requestID converts the id of $/cancelRequest into the id jsonrpc2 gave the request.
+func requestID(id protocol.IntegerOrString) jsonrpc2.ID {
+	switch value := id.Value.(type) {
+	case protocol.Integer:
+		return jsonrpc2.ID{Num: uint64(value)}
+	case string:
+		return jsonrpc2.ID{Str: value, IsString: true}
+	}
+	return jsonrpc2.ID{}
+}
+
+func isSemanticTokens(method string) bool {
+	switch method {
+	case protocol.MethodTextDocumentSemanticTokensFull,
+		protocol.MethodTextDocumentSemanticTokensFullDelta,
+		protocol.MethodTextDocumentSemanticTokensRange:
+		return true
+	}
+	return false
+}
+
+func slicesKeep(backends []int, keep int) []int {
+	for _, backend := range backends {
+		if backend == keep {
+			return []int{keep}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,316 @@
+package proxy
+
+import (
+	"bytes"
+	contextpkg "context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+	protocol317 "github.com/kjbreil/glsp/protocol_3_17"
+)
+
+// tagKey is the key of the data field the proxy wraps around the data of items it tags with their backend.
+const tagKey = "glspProxyBackend"
+
+type initializeResult struct {
+	Capabilities map[string]any                       `json:"capabilities"`
+	ServerInfo   *protocol.InitializeResultServerInfo `json:"serverInfo,omitempty"`
+}
+
+// initialize initializes every backend and merges their capabilities.
+func (p *Proxy) initialize(ctx contextpkg.Context, params *protocol317.InitializeParams) (any, error) {
+	results := make([]*protocol317.InitializeResult, len(p.Backends))
+	errs := make([]error, len(p.Backends))
+	var wg sync.WaitGroup
+	for i, backend := range p.Backends {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = backend.client.Initialize(ctx, params)
+		}()
+	}
+	wg.Wait()
+
+	merged := make(map[string]any)
+	for i, result := range results {
+		backend := p.Backends[i]
+		if errs[i] != nil {
+			return nil, fmt.Errorf("backend %s: %w", backend.Name, errs[i])
+		}
+
+		capabilities, err := capabilitiesMap(&result.Capabilities)
+		if err != nil {
+			return nil, fmt.Errorf("backend %s: %w", backend.Name, err)
+		}
+		if result.Capabilities.ExecuteCommandProvider != nil {
+			backend.commands = result.Capabilities.ExecuteCommandProvider.Commands
+		}
+		if _, ok := capabilities["semanticTokensProvider"]; ok {
+			// the legends of two backends cannot be merged without rewriting every token
+			if p.semanticTokens < 0 {
+				p.semanticTokens = i
+			} else {
+				delete(capabilities, "semanticTokensProvider")
+			}
+		}
+		mergeValue(merged, capabilities)
+	}
+
+	// the backend clients keep a copy of each document and send it whole to backends that want full sync
+	textDocumentSync, _ := merged["textDocumentSync"].(map[string]any)
+	if textDocumentSync == nil {
+		textDocumentSync = make(map[string]any)
+		merged["textDocumentSync"] = textDocumentSync
+	}
+	textDocumentSync["openClose"] = true
+	textDocumentSync["change"] = protocol.TextDocumentSyncKindIncremental
+
+	return initializeResult{
+		Capabilities: merged,
+		ServerInfo:   &protocol.InitializeResultServerInfo{Name: p.Name, Version: versionPtr(p.Version)},
+	}, nil
+}
+
+func versionPtr(version string) *string {
+	if version == "" {
+		return nil
+	}
+	return &version
+}
+
+// capabilitiesMap converts the capabilities into plain JSON values, a text document sync kind is converted
+// into the equivalent options so it can be merged with the options of other backends.
+func capabilitiesMap(capabilities *protocol317.ServerCapabilities) (map[string]any, error) {
+	data, err := json.Marshal(capabilities)
+	if err != nil {
+		return nil, err
+	}
+	var value map[string]any
+	if err = json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	if kind, ok := value["textDocumentSync"].(float64); ok {
+		value["textDocumentSync"] = map[string]any{"openClose": true, "change": kind}
+	}
+	return value, nil
+}
+
+// mergeValue merges b into a. Objects are merged field by field, lists are joined without duplicates, an
+// enabled feature wins over a disabled one and options win over a plain true. For anything else a wins.
+func mergeValue(a any, b any) any {
+	switch value := a.(type) {
+	case nil:
+		return b
+	case bool:
+		if !value {
+			return b
+		}
+		if _, ok := b.(map[string]any); ok {
+			return b
+		}
+		return value
+	case map[string]any:
+		other, ok := b.(map[string]any)
+		if !ok {
+			return value
+		}
+		for key, v := range other {
+			value[key] = mergeValue(value[key], v)
+		}
+		return value
+	case []any:
+		other, ok := b.([]any)
+		if !ok {
+			return value
+		}
+		for _, v := range other {
+			if !containsValue(value, v) {
+				value = append(value, v)
+			}
+		}
+		return value
+	default:
+		return value
+	}
+}
+
+func containsValue(values []any, v any) bool {
+	for _, value := range values {
+		if reflect.DeepEqual(value, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// mergeCompletions merges completion lists and plain item arrays into one list, the list is incomplete when
+// any backend said so.
+func mergeCompletions(results []json.RawMessage) (any, error) {
+	list := protocol.CompletionList{Items: []protocol.CompletionItem{}}
+	for _, raw := range results {
+		if isNull(raw) {
+			continue
+		}
+		if isArray(raw) {
+			var items []protocol.CompletionItem
+			if err := json.Unmarshal(raw, &items); err != nil {
+				return nil, err
+			}
+			list.Items = append(list.Items, items...)
+			continue
+		}
+		var other protocol.CompletionList
+		if err := json.Unmarshal(raw, &other); err != nil {
+			return nil, err
+		}
+		list.IsIncomplete = list.IsIncomplete || other.IsIncomplete
+		list.Items = append(list.Items, other.Items...)
+	}
+	return list, nil
+}
+
+// mergeLists joins the lists, null is returned when every backend answered null.
+func mergeLists(results []json.RawMessage) (any, error) {
+	var merged []json.RawMessage
+	for _, raw := range results {
+		if isNull(raw) {
+			continue
+		}
+		var items []json.RawMessage
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, err
+		}
+		if merged == nil {
+			merged = []json.RawMessage{}
+		}
+		merged = append(merged, items...)
+	}
+	if merged == nil {
+		return nil, nil
+	}
+	return merged, nil
+}
+
+// mergeDiagnosticReports joins the diagnostics of full reports. The previous result id is not forwarded to
+// the backends so every backend answers with a full report.
+func mergeDiagnosticReports(results []json.RawMessage) (any, error) {
+	report := protocol317.FullDocumentDiagnosticReport{
+		Kind:  string(protocol317.DocumentDiagnosticReportKindFull),
+		Items: []protocol.Diagnostic{},
+	}
+	for _, raw := range results {
+		if isNull(raw) {
+			continue
+		}
+		var other protocol317.FullDocumentDiagnosticReport
+		if err := json.Unmarshal(raw, &other); err != nil {
+			return nil, err
+		}
+		report.Items = append(report.Items, other.Items...)
+	}
+	return report, nil
+}
+
+func withoutPreviousResult(params json.RawMessage) json.RawMessage {
+	var value map[string]json.RawMessage
+	if err := json.Unmarshal(params, &value); err != nil {
+		return params
+	}
+	if _, ok := value["previousResultId"]; !ok {
+		return params
+	}
+	delete(value, "previousResultId")
+	data, err := json.Marshal(value)
+	if err != nil {
+		return params
+	}
+	return data
+}
+
+// tag wraps the data of the items in the result with the index of the backend, so a later resolve request
+// can be sent to the backend that created the item. Plain commands are left alone.
+func tag(raw json.RawMessage, backend int) json.RawMessage {
+	if isNull(raw) {
+		return raw
+	}
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return raw
+	}
+
+	switch value := value.(type) {
+	case []any:
+		for _, item := range value {
+			tagItem(item, backend)
+		}
+	case map[string]any:
+		if items, ok := value["items"].([]any); ok {
+			for _, item := range items {
+				tagItem(item, backend)
+			}
+		} else {
+			tagItem(value, backend)
+		}
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return raw
+	}
+	return data
+}
+
+func tagItem(item any, backend int) {
+	object, ok := item.(map[string]any)
+	if !ok {
+		return
+	}
+	if _, isCommand := object["command"].(string); isCommand {
+		return
+	}
+	wrapped := map[string]any{tagKey: backend}
+	if data, ok := object["data"]; ok {
+		wrapped["data"] = data
+	}
+	object["data"] = wrapped
+}
+
+// untag restores the data of an item tagged by the proxy and returns the backend it came from.
+func untag(params json.RawMessage) (int, json.RawMessage, bool) {
+	var object map[string]any
+	if err := json.Unmarshal(params, &object); err != nil {
+		return 0, params, false
+	}
+	wrapped, ok := object["data"].(map[string]any)
+	if !ok {
+		return 0, params, false
+	}
+	backend, ok := wrapped[tagKey].(float64)
+	if !ok || backend < 0 {
+		return 0, params, false
+	}
+
+	if data, ok := wrapped["data"]; ok {
+		object["data"] = data
+	} else {
+		delete(object, "data")
+	}
+	data, err := json.Marshal(object)
+	if err != nil {
+		return 0, params, false
+	}
+	return int(backend), data, true
+}
+
+func isNull(raw json.RawMessage) bool {
+	raw = bytes.TrimSpace(raw)
+	return len(raw) == 0 || bytes.Equal(raw, []byte("null"))
+}
+
+func isArray(raw json.RawMessage) bool {
+	raw = bytes.TrimSpace(raw)
+	return len(raw) > 0 && raw[0] == '['
+}
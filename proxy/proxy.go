@@ -0,0 +1,276 @@
+package proxy
+
+import (
+	contextpkg "context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"github.com/kjbreil/glsp/client"
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+	glspserv "github.com/kjbreil/glsp/server"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+var (
+	ErrNoBackends      = errors.New("no backends configured")
+	ErrNoBackend       = errors.New("no backend for document")
+	ErrUnknownCommand  = errors.New("no backend provides command")
+	ErrEditorNotReady  = errors.New("editor not connected")
+	ErrBackendNotReady = errors.New("backend not connected")
+)
+
+//
+// Backend
+//
+
+// Backend is a language server behind the proxy. Documents are routed to it by their language id or by glob
+// patterns matched against their path, a backend without either receives every document.
+type Backend struct {
+	Name      string   `json:"name"`
+	Languages []string `json:"languages,omitempty"`
+	Patterns  []string `json:"patterns,omitempty"`
+
+	// Command and Args launch the backend as a process, Address dials it over TCP
+	Command string   `json:"command,omitempty"`
+	Args    []string `json:"args,omitempty"`
+	Address string   `json:"address,omitempty"`
+
+	// Connect replaces Command and Address, it connects the client to the backend
+	Connect func(ctx contextpkg.Context, c *client.Client) error `json:"-"`
+
+	client   *client.Client
+	commands []string
+}
+
+func (b *Backend) connect(ctx contextpkg.Context) error {
+	switch {
+	case b.Connect != nil:
+		return b.Connect(ctx, b.client)
+	case b.Command != "":
+		return b.client.Launch(ctx, b.Command, b.Args...)
+	case b.Address != "":
+		return b.client.DialTCP(ctx, b.Address)
+	default:
+		return fmt.Errorf("backend %s: no command, address or connect function", b.Name)
+	}
+}
+
+// Matches reports whether documents with the uri and language id are routed to the backend.
+func (b *Backend) Matches(documentURI uri.DocumentURI, languageID string) bool {
+	if len(b.Languages) == 0 && len(b.Patterns) == 0 {
+		return true
+	}
+	if languageID != "" && slices.Contains(b.Languages, languageID) {
+		return true
+	}
+
+	path := documentURI.Path()
+	for _, pattern := range b.Patterns {
+		if ok, _ := filepath.Match(pattern, path); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, filepath.Base(path)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+//
+// Proxy
+//
+
+// Proxy puts several language servers behind one editor connection. It implements glsp.Handler and is
+// served by a server.Server, requests from the editor are routed to the backends and their answers merged,
+// requests from the backends are forwarded to the editor.
+type Proxy struct {
+	Backends []*Backend
+	Name     string
+	Version  string
+	Log      *slog.Logger
+
+	editor   *jsonrpc2.Conn
+	ready    chan struct{}
+	nextID   atomic.Uint64
+	requests map[jsonrpc2.ID]contextpkg.CancelFunc
+	// languages holds the language id of each open document
+	languages map[uri.DocumentURI]string
+	// diagnostics holds the published diagnostics of each backend by document
+	diagnostics map[uri.DocumentURI]map[int][]protocol.Diagnostic
+	// semanticTokens is the index of the backend whose semantic tokens legend was sent to the editor
+	semanticTokens int
+
+	readyOnce sync.Once
+	mu        sync.Mutex
+}
+
+func New(backends ...*Backend) *Proxy {
+	return &Proxy{
+		Backends:       backends,
+		Name:           "glsp-proxy",
+		Log:            slog.Default(),
+		ready:          make(chan struct{}),
+		requests:       make(map[jsonrpc2.ID]contextpkg.CancelFunc),
+		languages:      make(map[uri.DocumentURI]string),
+		diagnostics:    make(map[uri.DocumentURI]map[int][]protocol.Diagnostic),
+		semanticTokens: -1,
+	}
+}
+
+// Start connects to every backend.
+func (p *Proxy) Start(ctx contextpkg.Context) error {
+	if len(p.Backends) == 0 {
+		return ErrNoBackends
+	}
+	for i, backend := range p.Backends {
+		backend.client = client.NewClient(&forwarder{proxy: p, backend: i})
+		backend.client.Log = p.Log.With("backend", backend.Name)
+		if err := backend.connect(ctx); err != nil {
+			p.Close()
+			return fmt.Errorf("backend %s: %w", backend.Name, err)
+		}
+	}
+	return nil
+}
+
+// Close disconnects every backend.
+func (p *Proxy) Close() error {
+	var errs []error
+	for _, backend := range p.Backends {
+		if backend.client == nil {
+			continue
+		}
+		if err := backend.client.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("backend %s: %w", backend.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Server returns a server for the editor side of the proxy. Requests are answered asynchronously so a backend
+// can call the editor while one of its requests is pending.
+func (p *Proxy) Server(debug bool) *glspserv.Server {
+	s := glspserv.NewServer(p, p.Name, debug, p.setEditor)
+	s.Log = p.Log
+	s.AsyncRequests = true
+	return s
+}
+
+func (p *Proxy) setEditor(conn *jsonrpc2.Conn) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.editor = conn
+}
+
+func (p *Proxy) editorConn() (*jsonrpc2.Conn, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.editor == nil {
+		return nil, ErrEditorNotReady
+	}
+	return p.editor, nil
+}
+
+// backends returns the indexes of the backends that the document is routed to.
+func (p *Proxy) backends(documentURI uri.DocumentURI) []int {
+	p.mu.Lock()
+	languageID := p.languages[documentURI]
+	p.mu.Unlock()
+
+	var indexes []int
+	for i, backend := range p.Backends {
+		if backend.Matches(documentURI, languageID) {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+func (p *Proxy) allBackends() []int {
+	indexes := make([]int, len(p.Backends))
+	for i := range p.Backends {
+		indexes[i] = i
+	}
+	return indexes
+}
+
+// commandBackend returns the backend that advertised the command.
+func (p *Proxy) commandBackend(command string) (int, bool) {
+	for i, backend := range p.Backends {
+		if slices.Contains(backend.commands, command) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (p *Proxy) trackRequest(id jsonrpc2.ID, cancel contextpkg.CancelFunc) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.requests[id] = cancel
+}
+
+func (p *Proxy) untrackRequest(id jsonrpc2.ID) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.requests, id)
+}
+
+// cancelRequest cancels the context of a pending editor request, the backend clients then cancel their own
+// requests with the ids they picked.
+func (p *Proxy) cancelRequest(id jsonrpc2.ID) {
+	p.mu.Lock()
+	cancel, ok := p.requests[id]
+	p.mu.Unlock()
+	if ok {
+		cancel()
+	}
+}
+
+// callEditor sends a request to the editor with an id picked by the proxy, when ctx is done before the
+// response arrives the request is cancelled.
+func (p *Proxy) callEditor(ctx contextpkg.Context, method string, params any, result any) error {
+	select {
+	case <-p.ready:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	editor, err := p.editorConn()
+	if err != nil {
+		return err
+	}
+
+	id := jsonrpc2.ID{Num: p.nextID.Add(1)}
+	waiter, err := editor.DispatchCall(ctx, method, params, jsonrpc2.PickID(id))
+	if err != nil {
+		return err
+	}
+	err = waiter.Wait(ctx, result)
+	if ctx.Err() != nil {
+		cancelErr := editor.Notify(contextpkg.Background(), protocol.MethodCancelRequest, &protocol.CancelParams{
+			ID: protocol.IntegerOrString{Value: protocol.Integer(id.Num)},
+		})
+		if cancelErr != nil {
+			p.Log.Error("could not cancel editor request", "method", method, "err", cancelErr)
+		}
+	}
+	return err
+}
+
+func (p *Proxy) notifyEditor(method string, params any) {
+	editor, err := p.editorConn()
+	if err != nil {
+		p.Log.Error("could not notify editor", "method", method, "err", err)
+		return
+	}
+	if err = editor.Notify(contextpkg.Background(), method, params); err != nil {
+		p.Log.Error("could not notify editor", "method", method, "err", err)
+	}
+}
@@ -0,0 +1,304 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/kjbreil/glsp"
+	"github.com/kjbreil/glsp/client"
+	"github.com/kjbreil/glsp/internal/helpers"
+	"github.com/kjbreil/glsp/pkg/uri"
+	protocol "github.com/kjbreil/glsp/protocol_3_16"
+	protocol317 "github.com/kjbreil/glsp/protocol_3_17"
+	glspserv "github.com/kjbreil/glsp/server"
+	"github.com/sourcegraph/jsonrpc2"
+)
+
+// inProcess connects the backend to a glsp server running the handler.
+func inProcess(handler *protocol.Handler) func(ctx context.Context, c *client.Client) error {
+	return func(ctx context.Context, c *client.Client) error {
+		serverSide, clientSide := net.Pipe()
+		s := glspserv.NewServer(handler, "backend", false, func(conn *jsonrpc2.Conn) {})
+		go s.ServeStream(serverSide, nil)
+		return c.ConnectStream(ctx, clientSide)
+	}
+}
+
+func newBackendHandler(name string, capabilities protocol.ServerCapabilities) *protocol.Handler {
+	return &protocol.Handler{
+		Initialize: func(context *glsp.Context, params *protocol.InitializeParams) (any, error) {
+			return protocol.InitializeResult{Capabilities: capabilities}, nil
+		},
+		Initialized: func(context *glsp.Context, params *protocol.InitializedParams) error {
+			return nil
+		},
+		Shutdown: func(context *glsp.Context) error {
+			return nil
+		},
+		TextDocumentDidOpen: func(context *glsp.Context, params *protocol.DidOpenTextDocumentParams) error {
+			context.Notify(protocol.ServerTextDocumentPublishDiagnostics, &protocol.PublishDiagnosticsParams{
+				URI:         params.TextDocument.URI,
+				Diagnostics: []protocol.Diagnostic{{Message: name}},
+			})
+			return nil
+		},
+		TextDocumentHover: func(context *glsp.Context, params *protocol.HoverParams) (*protocol.Hover, error) {
+			return &protocol.Hover{Contents: protocol.MarkupContent{Kind: protocol.MarkupKindPlainText, Value: name}}, nil
+		},
+		TextDocumentCompletion: func(context *glsp.Context, params *protocol.CompletionParams) (any, error) {
+			return []protocol.CompletionItem{{Label: name}}, nil
+		},
+		CompletionItemResolve: func(context *glsp.Context, params *protocol.CompletionItem) (*protocol.CompletionItem, error) {
+			params.Detail = helpers.Ptr("resolved by " + name)
+			return params, nil
+		},
+	}
+}
+
+// newTestProxy starts a proxy with a dsl and a sql backend, both receive .dsl files, and returns an editor
+// client connected to it.
+func newTestProxy(t *testing.T, editorHandler *client.Handler) *client.Client {
+	t.Helper()
+
+	dsl := &Backend{
+		Name:      "dsl",
+		Languages: []string{"dsl"},
+		Connect: inProcess(newBackendHandler("dsl", protocol.ServerCapabilities{
+			HoverProvider:      true,
+			CompletionProvider: &protocol.CompletionOptions{TriggerCharacters: []string{"."}},
+		})),
+	}
+	sql := &Backend{
+		Name:      "sql",
+		Languages: []string{"sql"},
+		Patterns:  []string{"*.dsl"},
+		Connect: inProcess(newBackendHandler("sql", protocol.ServerCapabilities{
+			HoverProvider:      true,
+			CompletionProvider: &protocol.CompletionOptions{TriggerCharacters: []string{":", "."}},
+			DefinitionProvider: true,
+		})),
+	}
+
+	p := New(dsl, sql)
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	editorSide, proxySide := net.Pipe()
+	go p.Server(false).ServeStream(proxySide, nil)
+
+	editor := client.NewClient(editorHandler)
+	if err := editor.ConnectStream(context.Background(), editorSide); err != nil {
+		t.Fatalf("ConnectStream() error = %v", err)
+	}
+	t.Cleanup(func() {
+		_ = editor.Close()
+		_ = p.Close()
+	})
+	return editor
+}
+
+func TestProxy_Initialize(t *testing.T) {
+	editor := newTestProxy(t, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	result, err := editor.Initialize(ctx, &protocol317.InitializeParams{})
+	if err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	capabilities := result.Capabilities
+	if capabilities.CompletionProvider == nil || len(capabilities.CompletionProvider.TriggerCharacters) != 2 {
+		t.Errorf("Initialize() completion = %v", capabilities.CompletionProvider)
+	}
+	if capabilities.DefinitionProvider != true || capabilities.HoverProvider != true {
+		t.Errorf("Initialize() definition = %v, hover = %v", capabilities.DefinitionProvider, capabilities.HoverProvider)
+	}
+	sync, ok := capabilities.TextDocumentSync.(protocol.TextDocumentSyncOptions)
+	if !ok || sync.Change == nil || *sync.Change != protocol.TextDocumentSyncKindIncremental {
+		t.Errorf("Initialize() sync = %v", capabilities.TextDocumentSync)
+	}
+}
+
+func TestProxy_routing(t *testing.T) {
+	diagnostics := make(chan *protocol.PublishDiagnosticsParams, 10)
+	editor := newTestProxy(t, &client.Handler{
+		TextDocumentPublishDiagnostics: func(context *glsp.Context, params *protocol.PublishDiagnosticsParams) error {
+			diagnostics <- params
+			return nil
+		},
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := editor.Initialize(ctx, &protocol317.InitializeParams{}); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	queryURI := uri.DocumentURI("file:///query.sql")
+	if err := editor.OpenDocument(ctx, queryURI, "sql", "select"); err != nil {
+		t.Fatalf("OpenDocument() error = %v", err)
+	}
+	hover, err := editor.Hover(ctx, &protocol.HoverParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: queryURI},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Hover() error = %v", err)
+	}
+	if hover == nil || hover.Contents.(protocol.MarkupContent).Value != "sql" {
+		t.Errorf("Hover() = %v, want the sql backend", hover)
+	}
+
+	// a .dsl file matches the dsl backend by language and the sql backend by pattern
+	mainURI := uri.DocumentURI("file:///main.dsl")
+	if err = editor.OpenDocument(ctx, mainURI, "dsl", "main"); err != nil {
+		t.Fatalf("OpenDocument() error = %v", err)
+	}
+	list, err := editor.Completion(ctx, &protocol.CompletionParams{
+		TextDocumentPositionParams: protocol.TextDocumentPositionParams{
+			TextDocument: protocol.TextDocumentIdentifier{URI: mainURI},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Completion() error = %v", err)
+	}
+	if list == nil || len(list.Items) != 2 || list.Items[0].Label != "dsl" || list.Items[1].Label != "sql" {
+		t.Fatalf("Completion() = %v, want the items of both backends", list)
+	}
+
+	resolved, err := editor.CompletionResolve(ctx, &list.Items[1])
+	if err != nil {
+		t.Fatalf("CompletionResolve() error = %v", err)
+	}
+	if resolved.Detail == nil || *resolved.Detail != "resolved by sql" {
+		t.Errorf("CompletionResolve() detail = %v, want the sql backend", resolved.Detail)
+	}
+
+	// diagnostics of both backends are merged for the dsl file
+	for {
+		select {
+		case params := <-diagnostics:
+			if params.URI == mainURI && len(params.Diagnostics) == 2 {
+				return
+			}
+		case <-ctx.Done():
+			t.Fatal("diagnostics of both backends were not published")
+		}
+	}
+}
+
+func TestProxy_backendRequest(t *testing.T) {
+	configured := make(chan []any, 1)
+	backendHandler := newBackendHandler("dsl", protocol.ServerCapabilities{})
+	backendHandler.TextDocumentDidOpen = func(context *glsp.Context, params *protocol.DidOpenTextDocumentParams) error {
+		// the backend handles messages in order, so it asks the editor from another goroutine
+		go func() {
+			var result []any
+			context.Call(protocol.ServerWorkspaceConfiguration, &protocol.ConfigurationParams{
+				Items: []protocol.ConfigurationItem{{Section: helpers.Ptr("dsl")}},
+			}, &result)
+			configured <- result
+		}()
+		return nil
+	}
+
+	p := New(&Backend{Name: "dsl", Connect: inProcess(backendHandler)})
+	if err := p.Start(context.Background()); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	defer p.Close()
+
+	editorSide, proxySide := net.Pipe()
+	go p.Server(false).ServeStream(proxySide, nil)
+	editor := client.NewClient(&client.Handler{
+		WorkspaceConfiguration: func(context *glsp.Context, params *protocol.ConfigurationParams) ([]any, error) {
+			return []any{"tabs"}, nil
+		},
+	})
+	if err := editor.ConnectStream(context.Background(), editorSide); err != nil {
+		t.Fatalf("ConnectStream() error = %v", err)
+	}
+	defer editor.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := editor.Initialize(ctx, &protocol317.InitializeParams{}); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	if err := editor.OpenDocument(ctx, "file:///main.dsl", "dsl", ""); err != nil {
+		t.Fatalf("OpenDocument() error = %v", err)
+	}
+
+	select {
+	case result := <-configured:
+		if len(result) != 1 || result[0] != "tabs" {
+			t.Errorf("workspace/configuration = %v", result)
+		}
+	case <-ctx.Done():
+		t.Fatal("workspace/configuration was not forwarded to the editor")
+	}
+}
+
+func TestBackend_Matches(t *testing.T) {
+	backend := Backend{Languages: []string{"sql"}, Patterns: []string{"*.dsl", "/etc/*.conf"}}
+	tests := []struct {
+		uri        uri.DocumentURI
+		languageID string
+		want       bool
+	}{
+		{"file:///a/query.txt", "sql", true},
+		{"file:///a/main.dsl", "", true},
+		{"file:///etc/app.conf", "", true},
+		{"file:///a/app.conf", "", false},
+		{"file:///a/main.go", "go", false},
+	}
+	for _, tt := range tests {
+		if got := backend.Matches(tt.uri, tt.languageID); got != tt.want {
+			t.Errorf("Matches(%s, %s) = %v, want %v", tt.uri, tt.languageID, got, tt.want)
+		}
+	}
+
+	if !(&Backend{}).Matches("file:///a/main.go", "go") {
+		t.Errorf("Matches() without languages or patterns = false, want true")
+	}
+}
+
+func TestProxy_diagnosticsForgotten(t *testing.T) {
+	// the backends take no documents, the test only publishes their diagnostics
+	p := New(&Backend{Name: "a", Languages: []string{"a"}}, &Backend{Name: "b", Languages: []string{"b"}})
+	p.Log = slog.New(slog.NewTextHandler(io.Discard, nil))
+	u := uri.DocumentURI("file:///main.dsl")
+	diagnostic := protocol.Diagnostic{Message: "a"}
+
+	p.publishDiagnostics(0, &protocol.PublishDiagnosticsParams{URI: u, Diagnostics: []protocol.Diagnostic{diagnostic}})
+	p.publishDiagnostics(1, &protocol.PublishDiagnosticsParams{URI: u, Diagnostics: []protocol.Diagnostic{diagnostic}})
+	p.publishDiagnostics(0, &protocol.PublishDiagnosticsParams{URI: u, Diagnostics: []protocol.Diagnostic{}})
+	if _, ok := p.diagnostics[u]; !ok {
+		t.Fatalf("diagnostics of %s forgotten while backend b still has some", u)
+	}
+	p.publishDiagnostics(1, &protocol.PublishDiagnosticsParams{URI: u, Diagnostics: []protocol.Diagnostic{}})
+	if _, ok := p.diagnostics[u]; ok {
+		t.Errorf("diagnostics of %s kept after every backend cleared them", u)
+	}
+
+	p.publishDiagnostics(0, &protocol.PublishDiagnosticsParams{URI: u, Diagnostics: []protocol.Diagnostic{diagnostic}})
+	_, _, validParams, err := p.Handle(&glsp.Context{
+		Method:  protocol.MethodTextDocumentDidClose,
+		Params:  json.RawMessage(`{"textDocument":{"uri":"file:///main.dsl"}}`),
+		Context: context.Background(),
+	})
+	if !validParams || err != nil {
+		t.Fatalf("Handle() didClose = %v, %v", validParams, err)
+	}
+	if _, ok := p.diagnostics[u]; ok {
+		t.Errorf("diagnostics of %s kept after didClose", u)
+	}
+}
@@ -11,7 +11,26 @@ import (
 // See: https://github.com/sourcegraph/go-langserver/blob/master/langserver/handler.go#L206
 
 func (s *Server) newHandler() jsonrpc2.Handler {
-	return jsonrpc2.HandlerWithError(s.handle)
+	handler := jsonrpc2.HandlerWithError(s.handle)
+	if !s.AsyncRequests {
+		return handler
+	}
+
+	async := jsonrpc2.AsyncHandler(handler)
+	return handlerFunc(func(context contextpkg.Context, connection *jsonrpc2.Conn, request *jsonrpc2.Request) {
+		if request.Notif {
+			handler.Handle(context, connection, request)
+		} else {
+			async.Handle(context, connection, request)
+		}
+	})
+}
+
+type handlerFunc func(context contextpkg.Context, connection *jsonrpc2.Conn, request *jsonrpc2.Request)
+
+// ([jsonrpc2.Handler] interface)
+func (f handlerFunc) Handle(context contextpkg.Context, connection *jsonrpc2.Conn, request *jsonrpc2.Request) {
+	f(context, connection, request)
 }
 
 func (s *Server) handle(context contextpkg.Context, connection *jsonrpc2.Conn, request *jsonrpc2.Request) (any, error) {
@@ -30,7 +30,11 @@ type Server struct {
 	StreamTimeout    time.Duration
 	WebSocketTimeout time.Duration
 	Conn             *jsonrpc2.Conn
-	onConnect        func(conn *jsonrpc2.Conn)
+	// AsyncRequests answers requests on their own goroutine so a handler can call the client and wait for the
+	// response, notifications are still handled in order.
+	AsyncRequests bool
+
+	onConnect func(conn *jsonrpc2.Conn)
 }
 
 func NewServer(handler glsp.Handler, logName string, debug bool, fn func(conn *jsonrpc2.Conn)) *Server {